@@ -0,0 +1,53 @@
+package ddbfns
+
+import (
+	"reflect"
+
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// SchemaProvider can be implemented by the struct to describe its DynamoDB attributes without struct tags.
+//
+// Fns will use the returned Schema instead of parsing the `dynamodbav` struct tags by reflection. The implementation is
+// usually generated by cmd/ddbfns-gen alongside reflection-free key extraction, marshalling, and version helpers:
+//
+//	//go:generate go run github.com/nguyengg/go-ddb-fns/cmd/ddbfns-gen -type Item
+type SchemaProvider interface {
+	DynamoDBSchema() *Schema
+}
+
+// Schema describes the DynamoDB attributes of a struct.
+type Schema struct {
+	// TableName is the value of the `tableName` tag of the hashkey field.
+	TableName *string
+	// Attributes are the struct fields that are DynamoDB attributes.
+	Attributes []SchemaAttribute
+}
+
+// SchemaAttribute describes a struct field that is a DynamoDB attribute.
+type SchemaAttribute struct {
+	// Field is the name of the Go struct field.
+	Field string
+	// Name is the name of the DynamoDB attribute.
+	Name string
+	// Options are the options that would have followed the name in the `dynamodbav` struct tag such as `hashkey`.
+	Options []string
+}
+
+var schemaProviderType = reflect.TypeFor[SchemaProvider]()
+
+// parseModel creates the Model from SchemaProvider if the struct implements it, or by parsing struct tags otherwise.
+func parseModel(t reflect.Type) (*internal.Model, error) {
+	t = internal.DereferencedType(t)
+	if t.Kind() != reflect.Struct || !reflect.PointerTo(t).Implements(schemaProviderType) {
+		return internal.ParseFromType(t)
+	}
+
+	schema := reflect.New(t).Interface().(SchemaProvider).DynamoDBSchema()
+	fields := make([]internal.Field, len(schema.Attributes))
+	for i, attr := range schema.Attributes {
+		fields[i] = internal.Field{Name: attr.Field, Attribute: attr.Name, Options: attr.Options}
+	}
+
+	return internal.NewModel(t, schema.TableName, fields)
+}
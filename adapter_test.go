@@ -0,0 +1,51 @@
+package ddbfns
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// schemaTest has no ddbfns struct tags; its attributes are described by DynamoDBSchema instead.
+type schemaTest struct {
+	Id      string
+	Version int64
+}
+
+func (s *schemaTest) DynamoDBSchema() *Schema {
+	tableName := "schema"
+	return &Schema{
+		TableName: &tableName,
+		Attributes: []SchemaAttribute{
+			{Field: "Id", Name: "Id", Options: []string{"hashkey"}},
+			{Field: "Version", Name: "Version", Options: []string{"version"}},
+		},
+	}
+}
+
+func TestFns_SchemaProvider(t *testing.T) {
+	got, err := Put(schemaTest{Id: "hello", Version: 1})
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, "schema", *got.TableName)
+	assert.Equal(t, "#0 = :0", *got.ConditionExpression)
+	assert.Equal(t, map[string]string{"#0": "Version"}, got.ExpressionAttributeNames)
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, got.Item["Version"])
+}
+
+type badSchemaTest struct {
+	Id string
+}
+
+func (s *badSchemaTest) DynamoDBSchema() *Schema {
+	return &Schema{Attributes: []SchemaAttribute{{Field: "ID", Name: "id", Options: []string{"hashkey"}}}}
+}
+
+func TestFns_SchemaProviderUnknownField(t *testing.T) {
+	_, err := Put(badSchemaTest{Id: "hello"})
+	assert.ErrorContains(t, err, `no field "ID"`)
+}
@@ -0,0 +1,152 @@
+package ddbfns
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// wideTest is a large item with nested maps to show the cost of encoding the whole struct.
+type wideTest struct {
+	PK           string                       `dynamodbav:"pk,hashkey" tableName:"wide"`
+	SK           string                       `dynamodbav:"sk,sortkey"`
+	Version      int64                        `dynamodbav:"version,version"`
+	ModifiedTime time.Time                    `dynamodbav:"modifiedTime,modifiedTime,unixtime"`
+	Tags         []string                     `dynamodbav:"tags,stringset"`
+	Attributes   map[string]string            `dynamodbav:"attributes"`
+	Nested       map[string]map[string]string `dynamodbav:"nested"`
+	Notes        []string                     `dynamodbav:"notes"`
+}
+
+func newWideTest() wideTest {
+	v := wideTest{
+		PK:         "USER#123",
+		SK:         "PROFILE",
+		Version:    3,
+		Attributes: make(map[string]string),
+		Nested:     make(map[string]map[string]string),
+	}
+	for i := 0; i < 50; i++ {
+		v.Tags = append(v.Tags, fmt.Sprintf("tag-%d", i))
+		v.Notes = append(v.Notes, fmt.Sprintf("note %d", i))
+		v.Attributes[fmt.Sprintf("attr-%d", i)] = fmt.Sprintf("value %d", i)
+
+		nested := make(map[string]string)
+		for j := 0; j < 10; j++ {
+			nested[fmt.Sprintf("key-%d", j)] = fmt.Sprintf("value %d", j)
+		}
+		v.Nested[fmt.Sprintf("nested-%d", i)] = nested
+	}
+
+	return v
+}
+
+// BenchmarkFns_EncodeKey compares encoding only the key fields against encoding the whole struct.
+func BenchmarkFns_EncodeKey(b *testing.B) {
+	f := &Fns{}
+	f.init.Do(f.initFn)
+	v := newWideTest()
+	m, err := f.loadOrParse(reflect.TypeOf(v))
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("key fields", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err = f.encodeKey(m, v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("whole struct", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			av, err := f.Encoder.Encode(v)
+			if err != nil {
+				b.Fatal(err)
+			}
+
+			item := av.(*types.AttributeValueMemberM).Value
+			_ = map[string]types.AttributeValue{m.HashKey.Name: item[m.HashKey.Name], m.SortKey.Name: item[m.SortKey.Name]}
+		}
+	})
+}
+
+func BenchmarkFns_Get(b *testing.B) {
+	f := &Fns{}
+	v := newWideTest()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Get(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFns_Update(b *testing.B) {
+	f := &Fns{}
+	v := newWideTest()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Update(v, func(opts *UpdateOpts) {
+			opts.Set("notes", []string{"hello"})
+		}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFns_Delete(b *testing.B) {
+	f := &Fns{}
+	v := newWideTest()
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Delete(v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFns_Put compares the pre-rendered optimistic locking condition against building the same condition with
+// expression.Builder which happens when the user adds their own conditions.
+func BenchmarkFns_Put(b *testing.B) {
+	type Test struct {
+		Id           string    `dynamodbav:"id,hashkey" tableName:"test"`
+		Version      int64     `dynamodbav:"version,version"`
+		CreatedTime  time.Time `dynamodbav:"createdTime,createdTime"`
+		ModifiedTime time.Time `dynamodbav:"modifiedTime,modifiedTime,unixtime"`
+		Name         string    `dynamodbav:"name"`
+	}
+
+	f := &Fns{}
+	v := Test{Id: "hello", Version: 3, Name: "world"}
+
+	b.Run("fast path", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := f.Put(v); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("with condition", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := f.Put(v, func(opts *PutOpts) {
+				opts.And(expression.Name("name").AttributeExists())
+			}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
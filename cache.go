@@ -0,0 +1,415 @@
+package ddbfns
+
+import (
+	"container/list"
+	"hash/fnv"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// Cache is a cache of DynamoDB items used by [Fns.DoGet] when [Fns.Cache] is given.
+//
+// Keys are created from the table name and the encoded primary key of the item. Implementations must be safe for
+// concurrent use, and must not modify the items given to or returned by them.
+type Cache interface {
+	// Get returns the entry of the key.
+	Get(key string) (CacheEntry, bool)
+	// Set stores the entry of the key.
+	//
+	// If the cache already has an entry with a greater Version, it must be kept instead so that a slow read cannot
+	// replace the item written by a more recent DoPut or DoUpdate.
+	Set(key string, entry CacheEntry)
+	// Delete removes the entry of the key.
+	Delete(key string)
+}
+
+// CacheEntry is an item stored in a Cache.
+type CacheEntry struct {
+	// Item is the DynamoDB item.
+	Item map[string]types.AttributeValue
+	// Version is the value of the item's version attribute, or 0 if the item has no version attribute.
+	Version int64
+}
+
+// LRUCacheOpts customises [NewLRUCache].
+type LRUCacheOpts struct {
+	// Size is the maximum number of entries.
+	//
+	// Defaults to 1000.
+	Size int
+	// TTL is how long an entry is valid after it was set.
+	//
+	// If zero, entries are only evicted when the cache is full.
+	TTL time.Duration
+	// Clock returns the current time.
+	//
+	// If nil, [time.Now] is used.
+	Clock func() time.Time
+}
+
+// LRUCache is an in-memory Cache that evicts the least recently used entry when full, and expires entries after a TTL.
+type LRUCache struct {
+	opts LRUCacheOpts
+
+	mu      sync.Mutex
+	ll      *list.List
+	entries map[string]*list.Element
+}
+
+type lruEntry struct {
+	key     string
+	entry   CacheEntry
+	expires time.Time
+}
+
+// NewLRUCache creates a new LRUCache.
+func NewLRUCache(optFns ...func(*LRUCacheOpts)) *LRUCache {
+	opts := LRUCacheOpts{Size: 1000}
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = time.Now
+	}
+
+	return &LRUCache{opts: opts, ll: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// Get implements [Cache.Get].
+func (c *LRUCache) Get(key string) (CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok {
+		return CacheEntry{}, false
+	}
+
+	le := e.Value.(*lruEntry)
+	if c.opts.TTL > 0 && !c.opts.Clock().Before(le.expires) {
+		c.ll.Remove(e)
+		delete(c.entries, key)
+		return CacheEntry{}, false
+	}
+
+	c.ll.MoveToFront(e)
+	return le.entry, true
+}
+
+// Set implements [Cache.Set].
+func (c *LRUCache) Set(key string, entry CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.opts.Clock()
+	if e, ok := c.entries[key]; ok {
+		le := e.Value.(*lruEntry)
+		expired := c.opts.TTL > 0 && !now.Before(le.expires)
+		if !expired && le.entry.Version > entry.Version {
+			return
+		}
+
+		le.entry, le.expires = entry, now.Add(c.opts.TTL)
+		c.ll.MoveToFront(e)
+		return
+	}
+
+	c.entries[key] = c.ll.PushFront(&lruEntry{key: key, entry: entry, expires: now.Add(c.opts.TTL)})
+	for c.opts.Size > 0 && c.ll.Len() > c.opts.Size {
+		e := c.ll.Back()
+		c.ll.Remove(e)
+		delete(c.entries, e.Value.(*lruEntry).key)
+	}
+}
+
+// Delete implements [Cache.Delete].
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.entries[key]; ok {
+		c.ll.Remove(e)
+		delete(c.entries, key)
+	}
+}
+
+// Len returns the number of entries including the expired ones that have not been evicted.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+// cacheKey creates the key of the item from its table name and encoded primary key.
+func cacheKey(tableName string, key map[string]types.AttributeValue) string {
+	return tableName + "\x00" + explainItem(key)
+}
+
+// cacheable returns true if the GetItem request can be served from or stored in the cache.
+//
+// Projected items are never cached since they are not complete.
+func cacheable(input *dynamodb.GetItemInput) bool {
+	return input.TableName != nil && input.ProjectionExpression == nil
+}
+
+// getCached returns a copy of the cached item.
+func (f *Fns) getCached(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, bool) {
+	if !cacheable(input) || aws.ToBool(input.ConsistentRead) {
+		return nil, false
+	}
+
+	entry, ok := f.Cache.Get(cacheKey(*input.TableName, input.Key))
+	if !ok {
+		return nil, false
+	}
+
+	return &dynamodb.GetItemOutput{Item: copyItem(entry.Item)}, true
+}
+
+// cacheGenerations serialises the updates to the cache entries of the same key, and counts the writes to them.
+//
+// A GetItem captures the generation of its key before the request is sent, and its item is only cached if no write has
+// happened since. Otherwise, a GetItem that was in flight while a DoDelete or DoUpdate invalidated the entry would put
+// the stale item back. Keys are hashed into a fixed number of stripes so that the counters take constant space; keys
+// sharing a stripe only cause a few extra cache misses.
+type cacheGenerations struct {
+	stripes [64]cacheStripe
+}
+
+type cacheStripe struct {
+	mu  sync.Mutex
+	gen uint64
+}
+
+func (g *cacheGenerations) stripe(key string) *cacheStripe {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return &g.stripes[h.Sum32()%uint32(len(g.stripes))]
+}
+
+// get returns the current generation of the key.
+func (g *cacheGenerations) get(key string) uint64 {
+	s := g.stripe(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.gen
+}
+
+// write increments the generation of the key before calling fn while holding the lock of the key.
+func (g *cacheGenerations) write(key string, fn func()) {
+	s := g.stripe(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.gen++
+	fn()
+}
+
+// read calls fn while holding the lock of the key only if the key is still at the given generation.
+func (g *cacheGenerations) read(key string, gen uint64, fn func()) {
+	s := g.stripe(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.gen == gen {
+		fn()
+	}
+}
+
+// cacheGeneration returns the generation that must be passed to setCached after the GetItem request completes.
+func (f *Fns) cacheGeneration(input *dynamodb.GetItemInput) uint64 {
+	if !cacheable(input) {
+		return 0
+	}
+
+	return f.cacheGens.get(cacheKey(*input.TableName, input.Key))
+}
+
+// setCached stores a copy of the item returned by GetItem.
+//
+// The item is discarded if any write to the same key happened after gen was returned by cacheGeneration.
+func (f *Fns) setCached(attrs *internal.Model, input *dynamodb.GetItemInput, item map[string]types.AttributeValue, gen uint64) {
+	if !cacheable(input) {
+		return
+	}
+
+	key := cacheKey(*input.TableName, input.Key)
+	f.cacheGens.read(key, gen, func() {
+		if len(item) == 0 {
+			f.Cache.Delete(key)
+			return
+		}
+
+		f.Cache.Set(key, CacheEntry{Item: copyItem(item), Version: itemVersion(attrs, item)})
+	})
+}
+
+// updateCache refreshes or invalidates the cache entries of the items written by the request.
+//
+// PutItem refreshes the entry with the new item, as does UpdateItem with ReturnValues ALL_NEW. Otherwise, the entries
+// are deleted, including when the request fails since the cached item may be stale. Either way, the generation of the
+// key is incremented so that the GetItem requests still in flight don't cache their now stale items.
+func (f *Fns) updateCache(req *Request, output interface{}, err error) {
+	switch input := req.Input.(type) {
+	case *dynamodb.PutItemInput:
+		attrs := f.modelForItem(aws.ToString(input.TableName), input.Item)
+		if attrs == nil {
+			return
+		}
+
+		key := cacheKey(aws.ToString(input.TableName), itemKey(attrs, input.Item))
+		if err != nil {
+			f.invalidateCache(key)
+			return
+		}
+
+		f.cacheGens.write(key, func() {
+			f.Cache.Set(key, CacheEntry{Item: copyItem(input.Item), Version: itemVersion(attrs, input.Item)})
+		})
+	case *dynamodb.UpdateItemInput:
+		key := cacheKey(aws.ToString(input.TableName), input.Key)
+		if o, ok := output.(*dynamodb.UpdateItemOutput); ok && o != nil && err == nil && input.ReturnValues == types.ReturnValueAllNew {
+			f.cacheGens.write(key, func() {
+				f.Cache.Set(key, CacheEntry{Item: copyItem(o.Attributes), Version: itemVersion(f.modelForItem(aws.ToString(input.TableName), o.Attributes), o.Attributes)})
+			})
+			return
+		}
+
+		f.invalidateCache(key)
+	case *dynamodb.DeleteItemInput:
+		f.invalidateCache(cacheKey(aws.ToString(input.TableName), input.Key))
+	case *dynamodb.TransactWriteItemsInput:
+		for _, item := range input.TransactItems {
+			switch {
+			case item.Put != nil:
+				if attrs := f.modelForItem(aws.ToString(item.Put.TableName), item.Put.Item); attrs != nil {
+					f.invalidateCache(cacheKey(aws.ToString(item.Put.TableName), itemKey(attrs, item.Put.Item)))
+				}
+			case item.Update != nil:
+				f.invalidateCache(cacheKey(aws.ToString(item.Update.TableName), item.Update.Key))
+			case item.Delete != nil:
+				f.invalidateCache(cacheKey(aws.ToString(item.Delete.TableName), item.Delete.Key))
+			}
+		}
+	}
+}
+
+// invalidateCache deletes the entry of the key and increments its generation.
+func (f *Fns) invalidateCache(key string) {
+	f.cacheGens.write(key, func() {
+		f.Cache.Delete(key)
+	})
+}
+
+// modelForItem returns the model of any parsed struct type stored in the given table whose key attributes are all
+// present in the item.
+//
+// Struct types sharing the same table also share the same key attribute names so any of them will do.
+func (f *Fns) modelForItem(tableName string, item map[string]types.AttributeValue) (found *internal.Model) {
+	f.cache.Range(func(_, value any) bool {
+		m := value.(*cachedType).model
+		if aws.ToString(m.TableName) != tableName {
+			return true
+		}
+		if _, ok := item[m.HashKey.Name]; !ok {
+			return true
+		}
+		if m.SortKey != nil {
+			if _, ok := item[m.SortKey.Name]; !ok {
+				return true
+			}
+		}
+
+		found = m
+		return false
+	})
+
+	return found
+}
+
+// itemKey returns the key attributes of the item.
+func itemKey(attrs *internal.Model, item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	key := make(map[string]types.AttributeValue, 2)
+	for _, attr := range []*internal.Attribute{attrs.HashKey, attrs.SortKey} {
+		if attr != nil {
+			if av, ok := item[attr.Name]; ok {
+				key[attr.Name] = av
+			}
+		}
+	}
+
+	return key
+}
+
+// itemVersion returns the value of the item's version attribute, or 0 if there is none.
+func itemVersion(attrs *internal.Model, item map[string]types.AttributeValue) int64 {
+	if attrs == nil || attrs.Version == nil {
+		return 0
+	}
+
+	av, ok := item[attrs.Version.Name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0
+	}
+
+	version, _ := strconv.ParseInt(av.Value, 10, 64)
+	return version
+}
+
+// copyItem returns a deep copy of the item so that the cached and returned items don't share any state.
+func copyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+
+	copied := make(map[string]types.AttributeValue, len(item))
+	for name, av := range item {
+		copied[name] = copyAttributeValue(av)
+	}
+
+	return copied
+}
+
+func copyAttributeValue(av types.AttributeValue) types.AttributeValue {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *types.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *types.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: append([]byte(nil), v.Value...)}
+	case *types.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *types.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *types.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: append([]string(nil), v.Value...)}
+	case *types.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: append([]string(nil), v.Value...)}
+	case *types.AttributeValueMemberBS:
+		values := make([][]byte, len(v.Value))
+		for i, b := range v.Value {
+			values[i] = append([]byte(nil), b...)
+		}
+		return &types.AttributeValueMemberBS{Value: values}
+	case *types.AttributeValueMemberL:
+		values := make([]types.AttributeValue, len(v.Value))
+		for i, e := range v.Value {
+			values[i] = copyAttributeValue(e)
+		}
+		return &types.AttributeValueMemberL{Value: values}
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: copyItem(v.Value)}
+	default:
+		return av
+	}
+}
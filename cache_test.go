@@ -0,0 +1,248 @@
+package ddbfns
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUCache(t *testing.T) {
+	now := testTime
+	c := NewLRUCache(func(opts *LRUCacheOpts) {
+		opts.Size = 2
+		opts.TTL = time.Minute
+		opts.Clock = func() time.Time { return now }
+	})
+
+	item := func(s string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: s}}
+	}
+
+	c.Set("a", CacheEntry{Item: item("a")})
+	c.Set("b", CacheEntry{Item: item("b")})
+
+	// a is now the most recently used so c evicts b.
+	_, ok := c.Get("a")
+	assert.True(t, ok)
+	c.Set("c", CacheEntry{Item: item("c")})
+	_, ok = c.Get("b")
+	assert.False(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	// older versions don't replace newer ones.
+	c.Set("a", CacheEntry{Item: item("a2"), Version: 2})
+	c.Set("a", CacheEntry{Item: item("a1"), Version: 1})
+	entry, _ := c.Get("a")
+	assert.Equal(t, CacheEntry{Item: item("a2"), Version: 2}, entry)
+
+	// entries expire after TTL, after which any version can be set.
+	now = now.Add(time.Minute)
+	_, ok = c.Get("c")
+	assert.False(t, ok)
+	c.Set("a", CacheEntry{Item: item("a1"), Version: 1})
+	entry, _ = c.Get("a")
+	assert.Equal(t, CacheEntry{Item: item("a1"), Version: 1}, entry)
+
+	c.Delete("a")
+	_, ok = c.Get("a")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len())
+}
+
+func TestFns_Cache(t *testing.T) {
+	var calls []string
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		calls = append(calls, operation)
+		switch operation {
+		case "GetItem":
+			return 200, `{"Item":{"id":{"S":"hello"},"sort":{"S":"world"},"version":{"N":"1"},"notes":{"S":"from get"}}}`
+		case "UpdateItem":
+			return 200, `{"Attributes":{"id":{"S":"hello"},"sort":{"S":"world"},"version":{"N":"3"},"notes":{"S":"from update"}}}`
+		default:
+			return 200, `{}`
+		}
+	})
+
+	ctx := context.Background()
+	f := &Fns{Cache: NewLRUCache()}
+	key := middlewareTest{Id: "hello", Sort: "world"}
+	get := func(optFns ...func(*GetOpts)) middlewareTest {
+		var got middlewareTest
+		_, err := f.DoGet(ctx, client, key, append(optFns, func(opts *GetOpts) {
+			opts.Decode(&got)
+		})...)
+		assert.NoError(t, err)
+		return got
+	}
+
+	// the second get is served from the cache.
+	assert.Equal(t, middlewareTest{Id: "hello", Sort: "world", Version: 1, Notes: "from get"}, get())
+	assert.Equal(t, middlewareTest{Id: "hello", Sort: "world", Version: 1, Notes: "from get"}, get())
+	assert.Equal(t, []string{"GetItem"}, calls)
+
+	// consistent reads and projections bypass the cache.
+	calls = nil
+	get(func(opts *GetOpts) {
+		opts.ConsistentRead = &[]bool{true}[0]
+	})
+	get(func(opts *GetOpts) {
+		opts.WithProjectionExpression("notes")
+	})
+	assert.Equal(t, []string{"GetItem", "GetItem"}, calls)
+
+	// put refreshes the entry with the new version.
+	calls = nil
+	_, err := f.DoPut(ctx, client, middlewareTest{Id: "hello", Sort: "world", Version: 1, Notes: "from put"})
+	assert.NoError(t, err)
+	assert.Equal(t, middlewareTest{Id: "hello", Sort: "world", Version: 2, Notes: "from put"}, get())
+	assert.Equal(t, []string{"PutItem"}, calls)
+
+	// update with ALL_NEW refreshes the entry too.
+	calls = nil
+	_, err = f.DoUpdate(ctx, client, middlewareTest{Id: "hello", Sort: "world", Version: 2}, func(opts *UpdateOpts) {
+		opts.Set("notes", "from update")
+		opts.ReturnValues = types.ReturnValueAllNew
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, middlewareTest{Id: "hello", Sort: "world", Version: 3, Notes: "from update"}, get())
+	assert.Equal(t, []string{"UpdateItem"}, calls)
+
+	// update without ALL_NEW invalidates the entry.
+	calls = nil
+	_, err = f.DoUpdate(ctx, client, middlewareTest{Id: "hello", Sort: "world", Version: 3}, func(opts *UpdateOpts) {
+		opts.Set("notes", "from update")
+	})
+	assert.NoError(t, err)
+	get()
+	assert.Equal(t, []string{"UpdateItem", "GetItem"}, calls)
+
+	// delete invalidates the entry.
+	calls = nil
+	_, err = f.DoDelete(ctx, client, middlewareTest{Id: "hello", Sort: "world", Version: 1})
+	assert.NoError(t, err)
+	get()
+	assert.Equal(t, []string{"DeleteItem", "GetItem"}, calls)
+}
+
+func TestFns_Cache_conditionFailed(t *testing.T) {
+	calls := 0
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		calls++
+		if operation == "GetItem" {
+			return 200, `{"Item":{"id":{"S":"hello"},"sort":{"S":"world"},"version":{"N":"1"}}}`
+		}
+		return 400, conditionalCheckFailedBody
+	})
+
+	ctx := context.Background()
+	f := &Fns{Cache: NewLRUCache()}
+	key := middlewareTest{Id: "hello", Sort: "world"}
+
+	_, err := f.DoGet(ctx, client, key)
+	assert.NoError(t, err)
+
+	// the failed put means the cached item is stale.
+	_, err = f.DoPut(ctx, client, middlewareTest{Id: "hello", Sort: "world", Version: 1})
+	assert.True(t, IsConditionalCheckFailed(err))
+
+	_, err = f.DoGet(ctx, client, key)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestFns_Cache_copies(t *testing.T) {
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		return 200, `{"Item":{"id":{"S":"hello"},"sort":{"S":"world"},"notes":{"S":"original"}}}`
+	})
+
+	ctx := context.Background()
+	f := &Fns{Cache: NewLRUCache()}
+	key := middlewareTest{Id: "hello", Sort: "world"}
+
+	output, err := f.DoGet(ctx, client, key)
+	assert.NoError(t, err)
+	output.Item["notes"].(*types.AttributeValueMemberS).Value = "modified"
+	delete(output.Item, "id")
+
+	output, err = f.DoGet(ctx, client, key)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"id":    &types.AttributeValueMemberS{Value: "hello"},
+		"sort":  &types.AttributeValueMemberS{Value: "world"},
+		"notes": &types.AttributeValueMemberS{Value: "original"},
+	}, output.Item)
+}
+
+func TestCopyItem(t *testing.T) {
+	item := map[string]types.AttributeValue{
+		"s":    &types.AttributeValueMemberS{Value: "s"},
+		"n":    &types.AttributeValueMemberN{Value: "1"},
+		"b":    &types.AttributeValueMemberB{Value: []byte("b")},
+		"bool": &types.AttributeValueMemberBOOL{Value: true},
+		"null": &types.AttributeValueMemberNULL{Value: true},
+		"ss":   &types.AttributeValueMemberSS{Value: []string{"a", "b"}},
+		"ns":   &types.AttributeValueMemberNS{Value: []string{"1", "2"}},
+		"bs":   &types.AttributeValueMemberBS{Value: [][]byte{[]byte("a")}},
+		"l":    &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberS{Value: "a"}}},
+		"m":    &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{"a": &types.AttributeValueMemberS{Value: "a"}}},
+	}
+
+	copied := copyItem(item)
+	assert.Equal(t, item, copied)
+
+	copied["l"].(*types.AttributeValueMemberL).Value[0].(*types.AttributeValueMemberS).Value = "modified"
+	copied["bs"].(*types.AttributeValueMemberBS).Value[0][0] = 'z'
+	assert.Equal(t, "a", item["l"].(*types.AttributeValueMemberL).Value[0].(*types.AttributeValueMemberS).Value)
+	assert.Equal(t, []byte("a"), item["bs"].(*types.AttributeValueMemberBS).Value[0])
+}
+
+func TestFns_Cache_inFlightGet(t *testing.T) {
+	var calls []string
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		calls = append(calls, operation)
+		if operation == "GetItem" {
+			return 200, `{"Item":{"id":{"S":"hello"},"sort":{"S":"world"},"notes":{"S":"stale"}}}`
+		}
+		return 200, `{}`
+	})
+
+	// the middleware holds the first GetItem after its response has been received.
+	started, release := make(chan struct{}), make(chan struct{})
+	var blocked atomic.Bool
+	f := &Fns{Cache: NewLRUCache()}
+	f.Use(func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (interface{}, error) {
+			output, err := next(ctx, req)
+			if req.Operation == "GetItem" && blocked.CompareAndSwap(false, true) {
+				close(started)
+				<-release
+			}
+			return output, err
+		}
+	})
+
+	ctx := context.Background()
+	key := middlewareTest{Id: "hello", Sort: "world"}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, err := f.DoGet(ctx, client, key)
+		assert.NoError(t, err)
+	}()
+
+	<-started
+	_, err := f.DoDelete(ctx, client, key)
+	assert.NoError(t, err)
+	close(release)
+	<-done
+
+	// the in-flight GetItem must not have put the deleted item back.
+	_, err = f.DoGet(ctx, client, key)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"GetItem", "DeleteItem", "GetItem"}, calls)
+}
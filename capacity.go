@@ -0,0 +1,270 @@
+package ddbfns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// ErrCapacityBudgetExceeded is returned by the DoXyz methods without making the request if the CapacityTracker of the
+// context has spent more than its budget.
+var ErrCapacityBudgetExceeded = errors.New("capacity budget exceeded")
+
+// Capacity is an amount of read and write capacity units.
+type Capacity struct {
+	ReadCapacityUnits  float64
+	WriteCapacityUnits float64
+}
+
+func (c *Capacity) add(other Capacity) {
+	c.ReadCapacityUnits += other.ReadCapacityUnits
+	c.WriteCapacityUnits += other.WriteCapacityUnits
+}
+
+// TableCapacity is the capacity consumed by the requests to a table.
+type TableCapacity struct {
+	// Capacity is the total capacity consumed by the table and its indexes.
+	Capacity
+	// Table is the capacity consumed by the table itself.
+	//
+	// Table and Indexes are only available if the requests returned their breakdown, which is the case if
+	// ReturnConsumedCapacity is INDEXES.
+	Table Capacity
+	// Indexes is the capacity consumed by the global and local secondary indexes of the table, by index name.
+	Indexes map[string]Capacity
+}
+
+// CapacityTrackerOpts customises [WithCapacityTracker].
+type CapacityTrackerOpts struct {
+	// MaxReadCapacityUnits, if positive, is the budget of read capacity units.
+	//
+	// Once more than the budget has been spent, every subsequent request fails with ErrCapacityBudgetExceeded.
+	MaxReadCapacityUnits float64
+	// MaxWriteCapacityUnits, if positive, is the budget of write capacity units.
+	//
+	// Once more than the budget has been spent, every subsequent request fails with ErrCapacityBudgetExceeded.
+	MaxWriteCapacityUnits float64
+}
+
+// CapacityTracker accumulates the capacity consumed by the requests made with a context returned by
+// [WithCapacityTracker].
+//
+// CapacityTracker is safe for concurrent use.
+type CapacityTracker struct {
+	opts CapacityTrackerOpts
+
+	mu     sync.Mutex
+	total  Capacity
+	tables map[string]*TableCapacity
+}
+
+type capacityTrackerKey struct{}
+
+// WithCapacityTracker returns a new context that carries a new CapacityTracker.
+//
+// Every DynamoDB request made by Fns with the returned context, such as [Fns.DoGet], [Fns.DoPut], [Fns.DoTransactPut],
+// and each page of [Fns.DoQuery], has its ReturnConsumedCapacity set to INDEXES unless specified otherwise, and its
+// consumed capacity added to the tracker. Use [CapacityTrackerFromContext] to retrieve the tracker.
+//
+// Usage:
+//
+//	ctx = ddbfns.WithCapacityTracker(ctx, func(opts *ddbfns.CapacityTrackerOpts) {
+//		opts.MaxReadCapacityUnits = 100
+//	})
+//	// ... handle the request.
+//	tracker, _ := ddbfns.CapacityTrackerFromContext(ctx)
+//	log.Printf("spent %v", tracker.Total())
+func WithCapacityTracker(ctx context.Context, optFns ...func(*CapacityTrackerOpts)) context.Context {
+	t := &CapacityTracker{tables: make(map[string]*TableCapacity)}
+	for _, fn := range optFns {
+		fn(&t.opts)
+	}
+
+	return context.WithValue(ctx, capacityTrackerKey{}, t)
+}
+
+// CapacityTrackerFromContext returns the CapacityTracker created by WithCapacityTracker.
+func CapacityTrackerFromContext(ctx context.Context) (*CapacityTracker, bool) {
+	t, _ := ctx.Value(capacityTrackerKey{}).(*CapacityTracker)
+	return t, t != nil
+}
+
+// withoutCapacityTracker returns a new context that hides the CapacityTracker of the given context, if any.
+func withoutCapacityTracker(ctx context.Context) context.Context {
+	if _, ok := CapacityTrackerFromContext(ctx); !ok {
+		return ctx
+	}
+
+	return context.WithValue(ctx, capacityTrackerKey{}, (*CapacityTracker)(nil))
+}
+
+// Total returns the total capacity consumed across all tables.
+func (t *CapacityTracker) Total() Capacity {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.total
+}
+
+// Tables returns a copy of the capacity consumed by table name.
+func (t *CapacityTracker) Tables() map[string]TableCapacity {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tables := make(map[string]TableCapacity, len(t.tables))
+	for name, tc := range t.tables {
+		c := *tc
+		if tc.Indexes != nil {
+			c.Indexes = make(map[string]Capacity, len(tc.Indexes))
+			for indexName, ic := range tc.Indexes {
+				c.Indexes[indexName] = ic
+			}
+		}
+		tables[name] = c
+	}
+
+	return tables
+}
+
+// checkBudget returns an error wrapping ErrCapacityBudgetExceeded if more than the budget has been spent.
+func (t *CapacityTracker) checkBudget() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if max := t.opts.MaxReadCapacityUnits; max > 0 && t.total.ReadCapacityUnits > max {
+		return fmt.Errorf("%w: spent %g of %g read capacity units", ErrCapacityBudgetExceeded, t.total.ReadCapacityUnits, max)
+	}
+	if max := t.opts.MaxWriteCapacityUnits; max > 0 && t.total.WriteCapacityUnits > max {
+		return fmt.Errorf("%w: spent %g of %g write capacity units", ErrCapacityBudgetExceeded, t.total.WriteCapacityUnits, max)
+	}
+
+	return nil
+}
+
+// add adds the consumed capacity of a request.
+//
+// Capacity units that aren't broken down into reads and writes are attributed by the operation.
+func (t *CapacityTracker) add(operation string, cc []types.ConsumedCapacity) {
+	write := isWriteOperation(operation)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, c := range cc {
+		if c.TableName == nil {
+			continue
+		}
+
+		tc, ok := t.tables[*c.TableName]
+		if !ok {
+			tc = &TableCapacity{}
+			t.tables[*c.TableName] = tc
+		}
+
+		total := toCapacity(c.CapacityUnits, c.ReadCapacityUnits, c.WriteCapacityUnits, write)
+		tc.Capacity.add(total)
+		t.total.add(total)
+
+		if c.Table != nil {
+			tc.Table.add(toCapacity(c.Table.CapacityUnits, c.Table.ReadCapacityUnits, c.Table.WriteCapacityUnits, write))
+		}
+		for _, indexes := range []map[string]types.Capacity{c.GlobalSecondaryIndexes, c.LocalSecondaryIndexes} {
+			for indexName, ic := range indexes {
+				if tc.Indexes == nil {
+					tc.Indexes = make(map[string]Capacity)
+				}
+
+				v := tc.Indexes[indexName]
+				v.add(toCapacity(ic.CapacityUnits, ic.ReadCapacityUnits, ic.WriteCapacityUnits, write))
+				tc.Indexes[indexName] = v
+			}
+		}
+	}
+}
+
+// toCapacity prefers the read and write capacity units if either is given, otherwise the capacity units are attributed
+// to reads or writes.
+func toCapacity(units, readUnits, writeUnits *float64, write bool) Capacity {
+	if readUnits != nil || writeUnits != nil {
+		c := Capacity{}
+		if readUnits != nil {
+			c.ReadCapacityUnits = *readUnits
+		}
+		if writeUnits != nil {
+			c.WriteCapacityUnits = *writeUnits
+		}
+		return c
+	}
+
+	if units == nil {
+		return Capacity{}
+	}
+	if write {
+		return Capacity{WriteCapacityUnits: *units}
+	}
+	return Capacity{ReadCapacityUnits: *units}
+}
+
+func isWriteOperation(operation string) bool {
+	switch operation {
+	case "PutItem", "UpdateItem", "DeleteItem", "TransactWriteItems", "BatchWriteItem":
+		return true
+	default:
+		return false
+	}
+}
+
+// withReturnConsumedCapacity returns a copy of the input with ReturnConsumedCapacity set to INDEXES if it's not
+// specified, so that the input given by the caller isn't modified.
+func withReturnConsumedCapacity(input interface{}) interface{} {
+	switch input := input.(type) {
+	case *dynamodb.GetItemInput:
+		if input.ReturnConsumedCapacity == "" {
+			copied := *input
+			copied.ReturnConsumedCapacity = types.ReturnConsumedCapacityIndexes
+			return &copied
+		}
+	case *dynamodb.PutItemInput:
+		if input.ReturnConsumedCapacity == "" {
+			copied := *input
+			copied.ReturnConsumedCapacity = types.ReturnConsumedCapacityIndexes
+			return &copied
+		}
+	case *dynamodb.UpdateItemInput:
+		if input.ReturnConsumedCapacity == "" {
+			copied := *input
+			copied.ReturnConsumedCapacity = types.ReturnConsumedCapacityIndexes
+			return &copied
+		}
+	case *dynamodb.DeleteItemInput:
+		if input.ReturnConsumedCapacity == "" {
+			copied := *input
+			copied.ReturnConsumedCapacity = types.ReturnConsumedCapacityIndexes
+			return &copied
+		}
+	case *dynamodb.QueryInput:
+		if input.ReturnConsumedCapacity == "" {
+			copied := *input
+			copied.ReturnConsumedCapacity = types.ReturnConsumedCapacityIndexes
+			return &copied
+		}
+	case *dynamodb.ScanInput:
+		if input.ReturnConsumedCapacity == "" {
+			copied := *input
+			copied.ReturnConsumedCapacity = types.ReturnConsumedCapacityIndexes
+			return &copied
+		}
+	case *dynamodb.TransactWriteItemsInput:
+		if input.ReturnConsumedCapacity == "" {
+			copied := *input
+			copied.ReturnConsumedCapacity = types.ReturnConsumedCapacityIndexes
+			return &copied
+		}
+	}
+
+	return input
+}
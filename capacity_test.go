@@ -0,0 +1,107 @@
+package ddbfns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithCapacityTracker(t *testing.T) {
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		assert.Contains(t, string(body), `"ReturnConsumedCapacity":"INDEXES"`)
+
+		switch operation {
+		case "GetItem":
+			return 200, `{"ConsumedCapacity":{"TableName":"my-table","CapacityUnits":0.5,"ReadCapacityUnits":0.5,"Table":{"CapacityUnits":0.5,"ReadCapacityUnits":0.5}}}`
+		case "PutItem":
+			return 200, `{"ConsumedCapacity":{"TableName":"my-table","CapacityUnits":2}}`
+		case "Query":
+			return 200, `{"Items":[],"ConsumedCapacity":{"TableName":"my-table","CapacityUnits":1,"ReadCapacityUnits":1,"GlobalSecondaryIndexes":{"my-index":{"CapacityUnits":1,"ReadCapacityUnits":1}}}}`
+		default:
+			return 200, `{"ConsumedCapacity":[{"TableName":"my-table","CapacityUnits":1},{"TableName":"other-table","CapacityUnits":3}]}`
+		}
+	})
+
+	ctx := WithCapacityTracker(context.Background())
+	f := &Fns{}
+
+	_, err := f.DoGet(ctx, client, middlewareTest{Id: "hello", Sort: "world"})
+	assert.NoError(t, err)
+	_, err = f.DoPut(ctx, client, middlewareTest{Id: "hello", Sort: "world"})
+	assert.NoError(t, err)
+	err = f.DoQuery(ctx, client, &dynamodb.QueryInput{TableName: aws.String("my-table"), IndexName: aws.String("my-index")}, func(v interface{}) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	_, err = f.doTransactWriteItems(ctx, client, &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{}}, nil, nil)
+	assert.NoError(t, err)
+
+	tracker, ok := CapacityTrackerFromContext(ctx)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	assert.Equal(t, Capacity{ReadCapacityUnits: 1.5, WriteCapacityUnits: 6}, tracker.Total())
+	assert.Equal(t, map[string]TableCapacity{
+		"my-table": {
+			Capacity: Capacity{ReadCapacityUnits: 1.5, WriteCapacityUnits: 3},
+			Table:    Capacity{ReadCapacityUnits: 0.5},
+			Indexes: map[string]Capacity{
+				"my-index": {ReadCapacityUnits: 1},
+			},
+		},
+		"other-table": {
+			Capacity: Capacity{WriteCapacityUnits: 3},
+		},
+	}, tracker.Tables())
+}
+
+func TestWithCapacityTracker_budget(t *testing.T) {
+	calls := 0
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		calls++
+		return 200, `{"ConsumedCapacity":{"TableName":"my-table","CapacityUnits":1}}`
+	})
+
+	ctx := WithCapacityTracker(context.Background(), func(opts *CapacityTrackerOpts) {
+		opts.MaxReadCapacityUnits = 1.5
+	})
+	f := &Fns{}
+
+	// the first two reads are within budget; the second one puts the tracker over.
+	for i := 0; i < 2; i++ {
+		_, err := f.DoGet(ctx, client, middlewareTest{Id: "hello", Sort: "world"})
+		assert.NoError(t, err)
+	}
+
+	_, err := f.DoGet(ctx, client, middlewareTest{Id: "hello", Sort: "world"})
+	assert.ErrorIs(t, err, ErrCapacityBudgetExceeded)
+	assert.Equal(t, "capacity budget exceeded: spent 2 of 1.5 read capacity units", err.Error())
+	assert.Equal(t, 2, calls)
+
+	// once over budget, writes fail as well.
+	_, err = f.DoPut(ctx, client, middlewareTest{Id: "hello", Sort: "world"})
+	assert.ErrorIs(t, err, ErrCapacityBudgetExceeded)
+	assert.Equal(t, 2, calls)
+}
+
+func TestWithCapacityTracker_explicitReturnConsumedCapacity(t *testing.T) {
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		assert.Contains(t, string(body), `"ReturnConsumedCapacity":"TOTAL"`)
+		return 200, `{"ConsumedCapacity":{"TableName":"my-table","CapacityUnits":1}}`
+	})
+
+	ctx := WithCapacityTracker(context.Background())
+	_, err := DefaultFns.DoUpdate(ctx, client, middlewareTest{Id: "hello", Sort: "world"}, func(opts *UpdateOpts) {
+		opts.Set("notes", "hi")
+		opts.ReturnConsumedCapacity = types.ReturnConsumedCapacityTotal
+	})
+	assert.NoError(t, err)
+
+	tracker, _ := CapacityTrackerFromContext(ctx)
+	assert.Equal(t, Capacity{WriteCapacityUnits: 1}, tracker.Total())
+}
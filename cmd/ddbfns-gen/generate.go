@@ -0,0 +1,518 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// supportedOptions are the `dynamodbav` tag options that the generated code knows how to handle.
+var supportedOptions = map[string]bool{
+	"hashkey":      true,
+	"sortkey":      true,
+	"version":      true,
+	"createdTime":  true,
+	"modifiedTime": true,
+	"deletedTime":  true,
+	"ttl":          true,
+	"unixtime":     true,
+	"omitempty":    true,
+	"stringset":    true,
+}
+
+// unsupportedTags are the other struct tags that require the reflection-based parsing.
+var unsupportedTags = []string{"keyFormat", "unique", "gsi", "lsi", "min", "max", "maxLength", "pattern", "oneof"}
+
+// schemaOptions are the options that are passed to ddbfns.Schema.
+var schemaOptions = map[string]bool{
+	"hashkey":      true,
+	"sortkey":      true,
+	"version":      true,
+	"createdTime":  true,
+	"modifiedTime": true,
+	"deletedTime":  true,
+	"ttl":          true,
+	"unixtime":     true,
+}
+
+type model struct {
+	Name      string
+	// TableName is empty if the tableName tag is empty.
+	TableName string
+	Fields    []*field
+	HashKey   *field
+	SortKey   *field
+	Version   *field
+}
+
+type field struct {
+	Name      string
+	Attribute string
+	Options   []string
+	Type      string
+	OmitEmpty bool
+	UnixTime  bool
+	StringSet bool
+	// NonEmpty is the Go expression that is true if the field of `v` must not be omitted.
+	NonEmpty string
+}
+
+// SchemaOptions returns the options that are passed to ddbfns.Schema.
+func (f *field) SchemaOptions() []string {
+	var options []string
+	for _, option := range f.Options {
+		if schemaOptions[option] {
+			options = append(options, option)
+		}
+	}
+
+	return options
+}
+
+// ConstName returns the name of the attribute name constant.
+func (f *field) ConstName(m *model) string {
+	return m.Name + f.Name + "Attr"
+}
+
+// Generate generates the adapters for the named struct types declared in the Go package in dir.
+func Generate(dir string, typeNames []string) ([]byte, error) {
+	fset := token.NewFileSet()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []*ast.File
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") || strings.HasSuffix(name, "_ddbfns.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no Go files in %s", dir)
+	}
+
+	specs := make(map[string]*ast.TypeSpec)
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if spec, ok := n.(*ast.TypeSpec); ok {
+				specs[spec.Name.Name] = spec
+			}
+			return true
+		})
+	}
+
+	var models []*model
+	for _, name := range typeNames {
+		spec, ok := specs[name]
+		if !ok {
+			return nil, fmt.Errorf(`type "%s" not found in %s`, name, dir)
+		}
+
+		m, err := parseModel(spec, specs)
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, m)
+	}
+
+	var buf bytes.Buffer
+	if err = fileTemplate.Execute(&buf, struct {
+		Args    string
+		Package string
+		Imports []importSpec
+		Models  []*model
+	}{
+		Args:    strings.Join(typeNames, ","),
+		Package: files[0].Name.Name,
+		Imports: imports(models),
+		Models:  models,
+	}); err != nil {
+		return nil, err
+	}
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code error: %w\n%s", err, buf.Bytes())
+	}
+
+	return src, nil
+}
+
+func parseModel(spec *ast.TypeSpec, specs map[string]*ast.TypeSpec) (*model, error) {
+	st, ok := spec.Type.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf(`type "%s" is not a struct`, spec.Name.Name)
+	}
+	if spec.TypeParams != nil {
+		return nil, fmt.Errorf(`generic type "%s" is not supported`, spec.Name.Name)
+	}
+
+	m := &model{Name: spec.Name.Name}
+	for _, astField := range st.Fields.List {
+		var tag reflect.StructTag
+		if astField.Tag != nil {
+			s, err := strconv.Unquote(astField.Tag.Value)
+			if err != nil {
+				return nil, err
+			}
+			tag = reflect.StructTag(s)
+		}
+
+		if len(astField.Names) == 0 {
+			if tag.Get("dynamodbav") == "-" {
+				continue
+			}
+			return nil, fmt.Errorf(`embedded field "%s" in type "%s" is not supported`, exprString(astField.Type), m.Name)
+		}
+
+		for _, tagName := range unsupportedTags {
+			if _, ok := tag.Lookup(tagName); ok {
+				return nil, fmt.Errorf(`%s tag on field "%s" in type "%s" is not supported; use the struct tags without generated code instead`, tagName, astField.Names[0].Name, m.Name)
+			}
+		}
+
+		for _, ident := range astField.Names {
+			if !ident.IsExported() {
+				continue
+			}
+
+			f, err := parseField(ident.Name, tag, astField.Type, specs)
+			if err != nil {
+				return nil, fmt.Errorf(`field "%s" in type "%s": %w`, ident.Name, m.Name, err)
+			}
+			if f == nil {
+				continue
+			}
+
+			for _, option := range f.Options {
+				var target **field
+				switch option {
+				case "hashkey":
+					target = &m.HashKey
+					tableName, ok := tag.Lookup("tableName")
+					if !ok {
+						return nil, fmt.Errorf(`missing tableName tag on hashkey field in type "%s"`, m.Name)
+					}
+					m.TableName = tableName
+				case "sortkey":
+					target = &m.SortKey
+				case "version":
+					target = &m.Version
+				default:
+					continue
+				}
+
+				if *target != nil {
+					return nil, fmt.Errorf(`found multiple %s fields in type "%s"`, option, m.Name)
+				}
+				*target = f
+			}
+
+			m.Fields = append(m.Fields, f)
+		}
+	}
+
+	if m.HashKey == nil {
+		return nil, fmt.Errorf(`no hashkey field in type "%s"`, m.Name)
+	}
+
+	return m, nil
+}
+
+// parseField returns nil if the field is not a DynamoDB attribute.
+func parseField(name string, tag reflect.StructTag, typ ast.Expr, specs map[string]*ast.TypeSpec) (*field, error) {
+	f := &field{Name: name, Attribute: name, Type: exprString(typ)}
+
+	if v, ok := tag.Lookup("dynamodbav"); ok {
+		options := strings.Split(v, ",")
+		switch options[0] {
+		case "-":
+			return nil, nil
+		case "":
+		default:
+			f.Attribute = options[0]
+		}
+
+		for _, option := range options[1:] {
+			if !supportedOptions[option] {
+				return nil, fmt.Errorf(`option "%s" is not supported; use the struct tags without generated code instead`, option)
+			}
+
+			switch option {
+			case "omitempty":
+				f.OmitEmpty = true
+			case "unixtime":
+				if f.Type != "time.Time" {
+					return nil, fmt.Errorf(`unixtime option requires time.Time, got %s`, f.Type)
+				}
+				f.UnixTime = true
+			case "stringset":
+				if f.Type != "[]string" {
+					return nil, fmt.Errorf(`stringset option requires []string, got %s`, f.Type)
+				}
+				f.StringSet = true
+			}
+
+			f.Options = append(f.Options, option)
+		}
+	}
+
+	if f.OmitEmpty {
+		nonEmpty, err := nonEmptyExpr("v."+name, typ, specs)
+		if err != nil {
+			return nil, err
+		}
+		f.NonEmpty = nonEmpty
+	}
+
+	return f, nil
+}
+
+// nonEmptyExpr returns the expression that is true unless attributevalue.Encoder would omit the value with omitempty.
+//
+// Returns an empty string if the value is never omitted.
+func nonEmptyExpr(value string, typ ast.Expr, specs map[string]*ast.TypeSpec) (string, error) {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		switch t.Name {
+		case "string":
+			return value + ` != ""`, nil
+		case "bool":
+			return value, nil
+		case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr", "float32", "float64", "byte", "rune":
+			return value + " != 0", nil
+		case "any":
+			return value + " != nil", nil
+		}
+
+		// named types declared in the same package are resolved to their underlying type.
+		if spec, ok := specs[t.Name]; ok {
+			if _, ok = spec.Type.(*ast.StructType); ok {
+				return "", nil
+			}
+			return nonEmptyExpr(value, spec.Type, specs)
+		}
+	case *ast.StarExpr, *ast.MapType, *ast.InterfaceType, *ast.FuncType, *ast.ChanType:
+		return value + " != nil", nil
+	case *ast.ArrayType:
+		if t.Len == nil {
+			return value + " != nil", nil
+		}
+		return "len(" + value + ") != 0", nil
+	case *ast.StructType:
+		return "", nil
+	case *ast.SelectorExpr:
+		if exprString(t) == "time.Time" {
+			return "", nil
+		}
+	}
+
+	return "", fmt.Errorf(`omitempty on type "%s" is not supported`, exprString(typ))
+}
+
+func exprString(expr ast.Expr) string {
+	var buf bytes.Buffer
+	_ = format.Node(&buf, token.NewFileSet(), expr)
+	return buf.String()
+}
+
+type importSpec struct {
+	Name string
+	Path string
+	// Blank separates the standard library imports from the others.
+	Blank bool
+}
+
+// imports returns the imports needed by the generated code.
+func imports(models []*model) []importSpec {
+	paths := []string{
+		"fmt",
+		"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue",
+		"github.com/aws/aws-sdk-go-v2/service/dynamodb/types",
+		ddbfnsPath,
+	}
+
+	for _, m := range models {
+		if m.Version != nil && !slices.Contains(paths, "github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression") {
+			paths = append(paths, "github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression")
+		}
+		for _, f := range m.Fields {
+			if f.UnixTime && !slices.Contains(paths, "time") {
+				paths = append(paths, "time")
+			}
+		}
+	}
+
+	slices.SortFunc(paths, func(a, b string) int {
+		if isStd(a) != isStd(b) {
+			if isStd(a) {
+				return -1
+			}
+			return 1
+		}
+		return strings.Compare(a, b)
+	})
+
+	var specs []importSpec
+	for i, path := range paths {
+		if i > 0 && isStd(paths[i-1]) && !isStd(path) {
+			specs = append(specs, importSpec{Blank: true})
+		}
+
+		spec := importSpec{Path: path}
+		if path == ddbfnsPath {
+			spec.Name = "ddbfns"
+		}
+		specs = append(specs, spec)
+	}
+
+	return specs
+}
+
+const ddbfnsPath = "github.com/nguyengg/go-ddb-fns"
+
+// isStd returns true if the import path is from the standard library.
+func isStd(path string) bool {
+	return !strings.Contains(strings.Split(path, "/")[0], ".")
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by ddbfns-gen -type {{ .Args }}; DO NOT EDIT.
+
+package {{ .Package }}
+
+import (
+{{- range .Imports }}
+{{- if .Blank }}
+{{ else }}
+	{{ with .Name }}{{ . }} {{ end }}"{{ .Path }}"
+{{- end }}
+{{- end }}
+)
+{{ range $m := .Models }}
+// Attribute names of {{ $m.Name }}.
+const (
+{{- range .Fields }}
+	{{ .ConstName $m }} = {{ printf "%q" .Attribute }}
+{{- end }}
+)
+
+{{- if $m.TableName }}
+
+var {{ $m.Name }}TableName = {{ printf "%q" $m.TableName }}
+{{- end }}
+
+var {{ $m.Name }}Schema = &ddbfns.Schema{
+{{- if $m.TableName }}
+	TableName: &{{ $m.Name }}TableName,
+{{- end }}
+	Attributes: []ddbfns.SchemaAttribute{
+{{- range .Fields }}
+		{Field: {{ printf "%q" .Name }}, Name: {{ .ConstName $m }}{{ with .SchemaOptions }}, Options: []string{ {{- range $i, $o := . }}{{ if $i }}, {{ end }}"{{ $o }}"{{ end -}} }{{ end }}},
+{{- end }}
+	},
+}
+
+// DynamoDBSchema implements ddbfns.SchemaProvider.
+func (v *{{ $m.Name }}) DynamoDBSchema() *ddbfns.Schema {
+	return {{ $m.Name }}Schema
+}
+
+// DynamoDBKeys implements ddbfns.KeyBuilder.
+func (v {{ $m.Name }}) DynamoDBKeys() (hashKey, sortKey interface{}) {
+	return v.{{ $m.HashKey.Name }}, {{ if $m.SortKey }}v.{{ $m.SortKey.Name }}{{ else }}nil{{ end }}
+}
+
+// MarshalDynamoDBAttributeValue implements attributevalue.Marshaler.
+func (v {{ $m.Name }}) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	item := make(map[string]types.AttributeValue, {{ len $m.Fields }})
+{{- range .Fields }}
+{{- if .NonEmpty }}
+	if {{ .NonEmpty }} {
+{{- else }}
+	{
+{{- end }}
+{{- if .StringSet }}
+		if len(v.{{ .Name }}) != 0 {
+			item[{{ .ConstName $m }}] = &types.AttributeValueMemberSS{Value: v.{{ .Name }}}
+		} else {
+			item[{{ .ConstName $m }}] = &types.AttributeValueMemberNULL{Value: true}
+		}
+{{- else }}
+{{- if .UnixTime }}
+		av, err := attributevalue.UnixTime(v.{{ .Name }}).MarshalDynamoDBAttributeValue()
+{{- else }}
+		av, err := attributevalue.Marshal(v.{{ .Name }})
+{{- end }}
+		if err != nil {
+			return nil, fmt.Errorf("marshal {{ .Name }} error: %w", err)
+		}
+		item[{{ .ConstName $m }}] = av
+{{- end }}
+	}
+{{- end }}
+
+	return &types.AttributeValueMemberM{Value: item}, nil
+}
+
+// UnmarshalDynamoDBAttributeValue implements attributevalue.Unmarshaler.
+func (v *{{ $m.Name }}) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	switch av := av.(type) {
+	case *types.AttributeValueMemberNULL:
+		return nil
+	case *types.AttributeValueMemberM:
+{{- range .Fields }}
+		if av, ok := av.Value[{{ .ConstName $m }}]; ok {
+{{- if .UnixTime }}
+			var t attributevalue.UnixTime
+			if err := attributevalue.Unmarshal(av, &t); err != nil {
+				return fmt.Errorf("unmarshal {{ .Name }} error: %w", err)
+			}
+			v.{{ .Name }} = time.Time(t)
+{{- else }}
+			if err := attributevalue.Unmarshal(av, &v.{{ .Name }}); err != nil {
+				return fmt.Errorf("unmarshal {{ .Name }} error: %w", err)
+			}
+{{- end }}
+		}
+{{- end }}
+		return nil
+	default:
+		return fmt.Errorf("cannot unmarshal %T into {{ $m.Name }}", av)
+	}
+}
+{{- with $m.Version }}
+
+// NextVersion returns the version that ddbfns will write for v.
+func (v {{ $m.Name }}) NextVersion() {{ .Type }} {
+	return v.{{ .Name }} + 1
+}
+
+// VersionCondition returns the optimistic locking condition that ddbfns will add for v.
+func (v {{ $m.Name }}) VersionCondition() expression.ConditionBuilder {
+	if v.{{ .Name }} == 0 {
+		return expression.Name({{ $m.HashKey.ConstName $m }}).AttributeNotExists()
+	}
+
+	return expression.Name({{ .ConstName $m }}).Equal(expression.Value(v.{{ .Name }}))
+}
+{{- end }}
+{{ end -}}
+`))
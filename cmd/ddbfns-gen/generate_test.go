@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+// TestGenerate compares the generated code against the checked-in internal/gentest/models_ddbfns.go whose tests in turn
+// compare the generated adapters against the reflection-based outputs.
+func TestGenerate(t *testing.T) {
+	dir := filepath.Join("..", "..", "internal", "gentest")
+	golden := filepath.Join(dir, "models_ddbfns.go")
+
+	got, err := Generate(dir, []string{"Item", "Counter"})
+	if err != nil {
+		t.Errorf("Generate() error = %v", err)
+		return
+	}
+
+	if *update {
+		if err = os.WriteFile(golden, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, string(want), string(got))
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{
+			name: "keyFormat",
+			src: `type Test struct {
+	PK string ` + "`" + `dynamodbav:"pk,hashkey" tableName:"" keyFormat:"USER#{ID}"` + "`" + `
+}`,
+			wantErr: "keyFormat tag",
+		},
+		{
+			name: "unique",
+			src: `type Test struct {
+	PK    string ` + "`" + `dynamodbav:"pk,hashkey" tableName:""` + "`" + `
+	Email string ` + "`" + `dynamodbav:"email" unique:"true"` + "`" + `
+}`,
+			wantErr: "unique tag",
+		},
+		{
+			name: "unknown option",
+			src: `type Test struct {
+	PK string ` + "`" + `dynamodbav:"pk,hashkey,immutable" tableName:""` + "`" + `
+}`,
+			wantErr: `option "immutable" is not supported`,
+		},
+		{
+			name: "missing hashkey",
+			src: `type Test struct {
+	PK string ` + "`" + `dynamodbav:"pk"` + "`" + `
+}`,
+			wantErr: "no hashkey field",
+		},
+		{
+			name: "missing tableName",
+			src: `type Test struct {
+	PK string ` + "`" + `dynamodbav:"pk,hashkey"` + "`" + `
+}`,
+			wantErr: "missing tableName tag",
+		},
+		{
+			name: "embedded",
+			src: `type Base struct {
+	PK string ` + "`" + `dynamodbav:"pk,hashkey" tableName:""` + "`" + `
+}
+
+type Test struct {
+	Base
+}`,
+			wantErr: `embedded field "Base"`,
+		},
+		{
+			name: "stringset",
+			src: `type Test struct {
+	PK   string ` + "`" + `dynamodbav:"pk,hashkey" tableName:""` + "`" + `
+	Tags []int ` + "`" + `dynamodbav:"tags,stringset"` + "`" + `
+}`,
+			wantErr: "stringset option requires []string",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, "test.go"), []byte("package test\n\n"+tt.src+"\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err := Generate(dir, []string{"Test"})
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
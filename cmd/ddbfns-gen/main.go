@@ -0,0 +1,73 @@
+// Command ddbfns-gen generates reflection-free adapters for structs that use the ddbfns struct tags.
+//
+// For each named type, ddbfns-gen generates:
+//   - typed attribute name constants such as ItemVersionAttr for use with UpdateOpts.
+//   - DynamoDBSchema which lets ddbfns.Fns skip parsing the struct tags by reflection.
+//   - DynamoDBKeys which extracts the hash and sort keys.
+//   - MarshalDynamoDBAttributeValue and UnmarshalDynamoDBAttributeValue.
+//   - NextVersion and VersionCondition if the struct has a version field.
+//
+// Usage:
+//
+//	//go:generate go run github.com/nguyengg/go-ddb-fns/cmd/ddbfns-gen -type Item,Order
+//
+// Only the `dynamodbav` options supported by the generated code are allowed; the other ddbfns struct tags such as
+// `keyFormat` and `unique` must keep using the reflection-based parsing.
+//
+// The generated MarshalDynamoDBAttributeValue and UnmarshalDynamoDBAttributeValue always use the default options of
+// attributevalue.Marshal and attributevalue.Unmarshal, so the options of a customised ddbfns.Fns Encoder or Decoder
+// such as EncoderOptions.TagKey or EncoderOptions.EncodeTime do not apply to the generated types. Do not generate code
+// for types that rely on such options.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("ddbfns-gen: ")
+
+	typeNames := flag.String("type", "", "comma-separated list of type names; must be set")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_ddbfns.go")
+	flag.Usage = func() {
+		_, _ = fmt.Fprintf(os.Stderr, "Usage: ddbfns-gen -type T[,T...] [-output file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		dir = flag.Arg(0)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	types := strings.Split(*typeNames, ",")
+	src, err := Generate(dir, types)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	name := *output
+	if name == "" {
+		name = filepath.Join(dir, strings.ToLower(types[0])+"_ddbfns.go")
+	}
+
+	if err = os.WriteFile(name, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/nguyengg/go-ddb-fns/cmd/ddbfns
+
+go 1.23.5
+
+require (
+	github.com/stretchr/testify v1.10.0
+	golang.org/x/tools v0.36.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/mod v0.27.0 // indirect
+	golang.org/x/sync v0.16.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
@@ -0,0 +1,533 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Rule is a lint rule.
+type Rule struct {
+	ID          string
+	Description string
+	// Level is the SARIF level of the rule: "error", "warning", or "note".
+	Level string
+}
+
+var (
+	ruleDuplicate = &Rule{
+		ID:          "duplicate-attribute",
+		Description: "A struct has multiple hashkey, sortkey, version, or timestamp fields, or an index has multiple key fields.",
+		Level:       "error",
+	}
+	ruleUnsupportedType = &Rule{
+		ID:          "unsupported-type",
+		Description: "The field's type is not supported by the tag option.",
+		Level:       "error",
+	}
+	ruleMissingTableName = &Rule{
+		ID:          "missing-table-name",
+		Description: "The hashkey field is missing the tableName tag.",
+		Level:       "error",
+	}
+	ruleMissingHashKey = &Rule{
+		ID:          "missing-hashkey",
+		Description: "A struct with ddbfns tag options has no hashkey field.",
+		Level:       "error",
+	}
+	ruleUnixTime = &Rule{
+		ID:          "unixtime-non-time",
+		Description: "The unixtime option is used on a field that is not a time.Time.",
+		Level:       "warning",
+	}
+	ruleUnknownOption = &Rule{
+		ID:          "unknown-option",
+		Description: "The dynamodbav tag has an option that is neither a ddbfns nor an attributevalue option.",
+		Level:       "warning",
+	}
+	ruleIgnoredOptions = &Rule{
+		ID:          "ignored-options",
+		Description: "The dynamodbav tag has options but no attribute name so ddbfns ignores the field.",
+		Level:       "warning",
+	}
+	ruleOmitEmptyKey = &Rule{
+		ID:          "omitempty-key",
+		Description: "The omitempty option is used on a hashkey or sortkey field.",
+		Level:       "warning",
+	}
+	ruleMissingIndexKey = &Rule{
+		ID:          "missing-index-key",
+		Description: "A global secondary index has no hashkey field, or a local secondary index is declared on a struct without a sortkey field.",
+		Level:       "error",
+	}
+	ruleInvalidTag = &Rule{
+		ID:          "invalid-tag",
+		Description: "A gsi, lsi, or validation struct tag has a value that ddbfns cannot parse.",
+		Level:       "error",
+	}
+	ruleReservedWord = &Rule{
+		ID:          "reserved-word",
+		Description: "The attribute name is a DynamoDB reserved word which requires an expression attribute name.",
+		Level:       "note",
+	}
+
+	// Rules are all the lint rules.
+	Rules = []*Rule{
+		ruleDuplicate,
+		ruleUnsupportedType,
+		ruleMissingTableName,
+		ruleMissingHashKey,
+		ruleUnixTime,
+		ruleUnknownOption,
+		ruleIgnoredOptions,
+		ruleOmitEmptyKey,
+		ruleMissingIndexKey,
+		ruleInvalidTag,
+		ruleReservedWord,
+	}
+)
+
+// Diagnostic is a single lint finding.
+type Diagnostic struct {
+	Rule    *Rule
+	Pos     token.Position
+	Message string
+}
+
+// String formats the diagnostic the same way go vet does.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s (%s)", d.Pos, d.Message, d.Rule.ID)
+}
+
+// ddbfnsOptions are the `dynamodbav` options that ddbfns understands, mapped to whether the option is unique per struct.
+var ddbfnsOptions = map[string]bool{
+	"hashkey":      true,
+	"sortkey":      true,
+	"version":      true,
+	"createdTime":  true,
+	"modifiedTime": true,
+	"deletedTime":  true,
+	"ttl":          true,
+	"immutable":    false,
+}
+
+// encoderOptions are the `dynamodbav` options that attributevalue.Encoder understands.
+var encoderOptions = map[string]bool{
+	"omitempty":     true,
+	"omitemptyelem": true,
+	"nullempty":     true,
+	"nullemptyelem": true,
+	"string":        true,
+	"stringset":     true,
+	"numberset":     true,
+	"binaryset":     true,
+	"unixtime":      true,
+}
+
+// Lint loads the packages matching the patterns and lints every struct that uses ddbfns struct tags.
+func Lint(dir string, patterns ...string) ([]Diagnostic, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedFiles | packages.NeedSyntax | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedImports | packages.NeedDeps,
+		Dir:  dir,
+	}, patterns...)
+	if err != nil {
+		return nil, err
+	}
+
+	var diags []Diagnostic
+	for _, pkg := range pkgs {
+		if len(pkg.Errors) != 0 {
+			return nil, fmt.Errorf("load package %s error: %v", pkg.PkgPath, pkg.Errors[0])
+		}
+
+		l := &linter{pkg: pkg, timeType: findTimeType(pkg.Types, make(map[*types.Package]bool))}
+		for _, file := range pkg.Syntax {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if st, ok := n.(*ast.StructType); ok {
+					l.lintStruct(st)
+				}
+				return true
+			})
+		}
+		diags = append(diags, l.diags...)
+	}
+
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Pos, diags[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+
+	return diags, nil
+}
+
+type linter struct {
+	pkg      *packages.Package
+	timeType types.Type
+	diags    []Diagnostic
+}
+
+// structField is a field of a struct that has a `dynamodbav` tag.
+type structField struct {
+	name    string
+	pos     token.Pos
+	typ     types.Type
+	tag     reflect.StructTag
+	attr    string
+	options []string
+}
+
+func (l *linter) report(rule *Rule, pos token.Pos, format string, args ...interface{}) {
+	l.diags = append(l.diags, Diagnostic{Rule: rule, Pos: l.pkg.Fset.Position(pos), Message: fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) lintStruct(st *ast.StructType) {
+	var fields []*structField
+	relevant := false
+	for _, f := range st.Fields.List {
+		if f.Tag == nil || len(f.Names) == 0 {
+			continue
+		}
+
+		s, err := strconv.Unquote(f.Tag.Value)
+		if err != nil {
+			continue
+		}
+
+		tag := reflect.StructTag(s)
+		v, ok := tag.Lookup("dynamodbav")
+		if !ok {
+			continue
+		}
+
+		values := strings.Split(v, ",")
+		for _, ident := range f.Names {
+			if !ident.IsExported() {
+				continue
+			}
+
+			fields = append(fields, &structField{
+				name:    ident.Name,
+				pos:     f.Tag.Pos(),
+				typ:     l.pkg.TypesInfo.TypeOf(f.Type),
+				tag:     tag,
+				attr:    values[0],
+				options: values[1:],
+			})
+		}
+
+		if _, ok = tag.Lookup("tableName"); ok {
+			relevant = true
+		}
+		for _, option := range values[1:] {
+			for known, unique := range ddbfnsOptions {
+				if unique && strings.EqualFold(option, known) {
+					relevant = true
+				}
+			}
+		}
+	}
+
+	if !relevant {
+		return
+	}
+
+	seen := make(map[string]*structField)
+	for _, f := range fields {
+		l.lintField(f, seen)
+	}
+
+	if seen["hashkey"] == nil {
+		l.report(ruleMissingHashKey, st.Pos(), "struct has no hashkey field")
+	}
+
+	l.lintIndexes(st, fields, seen)
+}
+
+func (l *linter) lintField(f *structField, seen map[string]*structField) {
+	if f.attr == "-" {
+		return
+	}
+
+	if f.attr == "" && len(f.options) != 0 {
+		l.report(ruleIgnoredOptions, f.pos, `field "%s" has options %v but no attribute name so it is ignored by ddbfns`, f.name, f.options)
+		return
+	}
+
+	if f.attr != "" && isReservedWord(f.attr) {
+		l.report(ruleReservedWord, f.pos, `attribute name "%s" of field "%s" is a DynamoDB reserved word`, f.attr, f.name)
+	}
+
+	isKey := false
+	for _, option := range f.options {
+		if unique, ok := ddbfnsOptions[option]; ok {
+			if unique {
+				if other := seen[option]; other != nil {
+					l.report(ruleDuplicate, f.pos, `field "%s" is another %s field; "%s" is already one`, f.name, option, other.name)
+				} else {
+					seen[option] = f
+				}
+			}
+		} else if !encoderOptions[option] {
+			l.report(ruleUnknownOption, f.pos, `unknown option "%s" on field "%s"%s`, option, f.name, suggest(option))
+			continue
+		}
+
+		switch option {
+		case "hashkey":
+			isKey = true
+			if _, ok := f.tag.Lookup("tableName"); !ok {
+				l.report(ruleMissingTableName, f.pos, `hashkey field "%s" is missing the tableName tag`, f.name)
+			}
+			fallthrough
+		case "sortkey":
+			isKey = true
+			if !l.isKeyType(f.typ) {
+				l.report(ruleUnsupportedType, f.pos, `unsupported %s field type "%s"`, option, f.typ)
+			}
+		case "version":
+			if !isNumeric(f.typ) {
+				l.report(ruleUnsupportedType, f.pos, `unsupported version field type "%s"`, f.typ)
+			}
+		case "createdTime", "modifiedTime", "deletedTime":
+			if !l.isTime(f.typ) {
+				l.report(ruleUnsupportedType, f.pos, `unsupported %s field type "%s"`, option, f.typ)
+			}
+		case "ttl":
+			if !l.isTime(f.typ) && !isNumeric(f.typ) {
+				l.report(ruleUnsupportedType, f.pos, `unsupported ttl field type "%s"`, f.typ)
+			}
+		case "unixtime":
+			if !l.isTime(f.typ) {
+				l.report(ruleUnixTime, f.pos, `unixtime option on field "%s" of non-time type "%s"`, f.name, f.typ)
+			}
+		}
+	}
+
+	if isKey && slices.Contains(f.options, "omitempty") {
+		l.report(ruleOmitEmptyKey, f.pos, `omitempty option on key field "%s"`, f.name)
+	}
+
+	if _, ok := f.tag.Lookup("keyFormat"); ok && !isString(f.typ) {
+		l.report(ruleUnsupportedType, f.pos, `unsupported keyFormat field type "%s"`, f.typ)
+	}
+	if _, ok := f.tag.Lookup("unique"); ok && !l.isKeyType(f.typ) {
+		l.report(ruleUnsupportedType, f.pos, `unsupported unique field type "%s"`, f.typ)
+	}
+
+	l.lintValidationTags(f)
+}
+
+// lintIndexes mirrors internal.Model.parseIndexTags and internal.Model.validateIndexes.
+func (l *linter) lintIndexes(st *ast.StructType, fields []*structField, seen map[string]*structField) {
+	type index struct {
+		name             string
+		hashKey, sortKey *structField
+	}
+
+	var gsis, lsis []*index
+	find := func(indexes []*index, name string) *index {
+		for _, i := range indexes {
+			if i.name == name {
+				return i
+			}
+		}
+		return nil
+	}
+
+	for _, f := range fields {
+		if f.attr == "-" || f.attr == "" {
+			continue
+		}
+
+		if v, ok := f.tag.Lookup("gsi"); ok {
+			if !l.isKeyType(f.typ) {
+				l.report(ruleUnsupportedType, f.pos, `unsupported gsi field type "%s"`, f.typ)
+			}
+
+			for _, s := range strings.Fields(v) {
+				name, role, _ := strings.Cut(s, ",")
+				if name == "" || (role != "hashkey" && role != "sortkey") {
+					l.report(ruleInvalidTag, f.pos, `invalid gsi tag "%s" on field "%s"; must be either IndexName,hashkey or IndexName,sortkey`, s, f.name)
+					continue
+				}
+
+				i := find(gsis, name)
+				if i == nil {
+					i = &index{name: name}
+					gsis = append(gsis, i)
+				}
+
+				target := &i.hashKey
+				if role == "sortkey" {
+					target = &i.sortKey
+				}
+				if *target != nil {
+					l.report(ruleDuplicate, f.pos, `field "%s" is another %s field for gsi "%s"; "%s" is already one`, f.name, role, name, (*target).name)
+					continue
+				}
+				*target = f
+			}
+		}
+
+		if v, ok := f.tag.Lookup("lsi"); ok {
+			if !l.isKeyType(f.typ) {
+				l.report(ruleUnsupportedType, f.pos, `unsupported lsi field type "%s"`, f.typ)
+			}
+
+			for _, name := range strings.Fields(v) {
+				if i := find(lsis, name); i != nil {
+					l.report(ruleDuplicate, f.pos, `field "%s" is another sortkey field for lsi "%s"; "%s" is already one`, f.name, name, i.sortKey.name)
+					continue
+				}
+				lsis = append(lsis, &index{name: name, sortKey: f})
+			}
+		}
+	}
+
+	for _, i := range gsis {
+		if i.hashKey == nil {
+			l.report(ruleMissingIndexKey, i.sortKey.pos, `gsi "%s" has no hashkey field`, i.name)
+		}
+	}
+	for _, i := range lsis {
+		if seen["sortkey"] == nil {
+			l.report(ruleMissingIndexKey, i.sortKey.pos, `lsi "%s" requires a sortkey field in the struct`, i.name)
+		}
+	}
+}
+
+// lintValidationTags mirrors internal.parseValidationTags: tags on fields of types they don't apply to are ignored, but
+// tags with invalid values are errors.
+func (l *linter) lintValidationTags(f *structField) {
+	typ := f.typ
+	if p, ok := typ.Underlying().(*types.Pointer); ok {
+		typ = p.Elem()
+	}
+
+	if v, ok := f.tag.Lookup("required"); ok {
+		if _, err := strconv.ParseBool(v); err != nil {
+			l.report(ruleInvalidTag, f.pos, `invalid required tag "%s" on field "%s"`, v, f.name)
+		}
+	}
+
+	for _, key := range []string{"min", "max"} {
+		if v, ok := f.tag.Lookup(key); ok && isNumeric(typ) {
+			if _, err := strconv.ParseFloat(v, 64); err != nil {
+				l.report(ruleInvalidTag, f.pos, `invalid %s tag "%s" on field "%s"`, key, v, f.name)
+			}
+		}
+	}
+
+	if v, ok := f.tag.Lookup("maxLength"); ok && hasLength(typ) {
+		if _, err := strconv.Atoi(v); err != nil {
+			l.report(ruleInvalidTag, f.pos, `invalid maxLength tag "%s" on field "%s"`, v, f.name)
+		}
+	}
+
+	if v, ok := f.tag.Lookup("pattern"); ok && isString(typ) {
+		if _, err := regexp.Compile(v); err != nil {
+			l.report(ruleInvalidTag, f.pos, `invalid pattern tag "%s" on field "%s": %v`, v, f.name, err)
+		}
+	}
+}
+
+// suggest returns a hint if the option only differs from a known option by case, or is a rule that has its own tag.
+func suggest(option string) string {
+	if option == "required" {
+		return `; use the required:"true" struct tag instead`
+	}
+
+	for known := range ddbfnsOptions {
+		if strings.EqualFold(option, known) {
+			return fmt.Sprintf(`; did you mean "%s"?`, known)
+		}
+	}
+	for known := range encoderOptions {
+		if strings.EqualFold(option, known) {
+			return fmt.Sprintf(`; did you mean "%s"?`, known)
+		}
+	}
+
+	return ""
+}
+
+// isKeyType mirrors internal.validKeyAttribute.
+func (l *linter) isKeyType(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Info()&(types.IsString|types.IsInteger|types.IsFloat) != 0
+	case *types.Slice:
+		return isByte(u.Elem())
+	case *types.Array:
+		return isByte(u.Elem())
+	default:
+		return false
+	}
+}
+
+// isTime mirrors internal.validTimeAttribute.
+func (l *linter) isTime(t types.Type) bool {
+	return l.timeType != nil && types.ConvertibleTo(t, l.timeType)
+}
+
+func isNumeric(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&(types.IsInteger|types.IsFloat) != 0
+}
+
+// hasLength mirrors internal.hasLength.
+func hasLength(t types.Type) bool {
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Info()&types.IsString != 0
+	case *types.Slice, *types.Map, *types.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+func isString(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Info()&types.IsString != 0
+}
+
+func isByte(t types.Type) bool {
+	b, ok := t.Underlying().(*types.Basic)
+	return ok && b.Kind() == types.Uint8
+}
+
+// findTimeType returns time.Time if the package imports "time" directly or transitively.
+func findTimeType(pkg *types.Package, visited map[*types.Package]bool) types.Type {
+	if visited[pkg] {
+		return nil
+	}
+	visited[pkg] = true
+
+	if pkg.Path() == "time" {
+		if obj := pkg.Scope().Lookup("Time"); obj != nil {
+			return obj.Type()
+		}
+		return nil
+	}
+
+	for _, imp := range pkg.Imports() {
+		if t := findTimeType(imp, visited); t != nil {
+			return t
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLint(t *testing.T) {
+	diags, err := Lint(".", "./testdata/models")
+	if err != nil {
+		t.Errorf("Lint() error = %v", err)
+		return
+	}
+
+	var got []string
+	for _, d := range diags {
+		got = append(got, fmt.Sprintf("%s:%d: %s: %s", filepath.Base(d.Pos.Filename), d.Pos.Line, d.Rule.ID, d.Message))
+	}
+
+	assert.Equal(t, []string{
+		`models.go:14: missing-table-name: hashkey field "PK" is missing the tableName tag`,
+		`models.go:14: omitempty-key: omitempty option on key field "PK"`,
+		`models.go:15: duplicate-attribute: field "Other" is another hashkey field; "PK" is already one`,
+		`models.go:16: unsupported-type: unsupported version field type "string"`,
+		`models.go:17: unsupported-type: unsupported modifiedTime field type "int64"`,
+		`models.go:18: unixtime-non-time: unixtime option on field "Expires" of non-time type "string"`,
+		`models.go:19: unknown-option: unknown option "sortKey" on field "Typo"; did you mean "sortkey"?`,
+		`models.go:20: reserved-word: attribute name "status" of field "Status" is a DynamoDB reserved word`,
+		`models.go:21: ignored-options: field "Ignored" has options [immutable] but no attribute name so it is ignored by ddbfns`,
+		`models.go:22: reserved-word: attribute name "map" of field "Map" is a DynamoDB reserved word`,
+		`models.go:25: missing-hashkey: struct has no hashkey field`,
+		`models.go:36: missing-index-key: gsi "ByOwner" has no hashkey field`,
+		`models.go:37: duplicate-attribute: field "Other" is another hashkey field for gsi "ByDate"; "Owner" is already one`,
+		`models.go:37: invalid-tag: invalid gsi tag "ByTypo,partition" on field "Other"; must be either IndexName,hashkey or IndexName,sortkey`,
+		`models.go:38: unsupported-type: unsupported gsi field type "time.Time"`,
+		`models.go:39: missing-index-key: lsi "BySorted" requires a sortkey field in the struct`,
+		`models.go:44: unknown-option: unknown option "required" on field "Email"; use the required:"true" struct tag instead`,
+		"models.go:44: invalid-tag: invalid pattern tag \"[a-z\" on field \"Email\": error parsing regexp: missing closing ]: `[a-z`",
+		`models.go:45: invalid-tag: invalid required tag "yes" on field "Name"`,
+		`models.go:45: invalid-tag: invalid maxLength tag "ten" on field "Name"`,
+		`models.go:46: invalid-tag: invalid min tag "one" on field "Count"`,
+	}, got)
+}
+
+func TestWriteSARIF(t *testing.T) {
+	diags, err := Lint(".", "./testdata/models")
+	if err != nil {
+		t.Errorf("Lint() error = %v", err)
+		return
+	}
+
+	abs, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = WriteSARIF(&buf, abs, diags); err != nil {
+		t.Errorf("WriteSARIF() error = %v", err)
+		return
+	}
+
+	var log sarifLog
+	if err = json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Errorf("Unmarshal() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, "2.1.0", log.Version)
+	assert.Len(t, log.Runs[0].Tool.Driver.Rules, len(Rules))
+	assert.Len(t, log.Runs[0].Results, len(diags))
+
+	result := log.Runs[0].Results[0]
+	assert.Equal(t, "missing-table-name", result.RuleID)
+	assert.Equal(t, "error", result.Level)
+	assert.Equal(t, "testdata/models/models.go", result.Locations[0].PhysicalLocation.ArtifactLocation.URI)
+	assert.Equal(t, 14, result.Locations[0].PhysicalLocation.Region.StartLine)
+}
@@ -0,0 +1,80 @@
+// Command ddbfns provides tooling for structs that use the ddbfns struct tags.
+//
+// Usage:
+//
+//	ddbfns lint [-format text|sarif] [packages]
+//
+// The lint subcommand finds every struct whose `dynamodbav` tags use ddbfns options such as hashkey or version and
+// applies the same rules as the runtime parsing (duplicate keys, unsupported types, missing tableName, gsi and lsi tags,
+// and invalid validation tags), as well as rules that the runtime does not check such as unknown options, unixtime on
+// non-time fields, omitempty on keys, and attribute names that are DynamoDB reserved words. The exit code is 1 if there
+// are any findings.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("ddbfns: ")
+
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	switch os.Args[1] {
+	case "lint":
+		os.Exit(lint(os.Args[2:]))
+	default:
+		usage()
+	}
+}
+
+func usage() {
+	_, _ = fmt.Fprintf(os.Stderr, "Usage: ddbfns lint [-format text|sarif] [packages]\n")
+	os.Exit(2)
+}
+
+func lint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := fs.String("format", "text", "output format; either text or sarif")
+	_ = fs.Parse(args)
+
+	patterns := fs.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	diags, err := Lint(wd, patterns...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	switch *format {
+	case "text":
+		for _, d := range diags {
+			_, _ = fmt.Fprintln(os.Stderr, d)
+		}
+	case "sarif":
+		if err = WriteSARIF(os.Stdout, wd, diags); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		log.Fatalf(`unknown format "%s"`, *format)
+	}
+
+	if len(diags) != 0 {
+		return 1
+	}
+
+	return 0
+}
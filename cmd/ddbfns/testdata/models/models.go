@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+type Good struct {
+	PK        string    `dynamodbav:"pk,hashkey" tableName:"good"`
+	SK        int64     `dynamodbav:"sk,sortkey"`
+	Version   int64     `dynamodbav:"version,version"`
+	CreatedAt time.Time `dynamodbav:"createdAt,createdTime,unixtime"`
+	Notes     []string  `dynamodbav:"notes,stringset,omitempty"`
+}
+
+type Bad struct {
+	PK       string  `dynamodbav:"pk,hashkey,omitempty"`
+	Other    string  `dynamodbav:"alt,hashkey" tableName:"bad"`
+	Version  string  `dynamodbav:"version,version"`
+	Modified int64   `dynamodbav:"modified,modifiedTime"`
+	Expires  string  `dynamodbav:"expires,unixtime"`
+	Typo     string  `dynamodbav:"typo,sortKey"`
+	Status   string  `dynamodbav:"status"`
+	Ignored  string  `dynamodbav:",immutable"`
+	Map      float64 `dynamodbav:"map"`
+}
+
+type NoHashKey struct {
+	Version int64 `dynamodbav:"version,version"`
+}
+
+// Unrelated structs are not linted.
+type Unrelated struct {
+	Status string `dynamodbav:"status,omitempty"`
+}
+
+type Indexes struct {
+	PK     string    `dynamodbav:"pk,hashkey" tableName:"indexes"`
+	Owner  string    `dynamodbav:"ownerId" gsi:"ByOwner,sortkey ByDate,hashkey"`
+	Other  string    `dynamodbav:"otherId" gsi:"ByDate,hashkey ByTypo,partition"`
+	At     time.Time `dynamodbav:"atTime" gsi:"ByTime,hashkey"`
+	Sorted string    `dynamodbav:"sorted" lsi:"BySorted"`
+}
+
+type Validated struct {
+	PK      string    `dynamodbav:"pk,hashkey" tableName:"validated"`
+	Email   string    `dynamodbav:"email,required" pattern:"[a-z"`
+	Name    *string   `dynamodbav:"fullName" required:"yes" maxLength:"ten"`
+	Count   int       `dynamodbav:"tally" min:"one" max:"10"`
+	Created time.Time `dynamodbav:"created" min:"one" maxLength:"ten" pattern:"[a-z"`
+}
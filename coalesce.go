@@ -0,0 +1,138 @@
+package ddbfns
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// getGroup deduplicates in-flight GetItem requests.
+type getGroup struct {
+	mu    sync.Mutex
+	calls map[string]*getCall
+}
+
+// getCall is an in-flight GetItem request shared by one or more callers.
+type getCall struct {
+	done    chan struct{}
+	output  *dynamodb.GetItemOutput
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// coalesceKey identifies the GetItem requests that return the same result.
+//
+// Requests made with different clients are never merged since the clients may be configured with different
+// credentials, regions, or endpoints.
+func coalesceKey(client *dynamodb.Client, input *dynamodb.GetItemInput) string {
+	b := &strings.Builder{}
+	_, _ = fmt.Fprintf(b, "%p", client)
+	b.WriteByte(0)
+	b.WriteString(aws.ToString(input.TableName))
+	b.WriteByte(0)
+	b.WriteString(explainItem(input.Key))
+	b.WriteByte(0)
+	b.WriteString(strconv.FormatBool(aws.ToBool(input.ConsistentRead)))
+	b.WriteByte(0)
+	b.WriteString(aws.ToString(input.ProjectionExpression))
+
+	names := make([]string, 0, len(input.ExpressionAttributeNames))
+	for placeholder, name := range input.ExpressionAttributeNames {
+		names = append(names, placeholder+"="+name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		b.WriteByte(0)
+		b.WriteString(name)
+	}
+
+	return b.String()
+}
+
+// do executes fn once for all concurrent callers with the same key, returning a copy of the output to each caller.
+//
+// fn is given a context that carries the values of the first caller's context but is only cancelled once every caller
+// has given up, so that one caller's cancellation does not fail the others. A caller whose context is done returns
+// immediately with the context's error.
+//
+// The CapacityTracker of the first caller's context is hidden from fn. Instead, the budget of each caller's tracker is
+// checked before the caller joins, and the consumed capacity of the shared request is added to the tracker of every
+// caller that receives the output. fn must therefore request the consumed capacity to be returned.
+func (g *getGroup) do(ctx context.Context, key string, fn func(context.Context) (*dynamodb.GetItemOutput, error)) (*dynamodb.GetItemOutput, error) {
+	tracker, tracked := CapacityTrackerFromContext(ctx)
+	if tracked {
+		if err := tracker.checkBudget(); err != nil {
+			return nil, err
+		}
+	}
+
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*getCall)
+	}
+
+	c, ok := g.calls[key]
+	if ok {
+		c.waiters++
+	} else {
+		callCtx, cancel := context.WithCancel(withoutCapacityTracker(context.WithoutCancel(ctx)))
+		c = &getCall{done: make(chan struct{}), waiters: 1, cancel: cancel}
+		g.calls[key] = c
+
+		go func() {
+			defer cancel()
+
+			output, err := fn(callCtx)
+
+			g.mu.Lock()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+			g.mu.Unlock()
+
+			c.output, c.err = output, err
+			close(c.done)
+		}()
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		if tracked {
+			tracker.add("GetItem", ConsumedCapacity(c.output))
+		}
+
+		return copyGetItemOutput(c.output), c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			// nobody is waiting for the result anymore; subsequent callers will start a new request.
+			c.cancel()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+
+		return nil, ctx.Err()
+	}
+}
+
+// copyGetItemOutput returns a copy of the output whose item doesn't share any state with the original.
+func copyGetItemOutput(output *dynamodb.GetItemOutput) *dynamodb.GetItemOutput {
+	if output == nil {
+		return nil
+	}
+
+	copied := *output
+	copied.Item = copyItem(output.Item)
+	return &copied
+}
@@ -0,0 +1,262 @@
+package ddbfns
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// blockingHTTPClient responds to every request with the same item and consumed capacity once released.
+type blockingHTTPClient struct {
+	calls     atomic.Int32
+	cancelled atomic.Int32
+	release   chan struct{}
+}
+
+func (c *blockingHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	c.calls.Add(1)
+
+	select {
+	case <-c.release:
+	case <-req.Context().Done():
+		c.cancelled.Add(1)
+		return nil, req.Context().Err()
+	}
+
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(bytes.NewBufferString(`{"Item":{"id":{"S":"hello"},"sort":{"S":"world"},"notes":{"S":"hi"}},"ConsumedCapacity":{"TableName":"my-table","CapacityUnits":0.5}}`)),
+		Request:    req,
+	}, nil
+}
+
+func newBlockingClient() (*blockingHTTPClient, *dynamodb.Client) {
+	c := &blockingHTTPClient{release: make(chan struct{})}
+	return c, dynamodb.New(dynamodb.Options{
+		Region:       "us-west-2",
+		BaseEndpoint: aws.String("http://localhost:8000"),
+		Credentials:  aws.AnonymousCredentials{},
+		HTTPClient:   c,
+		Retryer:      aws.NopRetryer{},
+	})
+}
+
+// waitForWaiters waits until the in-flight request has the given number of callers.
+func waitForWaiters(t *testing.T, f *Fns, waiters int) {
+	assert.Eventually(t, func() bool {
+		f.gets.mu.Lock()
+		defer f.gets.mu.Unlock()
+
+		n := 0
+		for _, c := range f.gets.calls {
+			n += c.waiters
+		}
+		return n == waiters
+	}, time.Second, time.Millisecond)
+}
+
+func TestFns_CoalesceGets(t *testing.T) {
+	httpClient, client := newBlockingClient()
+	f := &Fns{CoalesceGets: true}
+	key := middlewareTest{Id: "hello", Sort: "world"}
+
+	const n = 10
+	outputs := make([]*dynamodb.GetItemOutput, n)
+	got := make([]middlewareTest, n)
+	wg := sync.WaitGroup{}
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			var err error
+			outputs[i], err = f.DoGet(context.Background(), client, key, func(opts *GetOpts) {
+				opts.Decode(&got[i])
+			})
+			assert.NoError(t, err)
+		}(i)
+	}
+
+	waitForWaiters(t, f, n)
+	close(httpClient.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), httpClient.calls.Load())
+	for i := 0; i < n; i++ {
+		assert.Equal(t, middlewareTest{Id: "hello", Sort: "world", Notes: "hi"}, got[i])
+	}
+
+	// each caller has its own copy of the item.
+	outputs[0].Item["notes"].(*types.AttributeValueMemberS).Value = "modified"
+	for i := 1; i < n; i++ {
+		assert.Equal(t, &types.AttributeValueMemberS{Value: "hi"}, outputs[i].Item["notes"])
+	}
+}
+
+func TestFns_CoalesceGets_differentRequests(t *testing.T) {
+	httpClient, client := newBlockingClient()
+	f := &Fns{CoalesceGets: true}
+	key := middlewareTest{Id: "hello", Sort: "world"}
+
+	wg := sync.WaitGroup{}
+	for _, optFn := range []func(*GetOpts){
+		func(opts *GetOpts) {},
+		func(opts *GetOpts) { opts.ConsistentRead = aws.Bool(true) },
+		func(opts *GetOpts) { opts.WithProjectionExpression("notes") },
+		func(opts *GetOpts) { opts.WithTableName("other-table") },
+	} {
+		wg.Add(1)
+		go func(optFn func(*GetOpts)) {
+			defer wg.Done()
+
+			_, err := f.DoGet(context.Background(), client, key, optFn)
+			assert.NoError(t, err)
+		}(optFn)
+	}
+
+	waitForWaiters(t, f, 4)
+	close(httpClient.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(4), httpClient.calls.Load())
+}
+
+func TestFns_CoalesceGets_firstCallerCancels(t *testing.T) {
+	httpClient, client := newBlockingClient()
+	f := &Fns{CoalesceGets: true}
+	key := middlewareTest{Id: "hello", Sort: "world"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error)
+	go func() {
+		_, err := f.DoGet(ctx, client, key)
+		firstErr <- err
+	}()
+	waitForWaiters(t, f, 1)
+
+	secondOutput := make(chan *dynamodb.GetItemOutput)
+	go func() {
+		output, err := f.DoGet(context.Background(), client, key)
+		assert.NoError(t, err)
+		secondOutput <- output
+	}()
+	waitForWaiters(t, f, 2)
+
+	// the first caller returns right away while the request continues for the second caller.
+	cancel()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+
+	close(httpClient.release)
+	output := <-secondOutput
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "hi"}, output.Item["notes"])
+	assert.Equal(t, int32(1), httpClient.calls.Load())
+	assert.Equal(t, int32(0), httpClient.cancelled.Load())
+}
+
+func TestFns_CoalesceGets_allCallersCancel(t *testing.T) {
+	httpClient, client := newBlockingClient()
+	f := &Fns{CoalesceGets: true}
+	key := middlewareTest{Id: "hello", Sort: "world"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errs := make(chan error, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			_, err := f.DoGet(ctx, client, key)
+			errs <- err
+		}()
+	}
+	waitForWaiters(t, f, 2)
+
+	cancel()
+	assert.ErrorIs(t, <-errs, context.Canceled)
+	assert.ErrorIs(t, <-errs, context.Canceled)
+
+	// the shared request is cancelled once nobody is waiting for it.
+	assert.Eventually(t, func() bool {
+		return httpClient.cancelled.Load() == 1
+	}, time.Second, time.Millisecond)
+
+	// subsequent callers start a new request.
+	close(httpClient.release)
+	_, err := f.DoGet(context.Background(), client, key)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), httpClient.calls.Load())
+}
+
+func TestFns_CoalesceGets_differentClients(t *testing.T) {
+	httpClient1, client1 := newBlockingClient()
+	httpClient2, client2 := newBlockingClient()
+	f := &Fns{CoalesceGets: true}
+	key := middlewareTest{Id: "hello", Sort: "world"}
+
+	wg := sync.WaitGroup{}
+	for _, client := range []*dynamodb.Client{client1, client2} {
+		wg.Add(1)
+		go func(client *dynamodb.Client) {
+			defer wg.Done()
+
+			_, err := f.DoGet(context.Background(), client, key)
+			assert.NoError(t, err)
+		}(client)
+	}
+
+	waitForWaiters(t, f, 2)
+	close(httpClient1.release)
+	close(httpClient2.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), httpClient1.calls.Load())
+	assert.Equal(t, int32(1), httpClient2.calls.Load())
+}
+
+func TestFns_CoalesceGets_capacityTrackers(t *testing.T) {
+	httpClient, client := newBlockingClient()
+	f := &Fns{CoalesceGets: true}
+	key := middlewareTest{Id: "hello", Sort: "world"}
+
+	// every caller that receives the shared output is charged its consumed capacity.
+	ctxs := []context.Context{WithCapacityTracker(context.Background()), WithCapacityTracker(context.Background())}
+	wg := sync.WaitGroup{}
+	for _, ctx := range ctxs {
+		wg.Add(1)
+		go func(ctx context.Context) {
+			defer wg.Done()
+
+			_, err := f.DoGet(ctx, client, key)
+			assert.NoError(t, err)
+		}(ctx)
+	}
+
+	waitForWaiters(t, f, 2)
+
+	// a caller that is over its budget doesn't join.
+	overBudget := WithCapacityTracker(context.Background(), func(opts *CapacityTrackerOpts) {
+		opts.MaxReadCapacityUnits = 0.1
+	})
+	tracker, _ := CapacityTrackerFromContext(overBudget)
+	tracker.add("GetItem", []types.ConsumedCapacity{{TableName: aws.String("my-table"), CapacityUnits: aws.Float64(1)}})
+	_, err := f.DoGet(overBudget, client, key)
+	assert.ErrorIs(t, err, ErrCapacityBudgetExceeded)
+
+	close(httpClient.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), httpClient.calls.Load())
+	for _, ctx := range ctxs {
+		tracker, _ := CapacityTrackerFromContext(ctx)
+		assert.Equal(t, Capacity{ReadCapacityUnits: 0.5}, tracker.Total())
+	}
+	assert.Equal(t, Capacity{ReadCapacityUnits: 1}, tracker.Total())
+}
@@ -0,0 +1,186 @@
+package ddbfns
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// LoadCollectionOpts customises [Fns.LoadCollection] operations per each invocation.
+type LoadCollectionOpts struct {
+	RequestOptions
+
+	// ConsistentRead modifies the [dynamodb.QueryInput.ConsistentRead].
+	ConsistentRead *bool
+	// IncludeDeleted, if true, will also load items that have been tombstoned by [Fns.SoftDelete].
+	IncludeDeleted bool
+}
+
+// WithTableName overrides [LoadCollectionOpts.TableName].
+func (o *LoadCollectionOpts) WithTableName(tableName string) *LoadCollectionOpts {
+	o.TableName = &tableName
+	return o
+}
+
+// LoadCollection loads the item collection sharing the parent's hash key into the given aggregate struct pointer.
+//
+// A single Query on the parent's hash key is issued and paginated until done. Each returned item is routed to a field
+// of the aggregate by the `skPrefix` struct tag that matches the start of the item's sort key; the longest matching
+// prefix wins, and items that match no prefix are ignored. A slice field collects all matching items while a struct or
+// struct pointer field receives the last matching item. History items written in history mode (see [HistoryOpts]) are
+// always skipped, and items that have been tombstoned by [Fns.SoftDelete] are skipped unless
+// [LoadCollectionOpts.IncludeDeleted] is true:
+//
+//	type OrderAggregate struct {
+//		Order     Order      `skPrefix:"META"`
+//		LineItems []LineItem `skPrefix:"ITEM#"`
+//		Shipments []Shipment `skPrefix:"SHIP#"`
+//	}
+//
+//	var agg OrderAggregate
+//	err := fns.LoadCollection(ctx, client, Order{OrderID: "123"}, &agg)
+//
+// The parentKey must be a struct with both hashkey and sortkey fields; only its hash key is used. Like the Decode paths
+// of DoXyz methods, composite keys are parsed and [AfterDecodeHook] is called for each decoded item.
+func (f *Fns) LoadCollection(ctx context.Context, client dynamodb.QueryAPIClient, parentKey interface{}, out interface{}, optFns ...func(*LoadCollectionOpts)) error {
+	f.init.Do(f.initFn)
+
+	opts := applyOpts(optFns)
+
+	attrs, err := f.loadOrParse(reflect.TypeOf(parentKey))
+	if err != nil {
+		return err
+	}
+	if attrs.HashKey == nil || attrs.SortKey == nil {
+		return fmt.Errorf(`type "%s" must have both hashkey and sortkey fields`, attrs.StructType.Name())
+	}
+
+	if opts.TableName == nil {
+		opts.TableName = attrs.TableName
+	}
+
+	ov := reflect.ValueOf(out)
+	if ov.Kind() != reflect.Pointer || ov.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("out must be a pointer to struct, got %T", out)
+	}
+	ov = ov.Elem()
+
+	type target struct {
+		prefix   string
+		field    reflect.Value
+		elemType reflect.Type
+	}
+	var targets []target
+	for i, n := 0, ov.NumField(); i < n; i++ {
+		structField := ov.Type().Field(i)
+		if prefix, ok := structField.Tag.Lookup("skPrefix"); ok && structField.IsExported() {
+			elemType := structField.Type
+			if elemType.Kind() == reflect.Slice {
+				elemType = elemType.Elem()
+			}
+
+			targets = append(targets, target{prefix: prefix, field: ov.Field(i), elemType: internal.DereferencedType(elemType)})
+		}
+	}
+
+	key, err := f.encodeKey(attrs, parentKey)
+	if err != nil {
+		return err
+	}
+
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(expression.Key(attrs.HashKey.Name).Equal(expression.Value(key[attrs.HashKey.Name]))).
+		Build()
+	if err != nil {
+		return fmt.Errorf("build expressions error: %w", err)
+	}
+
+	paginator := dynamodb.NewQueryPaginator(f.queryClient(client), f.rewriteQuery(&dynamodb.QueryInput{
+		TableName:                 opts.TableName,
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConsistentRead:            opts.ConsistentRead,
+		ReturnConsumedCapacity:    opts.ReturnConsumedCapacity,
+	}))
+	for paginator.HasMorePages() {
+		queryOutput, err := paginator.NextPage(ctx, opts.ClientOptions...)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range queryOutput.Items {
+			sk, ok := item[attrs.SortKey.Name].(*types.AttributeValueMemberS)
+			if !ok || isHistoryItem(item) {
+				continue
+			}
+
+			var match *target
+			for i, t := range targets {
+				if strings.HasPrefix(sk.Value, t.prefix) && (match == nil || len(t.prefix) > len(match.prefix)) {
+					match = &targets[i]
+				}
+			}
+			if match == nil {
+				continue
+			}
+
+			if !opts.IncludeDeleted {
+				if m, err := f.loadOrParse(match.elemType); err == nil && isDeleted(m, item) {
+					continue
+				}
+			}
+
+			if err = f.decodeInto(item, match.field); err != nil {
+				return fmt.Errorf(`decode item with sort key "%s" error: %w`, sk.Value, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeInto decodes the item into the given field, appending to the field if it's a slice.
+func (f *Fns) decodeInto(item map[string]types.AttributeValue, field reflect.Value) error {
+	switch field.Kind() {
+	case reflect.Slice:
+		elemType := field.Type().Elem()
+		ptr := reflect.New(internal.DereferencedType(elemType))
+		if err := f.decode(item, ptr.Interface()); err != nil {
+			return err
+		}
+
+		if elemType.Kind() == reflect.Pointer {
+			field.Set(reflect.Append(field, ptr))
+		} else {
+			field.Set(reflect.Append(field, ptr.Elem()))
+		}
+	case reflect.Pointer:
+		ptr := reflect.New(field.Type().Elem())
+		if err := f.decode(item, ptr.Interface()); err != nil {
+			return err
+		}
+
+		field.Set(ptr)
+	default:
+		ptr := reflect.New(field.Type())
+		if err := f.decode(item, ptr.Interface()); err != nil {
+			return err
+		}
+
+		field.Set(ptr.Elem())
+	}
+
+	return nil
+}
+
+// LoadCollection is a wrapper around [DefaultFns.LoadCollection]; see [Fns.LoadCollection] for more information.
+func LoadCollection(ctx context.Context, client dynamodb.QueryAPIClient, parentKey interface{}, out interface{}, optFns ...func(*LoadCollectionOpts)) error {
+	return DefaultFns.LoadCollection(ctx, client, parentKey, out, optFns...)
+}
@@ -0,0 +1,57 @@
+package ddbfns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFns_LoadCollection(t *testing.T) {
+	type Aggregate struct {
+		Order     *orderTest     `skPrefix:"META"`
+		LineItems []lineItemTest `skPrefix:"ITEM#"`
+		Ignored   []lineItemTest
+	}
+
+	item := func(sk string, qty string) map[string]types.AttributeValue {
+		return map[string]types.AttributeValue{
+			"pk":  &types.AttributeValueMemberS{Value: "ORDER#1"},
+			"sk":  &types.AttributeValueMemberS{Value: sk},
+			"qty": &types.AttributeValueMemberN{Value: qty},
+		}
+	}
+
+	client := &fakeQueryClient{pages: []*dynamodb.QueryOutput{
+		{
+			Items:            []map[string]types.AttributeValue{item("ITEM#1", "1"), item("META", "0")},
+			LastEvaluatedKey: item("META", "0"),
+		},
+		{
+			Items: []map[string]types.AttributeValue{item("ITEM#2", "2"), item("SHIP#1", "0")},
+		},
+	}}
+
+	var got Aggregate
+	err := LoadCollection(context.Background(), client, orderTest{PK: "ORDER#1"}, &got)
+	if err != nil {
+		t.Errorf("LoadCollection() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, Aggregate{
+		Order: &orderTest{PK: "ORDER#1", SK: "META"},
+		LineItems: []lineItemTest{
+			{PK: "ORDER#1", SK: "ITEM#1", Qty: 1},
+			{PK: "ORDER#1", SK: "ITEM#2", Qty: 2},
+		},
+	}, got)
+
+	assert.Equal(t, 2, len(client.inputs))
+	assert.Equal(t, "orders", *client.inputs[0].TableName)
+	assert.Equal(t, "#0 = :0", *client.inputs[0].KeyConditionExpression)
+	assert.Equal(t, map[string]string{"#0": "pk"}, client.inputs[0].ExpressionAttributeNames)
+	assert.Equal(t, map[string]types.AttributeValue{":0": &types.AttributeValueMemberS{Value: "ORDER#1"}}, client.inputs[0].ExpressionAttributeValues)
+}
@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"strconv"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
@@ -14,66 +13,99 @@ import (
 // Delete creates the DeleteItem request for the given item.
 //
 // The current item's version is used in the `#version = :version` condition expression to perform optimistic locking.
+//
+// If the struct implements [BeforeDeleteHook], the hook is called on a copy of the struct before the request is built.
+//
+// Delete returns an error if [DeleteOpts.Soft] is true; use [Fns.SoftDelete] to create the request instead. It also
+// returns an error if the struct has attributes tagged with `unique` since a single DeleteItem request cannot delete
+// their sentinel items; use [Fns.TransactDelete] instead.
 func (f *Fns) Delete(v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.DeleteItemInput, error) {
+	if f.hasUniques(v) {
+		attrs, _ := f.loadOrParse(reflect.TypeOf(v))
+		return nil, errUniques(attrs, "TransactDelete")
+	}
+
+	return f.delete(context.Background(), v, applyOpts(optFns))
+}
+
+func (f *Fns) delete(ctx context.Context, v interface{}, opts *DeleteOpts) (*dynamodb.DeleteItemInput, error) {
 	f.init.Do(f.initFn)
 
-	opts := &DeleteOpts{}
-	for _, fn := range optFns {
-		fn(opts)
+	if opts.Soft {
+		return nil, fmt.Errorf("soft delete must use SoftDelete instead of Delete")
 	}
 
-	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+	attrs, p, err := f.loadPlan(reflect.TypeOf(v))
 	if err != nil {
 		return nil, err
 	}
 
+	if cp, hook, ok := shallowCopy[BeforeDeleteHook](v); ok {
+		if err = hook.BeforeDelete(ctx, opts); err != nil {
+			return nil, err
+		}
+		v = cp
+	}
+
 	if opts.TableName == nil {
 		opts.TableName = attrs.TableName
 	}
 
 	// DeleteItem only needs the key.
-	var key map[string]types.AttributeValue
-	if av, err := f.Encoder.Encode(v); err != nil {
+	key, err := f.encodeKey(attrs, v)
+	if err != nil {
 		return nil, err
-	} else if asMap, ok := av.(*types.AttributeValueMemberM); !ok {
-		return nil, fmt.Errorf("item did not encode to M type")
-	} else {
-		item := asMap.Value
-		key = map[string]types.AttributeValue{attrs.HashKey.Name: item[attrs.HashKey.Name]}
-		if attrs.SortKey != nil {
-			key[attrs.SortKey.Name] = item[attrs.SortKey.Name]
-		}
 	}
 
 	iv := reflect.Indirect(reflect.ValueOf(v))
 
+	// if the only condition is the standard optimistic locking one, its pre-rendered form is used.
+	fast := newFastCondition(opts.condition)
+
 	if versionAttr := attrs.Version; !opts.DisableOptimisticLocking && versionAttr != nil {
-		version, err := versionAttr.Get(iv)
+		current, _, zero, err := p.version.get(iv)
 		if err != nil {
-			return nil, fmt.Errorf("get version value error: %w", err)
+			return nil, err
 		}
 
-		switch {
-		case version.IsZero():
+		if zero {
 			opts.And(expression.Name(attrs.HashKey.Name).AttributeNotExists())
-		case version.CanInt():
-			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(&types.AttributeValueMemberN{Value: strconv.FormatInt(version.Int(), 10)})))
-		case version.CanUint():
-			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(&types.AttributeValueMemberN{Value: strconv.FormatUint(version.Uint(), 10)})))
-		case version.CanFloat():
-			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(&types.AttributeValueMemberN{Value: strconv.FormatFloat(version.Float(), 'f', -1, 64)})))
-		default:
-			panic(fmt.Errorf("version attribute's type (%s) is unknown numeric type", version.Type()))
+			fast.use(&p.notExists, nil)
+		} else {
+			av := &types.AttributeValueMemberN{Value: current}
+			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(av)))
+			fast.use(&p.versionEquals, av)
+		}
+	}
+
+	if opts.beforeBuild != nil {
+		fast.disable()
+		if err = opts.beforeBuild(v); err != nil {
+			return nil, err
 		}
 	}
 
+	if rc, values, ok := fast.get(); ok {
+		return f.rewriteDelete(&dynamodb.DeleteItemInput{
+			Key:                                 key,
+			TableName:                           opts.TableName,
+			ConditionExpression:                 &rc.expression,
+			ExpressionAttributeNames:            rc.copyNames(),
+			ExpressionAttributeValues:           values,
+			ReturnConsumedCapacity:              opts.ReturnConsumedCapacity,
+			ReturnItemCollectionMetrics:         opts.ReturnItemCollectionMetrics,
+			ReturnValues:                        opts.ReturnValues,
+			ReturnValuesOnConditionCheckFailure: opts.ReturnValuesOnConditionCheckFailure,
+		}), nil
+	}
+
 	if opts.condition.IsSet() {
 		expr, err := expression.NewBuilder().WithCondition(opts.condition).Build()
 		if err != nil {
 			return nil, fmt.Errorf("build expressions error: %w", err)
 		}
 
-		return &dynamodb.DeleteItemInput{
+		return f.rewriteDelete(&dynamodb.DeleteItemInput{
 			Key:                                 key,
 			TableName:                           opts.TableName,
 			ConditionExpression:                 expr.Condition(),
@@ -83,7 +115,7 @@ func (f *Fns) Delete(v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.Dele
 			ReturnItemCollectionMetrics:         opts.ReturnItemCollectionMetrics,
 			ReturnValues:                        opts.ReturnValues,
 			ReturnValuesOnConditionCheckFailure: opts.ReturnValuesOnConditionCheckFailure,
-		}, nil
+		}), nil
 	}
 
 	return &dynamodb.DeleteItemInput{
@@ -97,24 +129,58 @@ func (f *Fns) Delete(v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.Dele
 }
 
 // DoDelete performs a [Fns.DoDelete] and then executes the request with the specified DynamoDB client.
+//
+// If [DeleteOpts.Soft] is true, [Fns.DoSoftDelete] is performed instead to tombstone the item, and its
+// UpdateItemOutput is returned as a DeleteItemOutput. Otherwise, if [Fns.HistoryOpts] is given, or if the struct has
+// attributes tagged with `unique`, [Fns.DoTransactDelete] is performed instead to also write the history item or delete
+// the uniqueness sentinel items. ReturnValues and Decode are not supported by TransactWriteItems so an error is returned
+// if either is given.
 func (f *Fns) DoDelete(ctx context.Context, client *dynamodb.Client, v interface{}, optFns ...func(ops *DeleteOpts)) (*dynamodb.DeleteItemOutput, error) {
-	var opts *DeleteOpts
-	optFns = append(optFns, func(o *DeleteOpts) {
-		opts = o
-	})
+	opts := applyOpts(optFns)
+
+	if opts.Soft {
+		opts.Soft = false
+		updateItemOutput, err := f.doSoftDelete(ctx, client, v, opts)
+		if updateItemOutput == nil {
+			return nil, err
+		}
+
+		return &dynamodb.DeleteItemOutput{
+			Attributes:            updateItemOutput.Attributes,
+			ConsumedCapacity:      updateItemOutput.ConsumedCapacity,
+			ItemCollectionMetrics: updateItemOutput.ItemCollectionMetrics,
+			ResultMetadata:        updateItemOutput.ResultMetadata,
+		}, err
+	}
+
+	if f.HistoryOpts != nil || f.hasUniques(v) {
+		if err := checkTransactReturnValues(&opts.RequestOptions); err != nil {
+			return nil, err
+		}
+
+		output, err := f.doTransactDelete(ctx, client, v, opts)
+		if output == nil {
+			return nil, err
+		}
+
+		return &dynamodb.DeleteItemOutput{
+			ConsumedCapacity: firstConsumedCapacity(output.ConsumedCapacity),
+			ResultMetadata:   output.ResultMetadata,
+		}, err
+	}
 
-	input, err := f.Delete(v, optFns...)
+	input, err := f.delete(ctx, v, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	deleteItemOutput, err := client.DeleteItem(ctx, input)
+	deleteItemOutput, err := invoke(ctx, f, newRequest(input), client.DeleteItem, opts.ClientOptions)
 	if err != nil || opts.out == nil {
 		return deleteItemOutput, err
 	}
 
 	if item := deleteItemOutput.Attributes; len(item) != 0 {
-		err = f.Decoder.Decode(&types.AttributeValueMemberM{Value: item}, opts.out)
+		err = f.decode(item, opts.out)
 	}
 
 	return deleteItemOutput, err
@@ -123,8 +189,8 @@ func (f *Fns) DoDelete(ctx context.Context, client *dynamodb.Client, v interface
 // Delete creates the DeleteItem request for the given item.
 //
 // Delete is a wrapper around [DefaultFns.Delete]; see [Fns.Delete] for more information.
-func Delete(v interface{}) (*dynamodb.DeleteItemInput, error) {
-	return DefaultFns.Delete(v)
+func Delete(v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.DeleteItemInput, error) {
+	return DefaultFns.Delete(v, optFns...)
 }
 
 // DoDelete is a wrapper around [DefaultFns.DoDelete]; see [Fns.DoDelete] for more information.
@@ -1,30 +1,30 @@
 package ddbfns
 
 import (
+	"time"
+
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 // DeleteOpts customises [Fns.Delete] operations per each invocation.
 type DeleteOpts struct {
+	RequestOptions
+
 	// DisableOptimisticLocking, if true, will skip all logic concerning version attribute.
 	DisableOptimisticLocking bool
 	// DisableAutoGeneratedTimestamps, if true, will skip all logic concerning timestamp attributes.
+	//
+	// Only used in soft delete mode since a hard delete does not write any timestamp.
 	DisableAutoGeneratedTimestamps bool
+	// Soft, if true, will make DoDelete tombstone the item instead of removing it; see [Fns.SoftDelete].
+	Soft bool
+	// TTL, if positive, will also make soft delete set the `ttl` attribute to [time.Now] plus TTL so that DynamoDB
+	// eventually removes the tombstoned item.
+	TTL time.Duration
 
-	// TableName modifies the [dynamodb.DeleteItemInput.TableName]
-	TableName *string
-	// ReturnConsumedCapacity modifies the [dynamodb.DeleteItemInput.ReturnConsumedCapacity]
-	ReturnConsumedCapacity types.ReturnConsumedCapacity
-	// ReturnItemCollectionMetrics modifies the [dynamodb.DeleteItemInput.ReturnItemCollectionMetrics]
-	ReturnItemCollectionMetrics types.ReturnItemCollectionMetrics
-	// ReturnValues modifies the [dynamodb.DeleteItemInput.ReturnValues]
-	ReturnValues types.ReturnValue
-	// ReturnValuesOnConditionCheckFailure modifies the [dynamodb.DeleteItemInput.ReturnValuesOnConditionCheckFailure].
-	ReturnValuesOnConditionCheckFailure types.ReturnValuesOnConditionCheckFailure
-
-	condition expression.ConditionBuilder
-	out       interface{}
+	// beforeBuild, if set, is called with the (possibly hook-modified) struct right before the expressions are built.
+	beforeBuild func(v interface{}) error
 }
 
 // Decode will decode the [dynamodb.DeleteItemOutput.Attributes] into the given struct pointer.
@@ -58,36 +58,12 @@ func (o *DeleteOpts) WithReturnValuesOnConditionCheckFailure(returnValuesOnCondi
 
 // And adds an expression.And to the condition expression.
 func (o *DeleteOpts) And(right expression.ConditionBuilder, other ...expression.ConditionBuilder) *DeleteOpts {
-	if o.condition.IsSet() {
-		o.condition = o.condition.And(right, other...)
-		return o
-	}
-
-	switch len(other) {
-	case 0:
-		o.condition = right
-	case 1:
-		o.condition = right.And(other[0])
-	default:
-		o.condition = right.And(other[0], other[1:]...)
-	}
+	o.and(right, other...)
 	return o
 }
 
-// Or adds an expression.And to the condition expression.
+// Or adds an expression.Or to the condition expression.
 func (o *DeleteOpts) Or(right expression.ConditionBuilder, other ...expression.ConditionBuilder) *DeleteOpts {
-	if o.condition.IsSet() {
-		o.condition = o.condition.Or(right, other...)
-		return o
-	}
-
-	switch len(other) {
-	case 0:
-		o.condition = right
-	case 1:
-		o.condition = right.Or(other[0])
-	default:
-		o.condition = right.Or(other[0], other[1:]...)
-	}
+	o.or(right, other...)
 	return o
 }
@@ -0,0 +1,236 @@
+package ddbfns
+
+import (
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Explain renders the request as a human-readable string suitable for logs and test failure messages.
+//
+// The expression attribute names and values are substituted into the expressions so that
+// `attribute_not_exists (#0)` with `{"#0": "id"}` becomes `attribute_not_exists(id)`. Supported inputs are
+// [dynamodb.PutItemInput], [dynamodb.UpdateItemInput], [dynamodb.DeleteItemInput], [dynamodb.GetItemInput],
+// [dynamodb.QueryInput], and [dynamodb.TransactWriteItemsInput] (each item on its own line), as either values or
+// pointers. For example:
+//
+//	UpdateItem my-table {id: "hello"}; SET version = version + 1, modifiedTime = 1700000000; IF version = 3
+//
+// Values are rendered similar to PartiQL: strings are quoted, sets use `<<` and `>>`, and binary values are base64
+// encoded. The output is not meant to be parsed; its format may change between versions.
+func Explain(input interface{}) string {
+	switch v := input.(type) {
+	case *dynamodb.PutItemInput:
+		return explainPut(v.TableName, v.Item, v.ConditionExpression, v.ExpressionAttributeNames, v.ExpressionAttributeValues)
+	case dynamodb.PutItemInput:
+		return Explain(&v)
+	case *dynamodb.UpdateItemInput:
+		return explainUpdate(v.TableName, v.Key, v.UpdateExpression, v.ConditionExpression, v.ExpressionAttributeNames, v.ExpressionAttributeValues)
+	case dynamodb.UpdateItemInput:
+		return Explain(&v)
+	case *dynamodb.DeleteItemInput:
+		return explainDelete(v.TableName, v.Key, v.ConditionExpression, v.ExpressionAttributeNames, v.ExpressionAttributeValues)
+	case dynamodb.DeleteItemInput:
+		return Explain(&v)
+	case *dynamodb.GetItemInput:
+		e := newExplainer("GetItem", v.TableName, v.ExpressionAttributeNames, nil)
+		e.item(v.Key)
+		e.clause("PROJECTION", v.ProjectionExpression)
+		if v.ConsistentRead != nil && *v.ConsistentRead {
+			e.parts = append(e.parts, "CONSISTENT")
+		}
+		return e.String()
+	case dynamodb.GetItemInput:
+		return Explain(&v)
+	case *dynamodb.QueryInput:
+		e := newExplainer("Query", v.TableName, v.ExpressionAttributeNames, v.ExpressionAttributeValues)
+		if v.IndexName != nil {
+			e.parts[0] += " INDEX " + *v.IndexName
+		}
+		e.clause("KEY", v.KeyConditionExpression)
+		e.clause("FILTER", v.FilterExpression)
+		e.clause("PROJECTION", v.ProjectionExpression)
+		if v.ScanIndexForward != nil && !*v.ScanIndexForward {
+			e.parts = append(e.parts, "DESC")
+		}
+		if v.Limit != nil {
+			e.parts = append(e.parts, "LIMIT "+strconv.Itoa(int(*v.Limit)))
+		}
+		if v.ExclusiveStartKey != nil {
+			e.parts = append(e.parts, "AFTER "+explainItem(v.ExclusiveStartKey))
+		}
+		return e.String()
+	case dynamodb.QueryInput:
+		return Explain(&v)
+	case *dynamodb.TransactWriteItemsInput:
+		lines := make([]string, 0, len(v.TransactItems))
+		for _, item := range v.TransactItems {
+			switch {
+			case item.Put != nil:
+				p := item.Put
+				lines = append(lines, explainPut(p.TableName, p.Item, p.ConditionExpression, p.ExpressionAttributeNames, p.ExpressionAttributeValues))
+			case item.Update != nil:
+				u := item.Update
+				lines = append(lines, explainUpdate(u.TableName, u.Key, u.UpdateExpression, u.ConditionExpression, u.ExpressionAttributeNames, u.ExpressionAttributeValues))
+			case item.Delete != nil:
+				d := item.Delete
+				lines = append(lines, explainDelete(d.TableName, d.Key, d.ConditionExpression, d.ExpressionAttributeNames, d.ExpressionAttributeValues))
+			case item.ConditionCheck != nil:
+				c := item.ConditionCheck
+				e := newExplainer("ConditionCheck", c.TableName, c.ExpressionAttributeNames, c.ExpressionAttributeValues)
+				e.item(c.Key)
+				e.clause("IF", c.ConditionExpression)
+				lines = append(lines, e.String())
+			}
+		}
+		return strings.Join(lines, "\n")
+	case dynamodb.TransactWriteItemsInput:
+		return Explain(&v)
+	default:
+		return fmt.Sprintf("%T %+v", input, input)
+	}
+}
+
+func explainPut(tableName *string, item map[string]types.AttributeValue, condition *string, names map[string]string, values map[string]types.AttributeValue) string {
+	e := newExplainer("PutItem", tableName, names, values)
+	e.item(item)
+	e.clause("IF", condition)
+	return e.String()
+}
+
+func explainUpdate(tableName *string, key map[string]types.AttributeValue, update, condition *string, names map[string]string, values map[string]types.AttributeValue) string {
+	e := newExplainer("UpdateItem", tableName, names, values)
+	e.item(key)
+	if update != nil {
+		// expression.Builder separates the SET, REMOVE, ADD, and DELETE clauses with newlines.
+		for _, clause := range strings.Split(*update, "\n") {
+			e.clause("", &clause)
+		}
+	}
+	e.clause("IF", condition)
+	return e.String()
+}
+
+func explainDelete(tableName *string, key map[string]types.AttributeValue, condition *string, names map[string]string, values map[string]types.AttributeValue) string {
+	e := newExplainer("DeleteItem", tableName, names, values)
+	e.item(key)
+	e.clause("IF", condition)
+	return e.String()
+}
+
+type explainer struct {
+	names  map[string]string
+	values map[string]types.AttributeValue
+	parts  []string
+}
+
+func newExplainer(op string, tableName *string, names map[string]string, values map[string]types.AttributeValue) *explainer {
+	if tableName != nil {
+		op += " " + *tableName
+	}
+
+	return &explainer{names: names, values: values, parts: []string{op}}
+}
+
+// item appends the rendered item or key to the first part.
+func (e *explainer) item(item map[string]types.AttributeValue) {
+	if item != nil {
+		e.parts[0] += " " + explainItem(item)
+	}
+}
+
+var (
+	placeholderRegex = regexp.MustCompile(`[#:][A-Za-z0-9_]+`)
+	functionRegex    = regexp.MustCompile(`\b(attribute_exists|attribute_not_exists|attribute_type|begins_with|contains|size|if_not_exists|list_append) \(`)
+)
+
+// clause appends the expression with its names and values substituted, prefixed by the given keyword if not empty.
+func (e *explainer) clause(keyword string, expr *string) {
+	if expr == nil || strings.TrimSpace(*expr) == "" {
+		return
+	}
+
+	s := placeholderRegex.ReplaceAllStringFunc(strings.TrimSpace(*expr), func(placeholder string) string {
+		if placeholder[0] == '#' {
+			if name, ok := e.names[placeholder]; ok {
+				return name
+			}
+		} else if av, ok := e.values[placeholder]; ok {
+			return explainValue(av)
+		}
+
+		return placeholder
+	})
+	s = functionRegex.ReplaceAllString(s, "$1(")
+
+	if keyword != "" {
+		s = keyword + " " + s
+	}
+
+	e.parts = append(e.parts, s)
+}
+
+func (e *explainer) String() string {
+	return strings.Join(e.parts, "; ")
+}
+
+// explainItem renders the item with its attributes sorted by name.
+func explainItem(item map[string]types.AttributeValue) string {
+	keys := make([]string, 0, len(item))
+	for k := range item {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k + ": " + explainValue(item[k])
+	}
+
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+func explainValue(av types.AttributeValue) string {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return strconv.Quote(v.Value)
+	case *types.AttributeValueMemberN:
+		return v.Value
+	case *types.AttributeValueMemberB:
+		return "b64" + strconv.Quote(base64.StdEncoding.EncodeToString(v.Value))
+	case *types.AttributeValueMemberBOOL:
+		return strconv.FormatBool(v.Value)
+	case *types.AttributeValueMemberNULL:
+		return "NULL"
+	case *types.AttributeValueMemberSS:
+		parts := make([]string, len(v.Value))
+		for i, s := range v.Value {
+			parts[i] = strconv.Quote(s)
+		}
+		return "<<" + strings.Join(parts, ", ") + ">>"
+	case *types.AttributeValueMemberNS:
+		return "<<" + strings.Join(v.Value, ", ") + ">>"
+	case *types.AttributeValueMemberBS:
+		parts := make([]string, len(v.Value))
+		for i, b := range v.Value {
+			parts[i] = "b64" + strconv.Quote(base64.StdEncoding.EncodeToString(b))
+		}
+		return "<<" + strings.Join(parts, ", ") + ">>"
+	case *types.AttributeValueMemberL:
+		parts := make([]string, len(v.Value))
+		for i, item := range v.Value {
+			parts[i] = explainValue(item)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case *types.AttributeValueMemberM:
+		return explainItem(v.Value)
+	default:
+		return fmt.Sprintf("%v", av)
+	}
+}
@@ -0,0 +1,105 @@
+package ddbfns
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type explainTest struct {
+	Id           string    `dynamodbav:"id,hashkey" tableName:"test"`
+	Version      int64     `dynamodbav:"version,version"`
+	ModifiedTime time.Time `dynamodbav:"modifiedTime,modifiedTime,unixtime"`
+	Tags         []string  `dynamodbav:"tags,stringset"`
+}
+
+func TestExplain(t *testing.T) {
+	clock := func(opts *RequestOptions) {
+		opts.Clock = func() time.Time {
+			return time.Unix(1700000000, 0)
+		}
+	}
+
+	put, err := Put(explainTest{Id: "hello", Tags: []string{"a", "b"}}, func(opts *PutOpts) {
+		clock(&opts.RequestOptions)
+	})
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+	assert.Equal(t, `PutItem test {id: "hello", modifiedTime: 1700000000, tags: <<"a", "b">>, version: 1}; IF attribute_not_exists(id)`, Explain(put))
+
+	update, err := Update(explainTest{Id: "hello", Version: 3}, func(opts *UpdateOpts) {
+		opts.Set("name", "world").Remove("notes")
+		clock(&opts.RequestOptions)
+	})
+	if err != nil {
+		t.Errorf("Update() error = %v", err)
+		return
+	}
+	assert.Equal(t, `UpdateItem test {id: "hello"}; ADD version 1; REMOVE notes; SET name = "world", modifiedTime = 1700000000; IF version = 3`, Explain(update))
+
+	del, err := Delete(explainTest{Id: "hello", Version: 3})
+	if err != nil {
+		t.Errorf("Delete() error = %v", err)
+		return
+	}
+	assert.Equal(t, `DeleteItem test {id: "hello"}; IF version = 3`, Explain(*del))
+
+	get, err := Get(explainTest{Id: "hello"}, func(opts *GetOpts) {
+		opts.WithProjectionExpression("id", "version")
+	})
+	if err != nil {
+		t.Errorf("Get() error = %v", err)
+		return
+	}
+	assert.Equal(t, `GetItem test {id: "hello"}; PROJECTION id, version`, Explain(get))
+}
+
+func TestExplain_Query(t *testing.T) {
+	expr, err := expression.NewBuilder().
+		WithKeyCondition(expression.Key("pk").Equal(expression.Value("USER#1")).And(expression.Key("sk").BeginsWith("ORDER#"))).
+		WithFilter(expression.Name("total").GreaterThan(expression.Value(10))).
+		Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                 aws.String("orders"),
+		IndexName:                 aws.String("ByUser"),
+		KeyConditionExpression:    expr.KeyCondition(),
+		FilterExpression:          expr.Filter(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int32(10),
+	}
+	assert.Equal(t, `Query orders INDEX ByUser; KEY (pk = "USER#1") AND (begins_with(sk, "ORDER#")); FILTER total > 10; DESC; LIMIT 10`, Explain(input))
+}
+
+func TestExplain_TransactWriteItems(t *testing.T) {
+	input := &dynamodb.TransactWriteItemsInput{TransactItems: []types.TransactWriteItem{
+		{Put: &types.Put{
+			TableName:                aws.String("test"),
+			Item:                     map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "a"}, "data": &types.AttributeValueMemberB{Value: []byte("hi")}},
+			ConditionExpression:      aws.String("attribute_not_exists (#0)"),
+			ExpressionAttributeNames: map[string]string{"#0": "id"},
+		}},
+		{ConditionCheck: &types.ConditionCheck{
+			TableName:                 aws.String("test"),
+			Key:                       map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "b"}},
+			ConditionExpression:       aws.String("#0 = :0"),
+			ExpressionAttributeNames:  map[string]string{"#0": "status"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{":0": &types.AttributeValueMemberL{Value: []types.AttributeValue{&types.AttributeValueMemberBOOL{Value: true}, &types.AttributeValueMemberNULL{Value: true}}}},
+		}},
+	}}
+
+	assert.Equal(t, `PutItem test {data: b64"aGk=", id: "a"}; IF attribute_not_exists(id)
+ConditionCheck test {id: "b"}; IF status = [true, NULL]`, Explain(input))
+}
@@ -19,6 +19,10 @@ import (
 //	Field string `dynamodbav:"-,hashkey" tableName:"my-table"`
 //	Field string `dynamodbav:"-,sortkey"`
 //
+//	// Composite keys for single-table design can be computed from other fields with `keyFormat`. On decoding, the
+//	// composite key is parsed back into its component fields. See also KeyBuilder.
+//	Field string `dynamodbav:"-,hashkey" tableName:"my-table" keyFormat:"USER#{UserID}"`
+//
 //	// Versioned attribute must have `version` show up in its `dynamodbav` tag. It must be a numeric type that
 //	// marshals to type N in DynamoDB.
 //	Field int64 `dynamodbav:"-,version"`
@@ -28,6 +32,25 @@ import (
 //	Field time.Time `dynamodbav:"-,createdTime,unixtime"`
 //	Field time.Time `dynamodbav:"-,modifiedTime,unixtime"`
 //
+//	// Soft delete requires a `deletedTime` attribute with the same requirements as the other timestamps. An optional
+//	// `ttl` attribute (time or numeric type) is set on soft delete if DeleteOpts.TTL is given. Put never writes a
+//	// zero-value `deletedTime` or `ttl` so that new items are not mistaken for tombstones.
+//	Field time.Time `dynamodbav:"-,deletedTime,unixtime"`
+//	Field time.Time `dynamodbav:"-,ttl"`
+//
+//	// Immutable attributes must have `immutable` in its `dynamodbav` tag. Update will refuse to modify them, while Put
+//	// on an existing item (non-zero version) will require the stored value to be the same as the given one.
+//	Field string `dynamodbav:"-,immutable"`
+//
+//	// Unique attributes must have a `unique` tag naming the constraint. TransactPut, TransactUpdate, and
+//	// TransactDelete maintain a sentinel item per unique value in the same transaction.
+//	Field string `dynamodbav:"-" unique:"email"`
+//
+//	// Validation rules are checked by Put and Update before the request is built. Each rule is its own struct tag.
+//	// `oneof` is a space-separated list of values.
+//	Field string `dynamodbav:"-" required:"true" maxLength:"64" pattern:"^[a-z]+$" oneof:"red green blue"`
+//	Field int    `dynamodbav:"-" min:"1" max:"10"`
+//
 // The zero-value Fns instance is ready for use. Prefer NewFns which can perform validation on the struct type.
 type Fns struct {
 	// Encoder is the attributevalue.Encoder to marshal structs into DynamoDB items.
@@ -38,9 +61,54 @@ type Fns struct {
 	//
 	// If nil, a default one will be created.
 	Decoder *attributevalue.Decoder
-
-	init  sync.Once
-	cache sync.Map
+	// TypeAttribute is the name of the type discriminator attribute used by RegisterType and DecodeItem.
+	//
+	// If empty, DefaultTypeAttribute is used.
+	TypeAttribute string
+	// HistoryOpts, if given, enables history mode which writes an immutable history item for every DoPut, DoUpdate,
+	// and DoDelete in the same transaction.
+	//
+	// See HistoryOpts for more information.
+	HistoryOpts *HistoryOpts
+	// ReadablePlaceholders, if true, names the expression attribute placeholders after the attributes instead of
+	// numbering them; for example, `#version = :version_old` instead of `#0 = :0`.
+	//
+	// Placeholders that would collide get a numeric suffix such as `#a_b_2`, and values in condition expressions are
+	// suffixed with `_old` since they are compared against the stored item.
+	ReadablePlaceholders bool
+	// CanonicalExpressions, if true, sorts the actions of each update clause and the operands of top-level AND
+	// conditions, then renames the placeholders in order of appearance. Identical logical requests will then produce
+	// byte-identical inputs regardless of the order in which the actions and conditions were added, which is useful for
+	// golden tests and request caching.
+	CanonicalExpressions bool
+	// Cache, if given, is a read-through cache in front of [Fns.DoGet] for hot keys.
+	//
+	// Eventually consistent GetItem requests without a projection expression are served from the cache if possible,
+	// and their results are stored in the cache otherwise. Consistent reads always go to DynamoDB but still refresh the
+	// cache. Every PutItem, UpdateItem, DeleteItem, and TransactWriteItems request made by the same Fns refreshes or
+	// invalidates the entries of the written items; the version attribute prevents a slow read from replacing a more
+	// recent write. See [NewLRUCache] for an in-memory implementation.
+	Cache Cache
+	// CoalesceGets, if true, deduplicates the in-flight GetItem requests of [Fns.DoGet] that have the same client,
+	// table, key, projection, and consistency so that a burst of concurrent calls for a hot key results in a single
+	// request.
+	//
+	// Each caller gets its own copy of the item so one caller's mutation cannot leak to another. The shared request is
+	// made with the first caller's context values and client options, and is only cancelled once every caller's context
+	// is done; a caller whose context is done returns immediately with its context's error. The consumed capacity is
+	// always returned so that it can be added to the [CapacityTracker] of every caller, each of whose budget is checked
+	// before joining the shared request.
+	CoalesceGets bool
+
+	init        sync.Once
+	cache       sync.Map
+	middlewares []Middleware
+	gets        getGroup
+	cacheGens   cacheGenerations
+
+	typesMu     sync.RWMutex
+	typesByName map[string]reflect.Type
+	namesByType map[reflect.Type]string
 }
 
 // ParseOpts customises the parsing and validation of NewFns, [Fns.ParseFromStruct], and [Fns.ParseFromType].
@@ -88,7 +156,7 @@ func (f *Fns) ParseFromType(t reflect.Type, optFns ...func(*ParseOpts)) error {
 		fn(&opts)
 	}
 
-	m, err := internal.ParseFromType(t)
+	m, err := parseModel(t)
 	if err != nil {
 		return err
 	}
@@ -103,24 +171,41 @@ func (f *Fns) ParseFromType(t reflect.Type, optFns ...func(*ParseOpts)) error {
 		return fmt.Errorf(`no timestamp fields in type "%s"`, t.Name())
 	}
 
-	f.cache.Store(t, m)
+	p, err := newPlan(m)
+	if err != nil {
+		return err
+	}
+
+	f.cache.Store(t, &cachedType{model: m, plan: p})
 	return nil
 }
 
 func (f *Fns) loadOrParse(t reflect.Type) (*internal.Model, error) {
+	m, _, err := f.loadPlan(t)
+	return m, err
+}
+
+// loadPlan is a variant of loadOrParse that also returns the plan of the struct type.
+func (f *Fns) loadPlan(t reflect.Type) (*internal.Model, *plan, error) {
 	t = internal.DereferencedType(t)
 	v, ok := f.cache.Load(t)
 	if ok {
-		return v.(*internal.Model), nil
+		c := v.(*cachedType)
+		return c.model, c.plan, nil
 	}
 
-	m, err := internal.ParseFromType(t)
+	m, err := parseModel(t)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	p, err := newPlan(m)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	f.cache.Store(t, m)
-	return m, nil
+	f.cache.Store(t, &cachedType{model: m, plan: p})
+	return m, p, nil
 }
 
 func (f *Fns) initFn() {
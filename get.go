@@ -7,7 +7,6 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 // Get creates the GetItem request for the given item.
@@ -16,12 +15,11 @@ import (
 // of the struct. Additionally, GetOpts provides convenient methods to customise the projection expression as well
 // (see WithProjectionExpression).
 func (f *Fns) Get(v interface{}, optFns ...func(*GetOpts)) (*dynamodb.GetItemInput, error) {
-	f.init.Do(f.initFn)
+	return f.get(v, applyOpts(optFns))
+}
 
-	opts := &GetOpts{}
-	for _, fn := range optFns {
-		fn(opts)
-	}
+func (f *Fns) get(v interface{}, opts *GetOpts) (*dynamodb.GetItemInput, error) {
+	f.init.Do(f.initFn)
 
 	attrs, err := f.loadOrParse(reflect.TypeOf(v))
 	if err != nil {
@@ -33,17 +31,9 @@ func (f *Fns) Get(v interface{}, optFns ...func(*GetOpts)) (*dynamodb.GetItemInp
 	}
 
 	// GetItem only needs the key.
-	var key map[string]types.AttributeValue
-	if av, err := f.Encoder.Encode(v); err != nil {
+	key, err := f.encodeKey(attrs, v)
+	if err != nil {
 		return nil, err
-	} else if asMap, ok := av.(*types.AttributeValueMemberM); !ok {
-		return nil, fmt.Errorf("item did not encode to M type")
-	} else {
-		item := asMap.Value
-		key = map[string]types.AttributeValue{attrs.HashKey.Name: item[attrs.HashKey.Name]}
-		if attrs.SortKey != nil {
-			key[attrs.SortKey.Name] = item[attrs.SortKey.Name]
-		}
 	}
 
 	getItemInput := &dynamodb.GetItemInput{
@@ -66,6 +56,7 @@ func (f *Fns) Get(v interface{}, optFns ...func(*GetOpts)) (*dynamodb.GetItemInp
 
 		getItemInput.ExpressionAttributeNames = expr.Names()
 		getItemInput.ProjectionExpression = expr.Projection()
+		f.rewriteGet(getItemInput)
 	}
 
 	return getItemInput, nil
@@ -78,24 +69,67 @@ func (f *Fns) Get(v interface{}, optFns ...func(*GetOpts)) (*dynamodb.GetItemInp
 //	Field string `dynamodbav:"-,hashkey" tableName:"my-table"`
 //
 // If the field doesn't have `tableName` tag, you must override the [GetOpts.TableName] for the request to succeed.
+//
+// If the item has been tombstoned by [Fns.SoftDelete], the returned [dynamodb.GetItemOutput.Item] will be empty as if
+// the item does not exist unless [GetOpts.IncludeDeleted] is true.
 func (f *Fns) DoGet(ctx context.Context, client *dynamodb.Client, v interface{}, optFns ...func(*GetOpts)) (*dynamodb.GetItemOutput, error) {
-	var opts *GetOpts
-	optFns = append(optFns, func(o *GetOpts) {
-		opts = o
-	})
+	opts := applyOpts(optFns)
 
-	input, err := f.Get(v, optFns...)
+	input, err := f.get(v, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	getItemOutput, err := client.GetItem(ctx, input)
-	if err != nil || opts.out == nil {
-		return getItemOutput, err
+	getItemOutput, cached := (*dynamodb.GetItemOutput)(nil), false
+	if f.Cache != nil {
+		getItemOutput, cached = f.getCached(input)
+	}
+
+	if !cached {
+		getItem := func(ctx context.Context) (*dynamodb.GetItemOutput, error) {
+			if f.Cache == nil {
+				return invoke(ctx, f, newRequest(input), client.GetItem, opts.ClientOptions)
+			}
+
+			// the generation must be captured before the request is sent; see cacheGenerations.
+			gen := f.cacheGeneration(input)
+			output, err := invoke(ctx, f, newRequest(input), client.GetItem, opts.ClientOptions)
+			if err == nil {
+				if attrs, err := f.loadOrParse(reflect.TypeOf(v)); err == nil {
+					f.setCached(attrs, input, output.Item, gen)
+				}
+			}
+
+			return output, err
+		}
+
+		if f.CoalesceGets {
+			// the consumed capacity is always returned so that it can be added to the tracker of every caller.
+			key := coalesceKey(client, input)
+			input = withReturnConsumedCapacity(input).(*dynamodb.GetItemInput)
+			getItemOutput, err = f.gets.do(ctx, key, getItem)
+		} else {
+			getItemOutput, err = getItem(ctx)
+		}
+		if err != nil {
+			return getItemOutput, err
+		}
+	}
+
+	if !opts.IncludeDeleted {
+		if attrs, err := f.loadOrParse(reflect.TypeOf(v)); err == nil && isDeleted(attrs, getItemOutput.Item) {
+			output := *getItemOutput
+			output.Item = nil
+			return &output, nil
+		}
+	}
+
+	if opts.out == nil {
+		return getItemOutput, nil
 	}
 
 	if item := getItemOutput.Item; len(item) != 0 {
-		err = f.Decoder.Decode(&types.AttributeValueMemberM{Value: item}, opts.out)
+		err = f.decode(item, opts.out)
 	}
 
 	return getItemOutput, err
@@ -1,20 +1,15 @@
 package ddbfns
 
-import (
-	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
-)
-
 // GetOpts customises [Fns.Get] operations per each invocation.
 type GetOpts struct {
-	// TableName modifies the [dynamodb.GetItemInput.TableName]
-	TableName *string
+	RequestOptions
+
 	// ConsistentRead modifies the [dynamodb.GetItemInput.ConsistentRead]
 	ConsistentRead *bool
-	// ReturnConsumedCapacity modifies the [dynamodb.GetItemInput.ReturnConsumedCapacity]
-	ReturnConsumedCapacity types.ReturnConsumedCapacity
+	// IncludeDeleted, if true, will make DoGet return items that have been tombstoned by [Fns.SoftDelete].
+	IncludeDeleted bool
 
 	names []string
-	out   interface{}
 }
 
 // Decode will decode the [dynamodb.GetItemOutput.Item] into the given struct pointer.
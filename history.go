@@ -0,0 +1,559 @@
+package ddbfns
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// HistoryOpts enables history mode on [Fns.HistoryOpts].
+//
+// In history mode, every [Fns.DoPut], [Fns.DoUpdate], and [Fns.DoDelete] (as well as their DoTransactXyz counterparts)
+// writes an immutable history item in the same transaction as the item itself. The history item shares the item's
+// hash key while its sort key is `HIST#` followed by the item's sort key (if any) and the new version number, so the
+// struct must have a version attribute. A DoPut stores the full new image, a DoUpdate stores the diff (SET values and
+// REMOVE names), and a DoDelete stores a marker. The actor is retrieved from the context; see [WithActor].
+//
+// Soft deletes by [Fns.DoDelete] or [Fns.DoSoftDelete] and restores by [Fns.DoRestore] are recorded as updates.
+// History items are skipped by [Fns.DoQuery], [Fns.DoScan], and [Fns.LoadCollection].
+//
+// Since the writes become TransactWriteItems requests, ReturnValues and Decode are not supported in history mode; the
+// DoXyz methods return an error if either is given.
+type HistoryOpts struct {
+	// TableName, if given, will write history items to this table instead of the item's own table.
+	TableName *string
+	// SortKeyName is the name of the sort key attribute of the history items if the struct has no sortkey field.
+	//
+	// If the struct has a sortkey field, its name is always used. Otherwise, TableName must be given, and SortKeyName
+	// defaults to DefaultHistorySortKeyName.
+	SortKeyName string
+}
+
+const (
+	// DefaultHistorySortKeyName is the default value of HistoryOpts.SortKeyName.
+	DefaultHistorySortKeyName = "sk"
+
+	historySortKeyPrefix = "HIST#"
+)
+
+// Names of the attributes of history items.
+const (
+	HistoryVersionAttribute   = "_version"
+	HistoryOperationAttribute = "_operation"
+	HistoryActorAttribute     = "_actor"
+	HistoryTimeAttribute      = "_time"
+	HistoryImageAttribute     = "_image"
+	HistorySetAttribute       = "_set"
+	HistoryRemoveAttribute    = "_remove"
+	HistoryChangedAttribute   = "_changed"
+)
+
+// Values of the HistoryOperationAttribute attribute.
+const (
+	HistoryOperationPut    = "PUT"
+	HistoryOperationUpdate = "UPDATE"
+	HistoryOperationDelete = "DELETE"
+)
+
+type actorKey struct{}
+
+// WithActor returns a new context that carries the given actor to be recorded in history items.
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor given to WithActor.
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok
+}
+
+// HistoryEntry is a decoded history item returned by [Fns.History].
+type HistoryEntry struct {
+	// Version is the version of the item after the write.
+	Version int64
+	// Operation is one of HistoryOperationPut, HistoryOperationUpdate, or HistoryOperationDelete.
+	Operation string
+	// Actor is the actor from the context of the write, if any.
+	Actor string
+	// Time is when the write request was created.
+	Time time.Time
+	// Image is the full new image written by a DoPut.
+	Image map[string]types.AttributeValue
+	// Set contains the values of the SET actions of a DoUpdate.
+	Set map[string]types.AttributeValue
+	// Remove contains the names of the REMOVE actions of a DoUpdate.
+	Remove []string
+	// Changed contains the names of all the attributes that were the target of an action of a DoUpdate.
+	//
+	// If Changed contains names that are not in Set or Remove (e.g. from ADD or arithmetic actions) or are document
+	// paths, the diff is incomplete and the version cannot be reconstructed by Revert.
+	Changed []string
+}
+
+// historyDiff is the diff of a DoUpdate.
+type historyDiff struct {
+	set     map[string]types.AttributeValue
+	remove  []string
+	changed []string
+}
+
+// historyItem creates the Put of the history item for the write to the item with the given key.
+func (f *Fns) historyItem(ctx context.Context, m *internal.Model, key map[string]types.AttributeValue, tableName *string, now time.Time, version int64, operation string, image map[string]types.AttributeValue, diff *historyDiff) (types.TransactWriteItem, error) {
+	histKey, histTableName, err := f.historyKey(m, key, tableName, version)
+	if err != nil {
+		return types.TransactWriteItem{}, err
+	}
+
+	item := histKey
+	item[HistoryVersionAttribute] = &types.AttributeValueMemberN{Value: strconv.FormatInt(version, 10)}
+	item[HistoryOperationAttribute] = &types.AttributeValueMemberS{Value: operation}
+	item[HistoryTimeAttribute] = &types.AttributeValueMemberS{Value: now.UTC().Format(time.RFC3339Nano)}
+	if actor, ok := ActorFromContext(ctx); ok {
+		item[HistoryActorAttribute] = &types.AttributeValueMemberS{Value: actor}
+	}
+	if image != nil {
+		item[HistoryImageAttribute] = &types.AttributeValueMemberM{Value: image}
+	}
+	if diff != nil {
+		item[HistorySetAttribute] = &types.AttributeValueMemberM{Value: diff.set}
+		if len(diff.remove) != 0 {
+			item[HistoryRemoveAttribute] = &types.AttributeValueMemberSS{Value: diff.remove}
+		}
+		if len(diff.changed) != 0 {
+			item[HistoryChangedAttribute] = &types.AttributeValueMemberSS{Value: diff.changed}
+		}
+	}
+
+	// history items are immutable.
+	expr, err := expression.NewBuilder().WithCondition(expression.Name(m.HashKey.Name).AttributeNotExists()).Build()
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("build expressions error: %w", err)
+	}
+
+	return types.TransactWriteItem{Put: f.rewriteTransactPut(&types.Put{
+		Item:                      item,
+		TableName:                 histTableName,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})}, nil
+}
+
+// historyKey returns the key and table name of the history item of the given version.
+//
+// If version is negative, the returned sort key is the prefix shared by all history items of the item.
+func (f *Fns) historyKey(m *internal.Model, key map[string]types.AttributeValue, tableName *string, version int64) (map[string]types.AttributeValue, *string, error) {
+	opts := f.HistoryOpts
+	if m.Version == nil {
+		return nil, nil, fmt.Errorf(`history mode requires a version field in type "%s"`, m.StructType.Name())
+	}
+
+	if opts.TableName != nil {
+		tableName = opts.TableName
+	}
+
+	sk := historySortKeyPrefix
+	skName := m.HashKey.Name
+	if m.SortKey != nil {
+		if opts.TableName == nil && m.SortKey.Field.Type.Kind() != reflect.String {
+			return nil, nil, fmt.Errorf(`history mode requires HistoryOpts.TableName since type "%s" has non-string sortkey`, m.StructType.Name())
+		}
+
+		skName = m.SortKey.Name
+		sk += uniqueValue(key[skName]) + "#"
+	} else if opts.TableName == nil {
+		return nil, nil, fmt.Errorf(`history mode requires HistoryOpts.TableName since type "%s" has no sortkey field`, m.StructType.Name())
+	} else if skName = opts.SortKeyName; skName == "" {
+		skName = DefaultHistorySortKeyName
+	}
+
+	if version >= 0 {
+		sk += fmt.Sprintf("%020d", version)
+	}
+
+	return map[string]types.AttributeValue{
+		m.HashKey.Name: key[m.HashKey.Name],
+		skName:         &types.AttributeValueMemberS{Value: sk},
+	}, tableName, nil
+}
+
+// isHistoryVersion returns true if s is a zero-padded version as written by historyKey.
+func isHistoryVersion(s string) bool {
+	if len(s) != 20 {
+		return false
+	}
+
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newVersion returns the version of the item after a write given the version in the struct.
+func newVersion(m *internal.Model, v interface{}) (int64, error) {
+	if m.Version == nil {
+		return 0, fmt.Errorf(`history mode requires a version field in type "%s"`, m.StructType.Name())
+	}
+
+	version, err := m.Version.Get(reflect.Indirect(reflect.ValueOf(v)))
+	if err != nil {
+		return 0, fmt.Errorf("get version value error: %w", err)
+	}
+
+	switch {
+	case version.CanInt():
+		return version.Int() + 1, nil
+	case version.CanUint():
+		return int64(version.Uint()) + 1, nil
+	case version.CanFloat():
+		// history items are keyed by integral versions so a fractional version would collide with another one.
+		next := version.Float() + 1
+		if next != math.Trunc(next) {
+			return 0, fmt.Errorf("history mode requires integral versions but got %v", version.Float())
+		}
+		return int64(next), nil
+	default:
+		return 0, fmt.Errorf("version attribute's type (%s) is unknown numeric type", version.Type())
+	}
+}
+
+// historyUpdateItem creates the Put of the history item recording the diff of the given UpdateItem request.
+func (f *Fns) historyUpdateItem(ctx context.Context, m *internal.Model, v interface{}, input *dynamodb.UpdateItemInput, opts *UpdateOpts) (types.TransactWriteItem, error) {
+	version, err := newVersion(m, v)
+	if err != nil {
+		return types.TransactWriteItem{}, err
+	}
+
+	diff, err := f.diffOf(m, opts, version)
+	if err != nil {
+		return types.TransactWriteItem{}, err
+	}
+
+	return f.historyItem(ctx, m, input.Key, input.TableName, opts.now(), version, HistoryOperationUpdate, nil, diff)
+}
+
+// doHistoryUpdate executes the UpdateItem request created from opts in the same transaction as its history item.
+//
+// Since the request becomes a TransactWriteItems request, only the consumed capacity and result metadata of the
+// UpdateItemOutput are available.
+func (f *Fns) doHistoryUpdate(ctx context.Context, client TransactWriteItemsAPIClient, v interface{}, input *dynamodb.UpdateItemInput, opts *UpdateOpts) (*dynamodb.UpdateItemOutput, error) {
+	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := f.historyUpdateItem(ctx, attrs, v, input, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	transactInput := &dynamodb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{{Update: &types.Update{
+			Key:                                 input.Key,
+			TableName:                           input.TableName,
+			UpdateExpression:                    input.UpdateExpression,
+			ConditionExpression:                 input.ConditionExpression,
+			ExpressionAttributeNames:            input.ExpressionAttributeNames,
+			ExpressionAttributeValues:           input.ExpressionAttributeValues,
+			ReturnValuesOnConditionCheckFailure: input.ReturnValuesOnConditionCheckFailure,
+		}}, item},
+		ReturnConsumedCapacity:      input.ReturnConsumedCapacity,
+		ReturnItemCollectionMetrics: input.ReturnItemCollectionMetrics,
+	}
+
+	output, err := invoke(ctx, f, newRequest(transactInput), client.TransactWriteItems, opts.ClientOptions)
+	if output == nil {
+		return nil, err
+	}
+
+	return &dynamodb.UpdateItemOutput{
+		ConsumedCapacity: firstConsumedCapacity(output.ConsumedCapacity),
+		ResultMetadata:   output.ResultMetadata,
+	}, err
+}
+
+// isHistoryItem returns true if the item is a history item written in history mode.
+//
+// History items share the partition of their items so they are returned by the same queries.
+func isHistoryItem(item map[string]types.AttributeValue) bool {
+	_, hasVersion := item[HistoryVersionAttribute]
+	_, hasOperation := item[HistoryOperationAttribute]
+	return hasVersion && hasOperation
+}
+
+// diffOf returns the diff of the update actions recorded in opts.
+//
+// The version attribute is incremented with an ADD action so its new value is recorded as if it was SET instead.
+func (f *Fns) diffOf(m *internal.Model, opts *UpdateOpts, version int64) (*historyDiff, error) {
+	diff := &historyDiff{set: map[string]types.AttributeValue{
+		m.Version.Name: &types.AttributeValueMemberN{Value: strconv.FormatInt(version, 10)},
+	}}
+	for name, value := range opts.values {
+		if name == m.Version.Name {
+			continue
+		}
+
+		av, ok := value.(types.AttributeValue)
+		if !ok {
+			var err error
+			if av, err = f.Encoder.Encode(value); err != nil {
+				return nil, fmt.Errorf("encode %s error: %w", name, err)
+			}
+		}
+
+		diff.set[name] = av
+	}
+
+	for _, name := range opts.names {
+		if !slices.Contains(diff.changed, name) {
+			diff.changed = append(diff.changed, name)
+		}
+		if opts.removes(name) && !slices.Contains(diff.remove, name) {
+			diff.remove = append(diff.remove, name)
+		}
+	}
+
+	return diff, nil
+}
+
+// History returns all history items of the given item in ascending version order.
+//
+// The key argument is a struct of the same type as the item whose key attributes are set. See [HistoryOpts] for how
+// the history items are written.
+func (f *Fns) History(ctx context.Context, client dynamodb.QueryAPIClient, key interface{}) ([]*HistoryEntry, error) {
+	f.init.Do(f.initFn)
+
+	if f.HistoryOpts == nil {
+		return nil, fmt.Errorf("history mode is not enabled")
+	}
+
+	attrs, err := f.loadOrParse(reflect.TypeOf(key))
+	if err != nil {
+		return nil, err
+	}
+
+	itemKey, err := f.encodeKey(attrs, key)
+	if err != nil {
+		return nil, err
+	}
+
+	histKey, tableName, err := f.historyKey(attrs, itemKey, attrs.TableName, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	var skName, prefix string
+	keyCondition := expression.Key(attrs.HashKey.Name).Equal(expression.Value(histKey[attrs.HashKey.Name]))
+	for name, av := range histKey {
+		if name != attrs.HashKey.Name {
+			skName, prefix = name, av.(*types.AttributeValueMemberS).Value
+			keyCondition = keyCondition.And(expression.Key(name).BeginsWith(prefix))
+		}
+	}
+
+	expr, err := expression.NewBuilder().WithKeyCondition(keyCondition).Build()
+	if err != nil {
+		return nil, fmt.Errorf("build expressions error: %w", err)
+	}
+
+	var entries []*HistoryEntry
+	paginator := dynamodb.NewQueryPaginator(f.queryClient(client), f.rewriteQuery(&dynamodb.QueryInput{
+		TableName:                 tableName,
+		KeyConditionExpression:    expr.KeyCondition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+		ConsistentRead:            &[]bool{true}[0],
+	}))
+	for paginator.HasMorePages() {
+		queryOutput, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, item := range queryOutput.Items {
+			// the prefix also matches the history items of sibling items whose sort key starts with this one's, such as
+			// "ORDER#1" for "ORDER", so the rest of the sort key must be exactly the version.
+			if sk, ok := item[skName].(*types.AttributeValueMemberS); !ok || !isHistoryVersion(strings.TrimPrefix(sk.Value, prefix)) {
+				continue
+			}
+
+			entry, err := decodeHistoryEntry(item)
+			if err != nil {
+				return nil, err
+			}
+
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries, nil
+}
+
+// Revert restores the given item to the state of the given version by writing a new version with [Fns.DoPut].
+//
+// The state is reconstructed from the latest DoPut image at or before the given version, then the diffs of subsequent
+// DoUpdate history items are replayed. Returns an error if the state cannot be reconstructed, such as when a diff is
+// incomplete (see [HistoryEntry.Changed]) or when the given version is a DoDelete. The current item is read with a
+// consistent read to use its version for optimistic locking; if it doesn't exist, the reverted item is created anew.
+//
+// The key argument is a struct of the same type as the item whose key attributes are set.
+func (f *Fns) Revert(ctx context.Context, client *dynamodb.Client, key interface{}, version int64) (*dynamodb.PutItemOutput, error) {
+	entries, err := f.History(ctx, client, key)
+	if err != nil {
+		return nil, err
+	}
+
+	image, err := replayHistory(entries, version)
+	if err != nil {
+		return nil, err
+	}
+
+	attrs, err := f.loadOrParse(reflect.TypeOf(key))
+	if err != nil {
+		return nil, err
+	}
+
+	// the current version is needed for optimistic locking.
+	current := reflect.New(attrs.StructType)
+	getItemOutput, err := f.DoGet(ctx, client, key, func(opts *GetOpts) {
+		opts.ConsistentRead = &[]bool{true}[0]
+		opts.IncludeDeleted = true
+		opts.WithProjectionExpression(attrs.Version.Name)
+		opts.Decode(current.Interface())
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reverted := reflect.New(attrs.StructType)
+	if err = f.Decoder.Decode(&types.AttributeValueMemberM{Value: image}, reverted.Interface()); err != nil {
+		return nil, fmt.Errorf("decode version %d error: %w", version, err)
+	}
+
+	versionValue := reflect.Zero(attrs.Version.Field.Type)
+	if len(getItemOutput.Item) != 0 {
+		if versionValue, err = attrs.Version.Get(current.Elem()); err != nil {
+			return nil, fmt.Errorf("get version value error: %w", err)
+		}
+	}
+	if err = setField(reverted.Elem(), attrs.Version, versionValue); err != nil {
+		return nil, err
+	}
+
+	return f.DoPut(ctx, client, reverted.Interface())
+}
+
+// replayHistory reconstructs the image of the given version from the history entries in ascending version order.
+func replayHistory(entries []*HistoryEntry, version int64) (map[string]types.AttributeValue, error) {
+	end := slices.IndexFunc(entries, func(entry *HistoryEntry) bool {
+		return entry.Version == version
+	})
+	if end == -1 {
+		return nil, fmt.Errorf("version %d not found in history", version)
+	}
+	if entries[end].Operation == HistoryOperationDelete {
+		return nil, fmt.Errorf("version %d is a delete", version)
+	}
+
+	start := end
+	for ; start >= 0 && entries[start].Image == nil; start-- {
+	}
+	if start == -1 {
+		return nil, fmt.Errorf("no full image at or before version %d", version)
+	}
+
+	image := make(map[string]types.AttributeValue, len(entries[start].Image))
+	for k, v := range entries[start].Image {
+		image[k] = v
+	}
+
+	for _, entry := range entries[start+1 : end+1] {
+		for _, name := range entry.Changed {
+			if _, ok := entry.Set[name]; (!ok && !slices.Contains(entry.Remove, name)) || strings.ContainsAny(name, ".[") {
+				return nil, fmt.Errorf("version %d has an incomplete diff for attribute %s", entry.Version, name)
+			}
+		}
+
+		for name, av := range entry.Set {
+			image[name] = av
+		}
+		for _, name := range entry.Remove {
+			delete(image, name)
+		}
+	}
+
+	return image, nil
+}
+
+func decodeHistoryEntry(item map[string]types.AttributeValue) (*HistoryEntry, error) {
+	entry := &HistoryEntry{}
+
+	if av, ok := item[HistoryVersionAttribute].(*types.AttributeValueMemberN); ok {
+		version, err := strconv.ParseInt(av.Value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse history version error: %w", err)
+		}
+		entry.Version = version
+	}
+	if av, ok := item[HistoryOperationAttribute].(*types.AttributeValueMemberS); ok {
+		entry.Operation = av.Value
+	}
+	if av, ok := item[HistoryActorAttribute].(*types.AttributeValueMemberS); ok {
+		entry.Actor = av.Value
+	}
+	if av, ok := item[HistoryTimeAttribute].(*types.AttributeValueMemberS); ok {
+		t, err := time.Parse(time.RFC3339Nano, av.Value)
+		if err != nil {
+			return nil, fmt.Errorf("parse history time error: %w", err)
+		}
+		entry.Time = t
+	}
+	if av, ok := item[HistoryImageAttribute].(*types.AttributeValueMemberM); ok {
+		entry.Image = av.Value
+	}
+	if av, ok := item[HistorySetAttribute].(*types.AttributeValueMemberM); ok {
+		entry.Set = av.Value
+	}
+	if av, ok := item[HistoryRemoveAttribute].(*types.AttributeValueMemberSS); ok {
+		entry.Remove = av.Value
+	}
+	if av, ok := item[HistoryChangedAttribute].(*types.AttributeValueMemberSS); ok {
+		entry.Changed = av.Value
+	}
+
+	return entry, nil
+}
+
+// setField sets the attribute's field of the struct value to the given value.
+func setField(sv reflect.Value, attr *internal.Attribute, value reflect.Value) error {
+	fv, err := attr.Get(sv)
+	if err != nil {
+		return fmt.Errorf("get %s value error: %w", attr.Name, err)
+	}
+
+	fv.Set(value)
+	return nil
+}
+
+// firstConsumedCapacity returns the consumed capacity of the first table of a TransactWriteItems request.
+func firstConsumedCapacity(cc []types.ConsumedCapacity) *types.ConsumedCapacity {
+	if len(cc) == 0 {
+		return nil
+	}
+
+	return &cc[0]
+}
@@ -0,0 +1,228 @@
+package ddbfns
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type historyTest struct {
+	PK      string `dynamodbav:"pk,hashkey" tableName:"documents"`
+	SK      string `dynamodbav:"sk,sortkey"`
+	Title   string `dynamodbav:"title,omitempty"`
+	Notes   string `dynamodbav:"notes,omitempty"`
+	Version int64  `dynamodbav:"version,version"`
+}
+
+func TestFns_History(t *testing.T) {
+	f := &Fns{HistoryOpts: &HistoryOpts{}}
+	ctx := WithActor(context.Background(), "alice")
+
+	put, _, err := f.transactPut(ctx, historyTest{PK: "DOC#1", SK: "META", Title: "hello"}, &PutOpts{})
+	if err != nil {
+		t.Errorf("transactPut() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, 2, len(put.TransactItems))
+	hist := put.TransactItems[1].Put
+	assert.Equal(t, "documents", *hist.TableName)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "DOC#1"}, hist.Item["pk"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "HIST#META#00000000000000000001"}, hist.Item["sk"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1"}, hist.Item[HistoryVersionAttribute])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: HistoryOperationPut}, hist.Item[HistoryOperationAttribute])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "alice"}, hist.Item[HistoryActorAttribute])
+	assert.Equal(t, &types.AttributeValueMemberM{Value: put.TransactItems[0].Put.Item}, hist.Item[HistoryImageAttribute])
+	assert.Equal(t, "attribute_not_exists (#0)", *hist.ConditionExpression)
+
+	update, _, err := f.transactUpdate(ctx, historyTest{PK: "DOC#1", SK: "META", Version: 1}, newUpdateOpts(func(opts *UpdateOpts) {
+		opts.Set("title", "world").Remove("notes")
+	}, nil))
+	if err != nil {
+		t.Errorf("transactUpdate() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, 2, len(update.TransactItems))
+	hist = update.TransactItems[1].Put
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "HIST#META#00000000000000000002"}, hist.Item["sk"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: HistoryOperationUpdate}, hist.Item[HistoryOperationAttribute])
+	assert.Equal(t, &types.AttributeValueMemberM{Value: map[string]types.AttributeValue{
+		"title":   &types.AttributeValueMemberS{Value: "world"},
+		"version": &types.AttributeValueMemberN{Value: "2"},
+	}}, hist.Item[HistorySetAttribute])
+	assert.Equal(t, &types.AttributeValueMemberSS{Value: []string{"notes"}}, hist.Item[HistoryRemoveAttribute])
+
+	del, err := f.transactDelete(ctx, historyTest{PK: "DOC#1", SK: "META", Version: 2}, &DeleteOpts{})
+	if err != nil {
+		t.Errorf("transactDelete() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, 2, len(del.TransactItems))
+	hist = del.TransactItems[1].Put
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "HIST#META#00000000000000000003"}, hist.Item["sk"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: HistoryOperationDelete}, hist.Item[HistoryOperationAttribute])
+
+	client := &fakeQueryClient{pages: []*dynamodb.QueryOutput{{Items: []map[string]types.AttributeValue{
+		put.TransactItems[1].Put.Item,
+		update.TransactItems[1].Put.Item,
+		del.TransactItems[1].Put.Item,
+	}}}}
+	entries, err := f.History(ctx, client, historyTest{PK: "DOC#1", SK: "META"})
+	if err != nil {
+		t.Errorf("History() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, "(#0 = :0) AND (begins_with (#1, :1))", *client.inputs[0].KeyConditionExpression)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "HIST#META#"}, client.inputs[0].ExpressionAttributeValues[":1"])
+	assert.Equal(t, 3, len(entries))
+	assert.Equal(t, int64(2), entries[1].Version)
+	assert.Equal(t, "alice", entries[1].Actor)
+	assert.Equal(t, []string{"notes"}, entries[1].Remove)
+
+	image, err := replayHistory(entries, 2)
+	if err != nil {
+		t.Errorf("replayHistory() error = %v", err)
+		return
+	}
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "world"}, image["title"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2"}, image["version"])
+
+	_, err = replayHistory(entries, 3)
+	assert.Error(t, err)
+}
+
+func TestFns_HistoryIncompleteDiff(t *testing.T) {
+	f := &Fns{HistoryOpts: &HistoryOpts{}}
+
+	update, _, err := f.transactUpdate(context.Background(), historyTest{PK: "DOC#1", SK: "META", Version: 1}, newUpdateOpts(func(opts *UpdateOpts) {
+		opts.Add("views", 1)
+	}, nil))
+	if err != nil {
+		t.Errorf("transactUpdate() error = %v", err)
+		return
+	}
+
+	entry, err := decodeHistoryEntry(update.TransactItems[1].Put.Item)
+	if err != nil {
+		t.Errorf("decodeHistoryEntry() error = %v", err)
+		return
+	}
+
+	_, err = replayHistory([]*HistoryEntry{{Version: 1, Operation: HistoryOperationPut, Image: map[string]types.AttributeValue{}}, entry}, 2)
+	assert.ErrorContains(t, err, "incomplete diff for attribute views")
+}
+
+func TestFns_HistorySoftDelete(t *testing.T) {
+	type Test struct {
+		PK          string    `dynamodbav:"pk,hashkey" tableName:"documents"`
+		SK          string    `dynamodbav:"sk,sortkey"`
+		Version     int64     `dynamodbav:"version,version"`
+		DeletedTime time.Time `dynamodbav:"deletedTime,deletedTime,unixtime"`
+	}
+
+	var operations, bodies []string
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		operations = append(operations, operation)
+		bodies = append(bodies, string(body))
+		return 200, `{}`
+	})
+
+	ctx := context.Background()
+	f := &Fns{HistoryOpts: &HistoryOpts{}}
+
+	_, err := f.DoDelete(ctx, client, Test{PK: "DOC#1", SK: "META", Version: 1}, func(opts *DeleteOpts) {
+		opts.Soft = true
+	})
+	assert.NoError(t, err)
+	_, err = f.DoRestore(ctx, client, Test{PK: "DOC#1", SK: "META", Version: 2})
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"TransactWriteItems", "TransactWriteItems"}, operations)
+	assert.Contains(t, bodies[0], "HIST#META#00000000000000000002")
+	assert.Contains(t, bodies[0], `"_changed":{"SS":["deletedTime","version"]}`)
+	assert.Contains(t, bodies[1], "HIST#META#00000000000000000003")
+	assert.Contains(t, bodies[1], `"_remove":{"SS":["deletedTime"]}`)
+
+	// the items cannot be returned by TransactWriteItems.
+	operations = nil
+	_, err = f.DoDelete(ctx, client, Test{PK: "DOC#1", SK: "META", Version: 1}, func(opts *DeleteOpts) {
+		opts.Soft = true
+		opts.ReturnValues = types.ReturnValueAllNew
+	})
+	assert.ErrorContains(t, err, "ReturnValues")
+	_, err = f.DoPut(ctx, client, Test{PK: "DOC#1", SK: "META"}, func(opts *PutOpts) {
+		opts.Decode(&Test{})
+	})
+	assert.ErrorContains(t, err, "Decode")
+	assert.Empty(t, operations)
+}
+
+func TestFns_HistorySiblingSortKeys(t *testing.T) {
+	f := &Fns{HistoryOpts: &HistoryOpts{}}
+	ctx := context.Background()
+
+	// "ORDER#1" shares the prefix of "ORDER" so their history items share the prefix "HIST#ORDER#" too.
+	order, _, err := f.transactPut(ctx, historyTest{PK: "DOC#1", SK: "ORDER", Title: "order"}, &PutOpts{})
+	assert.NoError(t, err)
+	sibling, _, err := f.transactPut(ctx, historyTest{PK: "DOC#1", SK: "ORDER#1", Title: "sibling"}, &PutOpts{})
+	assert.NoError(t, err)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "HIST#ORDER#1#00000000000000000001"}, sibling.TransactItems[1].Put.Item["sk"])
+
+	client := &fakeQueryClient{pages: []*dynamodb.QueryOutput{{Items: []map[string]types.AttributeValue{
+		order.TransactItems[1].Put.Item,
+		sibling.TransactItems[1].Put.Item,
+	}}}}
+	entries, err := f.History(ctx, client, historyTest{PK: "DOC#1", SK: "ORDER"})
+	if err != nil {
+		t.Errorf("History() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, 1, len(entries))
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "order"}, entries[0].Image["title"])
+}
+
+func TestFns_HistoryItemsSkipped(t *testing.T) {
+	f := &Fns{HistoryOpts: &HistoryOpts{}}
+	assert.NoError(t, RegisterType[historyTest](f, "Document"))
+
+	put, _, err := f.transactPut(context.Background(), historyTest{PK: "DOC#1", SK: "META", Title: "hello"}, &PutOpts{})
+	assert.NoError(t, err)
+
+	client := &fakeQueryClient{pages: []*dynamodb.QueryOutput{{Items: []map[string]types.AttributeValue{
+		put.TransactItems[0].Put.Item,
+		put.TransactItems[1].Put.Item,
+	}}}}
+
+	var got []interface{}
+	assert.NoError(t, f.DoQuery(context.Background(), client, &dynamodb.QueryInput{}, func(v interface{}) error {
+		got = append(got, v)
+		return nil
+	}))
+	assert.Equal(t, []interface{}{&historyTest{PK: "DOC#1", SK: "META", Title: "hello", Version: 1}}, got)
+}
+
+func TestNewVersion(t *testing.T) {
+	type Test struct {
+		PK      string  `dynamodbav:"pk,hashkey" tableName:"documents"`
+		Version float64 `dynamodbav:"version,version"`
+	}
+
+	m, err := DefaultFns.loadOrParse(reflect.TypeOf(Test{}))
+	assert.NoError(t, err)
+
+	version, err := newVersion(m, Test{Version: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), version)
+
+	_, err = newVersion(m, Test{Version: 1.5})
+	assert.Error(t, err)
+}
@@ -0,0 +1,87 @@
+package ddbfns
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// BeforePutHook can be implemented by the struct to be called by [Fns.Put] before the request is built.
+//
+// Since Fns never mutates the caller's struct, the hook is called on a shallow copy of the struct so it's safe to use a
+// pointer receiver to normalise fields (e.g. lower-casing email) or compute derived attributes (e.g. GSI keys). The copy
+// is then validated and encoded in place of the original struct. The hook can also modify the PutOpts.
+//
+// If the hook returns a non-nil error, Put will return that error as-is.
+type BeforePutHook interface {
+	BeforePut(ctx context.Context, opts *PutOpts) error
+}
+
+// BeforeUpdateHook can be implemented by the struct to be called by [Fns.Update] before the request is built.
+//
+// The hook is called on a shallow copy of the struct after all the UpdateOpts functions have been applied, so it can add
+// more update actions or conditions to the UpdateOpts.
+//
+// If the hook returns a non-nil error, Update will return that error as-is.
+type BeforeUpdateHook interface {
+	BeforeUpdate(ctx context.Context, opts *UpdateOpts) error
+}
+
+// BeforeDeleteHook can be implemented by the struct to be called by [Fns.Delete] before the request is built.
+//
+// The hook is called on a shallow copy of the struct after all the DeleteOpts functions have been applied.
+//
+// If the hook returns a non-nil error, Delete will return that error as-is.
+type BeforeDeleteHook interface {
+	BeforeDelete(ctx context.Context, opts *DeleteOpts) error
+}
+
+// AfterDecodeHook can be implemented by the struct to be called after an item has been decoded into it.
+//
+// The hook is called by the Decode paths of [Fns.DoGet], [Fns.DoPut], [Fns.DoUpdate], and [Fns.DoDelete] after the item
+// has been successfully unmarshalled into the struct pointer. It can be used to redact fields or fill in fields that are
+// not stored in DynamoDB. If the hook returns a non-nil error, the DoXyz method will return that error along with the
+// output.
+type AfterDecodeHook interface {
+	AfterDecode() error
+}
+
+// shallowCopy returns a pointer to a shallow copy of the struct value v if *T implements the hook type H.
+//
+// Returns false if neither T nor *T implements H.
+func shallowCopy[H any](v interface{}) (interface{}, H, bool) {
+	iv := reflect.Indirect(reflect.ValueOf(v))
+	if iv.Kind() != reflect.Struct {
+		var hook H
+		return v, hook, false
+	}
+
+	// checking the type first avoids allocating the copy for structs that don't implement H.
+	if !reflect.PointerTo(iv.Type()).Implements(reflect.TypeFor[H]()) {
+		var hook H
+		return v, hook, false
+	}
+
+	ptr := reflect.New(iv.Type())
+	ptr.Elem().Set(iv)
+	return ptr.Interface(), ptr.Interface().(H), true
+}
+
+// decode unmarshalls the item into out, parses composite keys back into their component fields, then calls
+// AfterDecodeHook.AfterDecode if out implements it.
+func (f *Fns) decode(item map[string]types.AttributeValue, out interface{}) error {
+	if err := f.Decoder.Decode(&types.AttributeValueMemberM{Value: item}, out); err != nil {
+		return err
+	}
+
+	if err := f.parseKeys(out); err != nil {
+		return err
+	}
+
+	if hook, ok := out.(AfterDecodeHook); ok {
+		return hook.AfterDecode()
+	}
+
+	return nil
+}
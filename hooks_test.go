@@ -0,0 +1,76 @@
+package ddbfns
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type hookedTest struct {
+	Id       string `dynamodbav:"id,hashkey" tableName:""`
+	Email    string `dynamodbav:"email"`
+	GSI1Key  string `dynamodbav:"gsi1pk,omitempty"`
+	Password string `dynamodbav:"password"`
+}
+
+func (h *hookedTest) BeforePut(_ context.Context, opts *PutOpts) error {
+	h.Email = strings.ToLower(h.Email)
+	h.GSI1Key = "EMAIL#" + h.Email
+	opts.WithTableName("hooked")
+	return nil
+}
+
+func (h hookedTest) BeforeUpdate(_ context.Context, opts *UpdateOpts) error {
+	opts.Set("updatedBy", "hook")
+	return nil
+}
+
+func (h *hookedTest) BeforeDelete(_ context.Context, _ *DeleteOpts) error {
+	return fmt.Errorf("cannot delete %s", h.Id)
+}
+
+func (h *hookedTest) AfterDecode() error {
+	h.Password = ""
+	return nil
+}
+
+func TestFns_Hooks(t *testing.T) {
+	input := hookedTest{Id: "hello", Email: "Alice@Example.com", Password: "secret"}
+
+	// this is to make sure the input item is not mutated.
+	before := MustToJSON(input)
+
+	putItemInput, err := Put(input)
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+	assert.JSONEq(t, before, MustToJSON(input))
+	assert.Equal(t, "hooked", *putItemInput.TableName)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "alice@example.com"}, putItemInput.Item["email"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "EMAIL#alice@example.com"}, putItemInput.Item["gsi1pk"])
+
+	updateItemInput, err := Update(&input, func(opts *UpdateOpts) {
+		opts.Set("notes", "world!")
+	})
+	if err != nil {
+		t.Errorf("Update() error = %v", err)
+		return
+	}
+	assert.Equal(t, "SET #0 = :0, #1 = :1\n", *updateItemInput.UpdateExpression)
+	assert.Equal(t, map[string]string{"#0": "notes", "#1": "updatedBy"}, updateItemInput.ExpressionAttributeNames)
+
+	_, err = Delete(input)
+	assert.EqualError(t, err, "cannot delete hello")
+
+	var out hookedTest
+	f := &Fns{}
+	f.init.Do(f.initFn)
+	err = f.decode(putItemInput.Item, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, hookedTest{Id: "hello", Email: "alice@example.com", GSI1Key: "EMAIL#alice@example.com"}, out)
+}
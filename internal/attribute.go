@@ -2,6 +2,7 @@ package internal
 
 import (
 	"reflect"
+	"regexp"
 )
 
 // Attribute contains metadata about a reflect.StructField that represents a DynamoDB attribute.
@@ -14,6 +15,29 @@ type Attribute struct {
 	OmitEmpty bool
 	// UnixTime is true only if the `dynamodbav` struct tag also includes `unixtime`.
 	UnixTime bool
+	// AsString is true only if the `dynamodbav` struct tag also includes `string`.
+	AsString bool
+	// EncoderOptions is true only if the `dynamodbav` struct tag also includes attributevalue options other than
+	// `string` that change how the value is encoded, such as `omitempty`, `nullempty`, or `unixtime`.
+	EncoderOptions bool
+	// Immutable is true only if the `dynamodbav` struct tag also includes `immutable`.
+	Immutable bool
+	// Required is parsed from the `required` struct tag.
+	Required bool
+	// Min is parsed from the `min` struct tag.
+	Min *float64
+	// Max is parsed from the `max` struct tag.
+	Max *float64
+	// MaxLength is parsed from the `maxLength` struct tag.
+	MaxLength *int
+	// Pattern is compiled from the `pattern` struct tag.
+	Pattern *regexp.Regexp
+	// OneOf is parsed from the space-separated `oneof` struct tag.
+	OneOf []string
+	// KeyFormat is parsed from the `keyFormat` struct tag of a hashkey or sortkey field.
+	KeyFormat *KeyFormat
+	// Unique is the name of the uniqueness constraint from the `unique` struct tag.
+	Unique string
 }
 
 // Get returns the reflected value from the given struct value.
@@ -0,0 +1,32 @@
+// Package gentest contains the models used to test the code generated by cmd/ddbfns-gen.
+package gentest
+
+import "time"
+
+//go:generate go run github.com/nguyengg/go-ddb-fns/cmd/ddbfns-gen -type Item,Counter -output models_ddbfns.go
+
+// Status is a named type to test omitempty on types declared in the same package.
+type Status string
+
+// Item has a sort key, a version, and all the timestamp attributes.
+type Item struct {
+	PK           string    `dynamodbav:"pk,hashkey" tableName:"items"`
+	SK           string    `dynamodbav:"sk,sortkey"`
+	Version      int64     `dynamodbav:"version,version"`
+	CreatedTime  time.Time `dynamodbav:"createdTime,createdTime"`
+	ModifiedTime time.Time `dynamodbav:"modifiedTime,modifiedTime,unixtime"`
+	ExpiresAt    time.Time `dynamodbav:"expiresAt,ttl,unixtime"`
+	Status       Status    `dynamodbav:"status,omitempty"`
+	Tags         []string  `dynamodbav:"tags,stringset"`
+	Count        int       `dynamodbav:"count,omitempty"`
+	Labels       map[string]string
+	Ignored      string `dynamodbav:"-"`
+	unexported   string
+}
+
+// Counter has no sort key and no table name.
+type Counter struct {
+	ID    string  `dynamodbav:"id,hashkey" tableName:""`
+	Value uint32  `dynamodbav:"value,version"`
+	Note  *string `dynamodbav:"note,omitempty"`
+}
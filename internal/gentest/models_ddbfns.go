@@ -0,0 +1,306 @@
+// Code generated by ddbfns-gen -type Item,Counter; DO NOT EDIT.
+
+package gentest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddbfns "github.com/nguyengg/go-ddb-fns"
+)
+
+// Attribute names of Item.
+const (
+	ItemPKAttr           = "pk"
+	ItemSKAttr           = "sk"
+	ItemVersionAttr      = "version"
+	ItemCreatedTimeAttr  = "createdTime"
+	ItemModifiedTimeAttr = "modifiedTime"
+	ItemExpiresAtAttr    = "expiresAt"
+	ItemStatusAttr       = "status"
+	ItemTagsAttr         = "tags"
+	ItemCountAttr        = "count"
+	ItemLabelsAttr       = "Labels"
+)
+
+var ItemTableName = "items"
+
+var ItemSchema = &ddbfns.Schema{
+	TableName: &ItemTableName,
+	Attributes: []ddbfns.SchemaAttribute{
+		{Field: "PK", Name: ItemPKAttr, Options: []string{"hashkey"}},
+		{Field: "SK", Name: ItemSKAttr, Options: []string{"sortkey"}},
+		{Field: "Version", Name: ItemVersionAttr, Options: []string{"version"}},
+		{Field: "CreatedTime", Name: ItemCreatedTimeAttr, Options: []string{"createdTime"}},
+		{Field: "ModifiedTime", Name: ItemModifiedTimeAttr, Options: []string{"modifiedTime", "unixtime"}},
+		{Field: "ExpiresAt", Name: ItemExpiresAtAttr, Options: []string{"ttl", "unixtime"}},
+		{Field: "Status", Name: ItemStatusAttr},
+		{Field: "Tags", Name: ItemTagsAttr},
+		{Field: "Count", Name: ItemCountAttr},
+		{Field: "Labels", Name: ItemLabelsAttr},
+	},
+}
+
+// DynamoDBSchema implements ddbfns.SchemaProvider.
+func (v *Item) DynamoDBSchema() *ddbfns.Schema {
+	return ItemSchema
+}
+
+// DynamoDBKeys implements ddbfns.KeyBuilder.
+func (v Item) DynamoDBKeys() (hashKey, sortKey interface{}) {
+	return v.PK, v.SK
+}
+
+// MarshalDynamoDBAttributeValue implements attributevalue.Marshaler.
+func (v Item) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	item := make(map[string]types.AttributeValue, 10)
+	{
+		av, err := attributevalue.Marshal(v.PK)
+		if err != nil {
+			return nil, fmt.Errorf("marshal PK error: %w", err)
+		}
+		item[ItemPKAttr] = av
+	}
+	{
+		av, err := attributevalue.Marshal(v.SK)
+		if err != nil {
+			return nil, fmt.Errorf("marshal SK error: %w", err)
+		}
+		item[ItemSKAttr] = av
+	}
+	{
+		av, err := attributevalue.Marshal(v.Version)
+		if err != nil {
+			return nil, fmt.Errorf("marshal Version error: %w", err)
+		}
+		item[ItemVersionAttr] = av
+	}
+	{
+		av, err := attributevalue.Marshal(v.CreatedTime)
+		if err != nil {
+			return nil, fmt.Errorf("marshal CreatedTime error: %w", err)
+		}
+		item[ItemCreatedTimeAttr] = av
+	}
+	{
+		av, err := attributevalue.UnixTime(v.ModifiedTime).MarshalDynamoDBAttributeValue()
+		if err != nil {
+			return nil, fmt.Errorf("marshal ModifiedTime error: %w", err)
+		}
+		item[ItemModifiedTimeAttr] = av
+	}
+	{
+		av, err := attributevalue.UnixTime(v.ExpiresAt).MarshalDynamoDBAttributeValue()
+		if err != nil {
+			return nil, fmt.Errorf("marshal ExpiresAt error: %w", err)
+		}
+		item[ItemExpiresAtAttr] = av
+	}
+	if v.Status != "" {
+		av, err := attributevalue.Marshal(v.Status)
+		if err != nil {
+			return nil, fmt.Errorf("marshal Status error: %w", err)
+		}
+		item[ItemStatusAttr] = av
+	}
+	{
+		if len(v.Tags) != 0 {
+			item[ItemTagsAttr] = &types.AttributeValueMemberSS{Value: v.Tags}
+		} else {
+			item[ItemTagsAttr] = &types.AttributeValueMemberNULL{Value: true}
+		}
+	}
+	if v.Count != 0 {
+		av, err := attributevalue.Marshal(v.Count)
+		if err != nil {
+			return nil, fmt.Errorf("marshal Count error: %w", err)
+		}
+		item[ItemCountAttr] = av
+	}
+	{
+		av, err := attributevalue.Marshal(v.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("marshal Labels error: %w", err)
+		}
+		item[ItemLabelsAttr] = av
+	}
+
+	return &types.AttributeValueMemberM{Value: item}, nil
+}
+
+// UnmarshalDynamoDBAttributeValue implements attributevalue.Unmarshaler.
+func (v *Item) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	switch av := av.(type) {
+	case *types.AttributeValueMemberNULL:
+		return nil
+	case *types.AttributeValueMemberM:
+		if av, ok := av.Value[ItemPKAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.PK); err != nil {
+				return fmt.Errorf("unmarshal PK error: %w", err)
+			}
+		}
+		if av, ok := av.Value[ItemSKAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.SK); err != nil {
+				return fmt.Errorf("unmarshal SK error: %w", err)
+			}
+		}
+		if av, ok := av.Value[ItemVersionAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.Version); err != nil {
+				return fmt.Errorf("unmarshal Version error: %w", err)
+			}
+		}
+		if av, ok := av.Value[ItemCreatedTimeAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.CreatedTime); err != nil {
+				return fmt.Errorf("unmarshal CreatedTime error: %w", err)
+			}
+		}
+		if av, ok := av.Value[ItemModifiedTimeAttr]; ok {
+			var t attributevalue.UnixTime
+			if err := attributevalue.Unmarshal(av, &t); err != nil {
+				return fmt.Errorf("unmarshal ModifiedTime error: %w", err)
+			}
+			v.ModifiedTime = time.Time(t)
+		}
+		if av, ok := av.Value[ItemExpiresAtAttr]; ok {
+			var t attributevalue.UnixTime
+			if err := attributevalue.Unmarshal(av, &t); err != nil {
+				return fmt.Errorf("unmarshal ExpiresAt error: %w", err)
+			}
+			v.ExpiresAt = time.Time(t)
+		}
+		if av, ok := av.Value[ItemStatusAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.Status); err != nil {
+				return fmt.Errorf("unmarshal Status error: %w", err)
+			}
+		}
+		if av, ok := av.Value[ItemTagsAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.Tags); err != nil {
+				return fmt.Errorf("unmarshal Tags error: %w", err)
+			}
+		}
+		if av, ok := av.Value[ItemCountAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.Count); err != nil {
+				return fmt.Errorf("unmarshal Count error: %w", err)
+			}
+		}
+		if av, ok := av.Value[ItemLabelsAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.Labels); err != nil {
+				return fmt.Errorf("unmarshal Labels error: %w", err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("cannot unmarshal %T into Item", av)
+	}
+}
+
+// NextVersion returns the version that ddbfns will write for v.
+func (v Item) NextVersion() int64 {
+	return v.Version + 1
+}
+
+// VersionCondition returns the optimistic locking condition that ddbfns will add for v.
+func (v Item) VersionCondition() expression.ConditionBuilder {
+	if v.Version == 0 {
+		return expression.Name(ItemPKAttr).AttributeNotExists()
+	}
+
+	return expression.Name(ItemVersionAttr).Equal(expression.Value(v.Version))
+}
+
+// Attribute names of Counter.
+const (
+	CounterIDAttr    = "id"
+	CounterValueAttr = "value"
+	CounterNoteAttr  = "note"
+)
+
+var CounterSchema = &ddbfns.Schema{
+	Attributes: []ddbfns.SchemaAttribute{
+		{Field: "ID", Name: CounterIDAttr, Options: []string{"hashkey"}},
+		{Field: "Value", Name: CounterValueAttr, Options: []string{"version"}},
+		{Field: "Note", Name: CounterNoteAttr},
+	},
+}
+
+// DynamoDBSchema implements ddbfns.SchemaProvider.
+func (v *Counter) DynamoDBSchema() *ddbfns.Schema {
+	return CounterSchema
+}
+
+// DynamoDBKeys implements ddbfns.KeyBuilder.
+func (v Counter) DynamoDBKeys() (hashKey, sortKey interface{}) {
+	return v.ID, nil
+}
+
+// MarshalDynamoDBAttributeValue implements attributevalue.Marshaler.
+func (v Counter) MarshalDynamoDBAttributeValue() (types.AttributeValue, error) {
+	item := make(map[string]types.AttributeValue, 3)
+	{
+		av, err := attributevalue.Marshal(v.ID)
+		if err != nil {
+			return nil, fmt.Errorf("marshal ID error: %w", err)
+		}
+		item[CounterIDAttr] = av
+	}
+	{
+		av, err := attributevalue.Marshal(v.Value)
+		if err != nil {
+			return nil, fmt.Errorf("marshal Value error: %w", err)
+		}
+		item[CounterValueAttr] = av
+	}
+	if v.Note != nil {
+		av, err := attributevalue.Marshal(v.Note)
+		if err != nil {
+			return nil, fmt.Errorf("marshal Note error: %w", err)
+		}
+		item[CounterNoteAttr] = av
+	}
+
+	return &types.AttributeValueMemberM{Value: item}, nil
+}
+
+// UnmarshalDynamoDBAttributeValue implements attributevalue.Unmarshaler.
+func (v *Counter) UnmarshalDynamoDBAttributeValue(av types.AttributeValue) error {
+	switch av := av.(type) {
+	case *types.AttributeValueMemberNULL:
+		return nil
+	case *types.AttributeValueMemberM:
+		if av, ok := av.Value[CounterIDAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.ID); err != nil {
+				return fmt.Errorf("unmarshal ID error: %w", err)
+			}
+		}
+		if av, ok := av.Value[CounterValueAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.Value); err != nil {
+				return fmt.Errorf("unmarshal Value error: %w", err)
+			}
+		}
+		if av, ok := av.Value[CounterNoteAttr]; ok {
+			if err := attributevalue.Unmarshal(av, &v.Note); err != nil {
+				return fmt.Errorf("unmarshal Note error: %w", err)
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("cannot unmarshal %T into Counter", av)
+	}
+}
+
+// NextVersion returns the version that ddbfns will write for v.
+func (v Counter) NextVersion() uint32 {
+	return v.Value + 1
+}
+
+// VersionCondition returns the optimistic locking condition that ddbfns will add for v.
+func (v Counter) VersionCondition() expression.ConditionBuilder {
+	if v.Value == 0 {
+		return expression.Name(CounterIDAttr).AttributeNotExists()
+	}
+
+	return expression.Name(CounterValueAttr).Equal(expression.Value(v.Value))
+}
@@ -0,0 +1,221 @@
+package gentest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddbfns "github.com/nguyengg/go-ddb-fns"
+	"github.com/stretchr/testify/assert"
+)
+
+// reflectItem is identical to Item but without the generated methods so that Fns must parse its struct tags.
+type reflectItem struct {
+	PK           string    `dynamodbav:"pk,hashkey" tableName:"items"`
+	SK           string    `dynamodbav:"sk,sortkey"`
+	Version      int64     `dynamodbav:"version,version"`
+	CreatedTime  time.Time `dynamodbav:"createdTime,createdTime"`
+	ModifiedTime time.Time `dynamodbav:"modifiedTime,modifiedTime,unixtime"`
+	ExpiresAt    time.Time `dynamodbav:"expiresAt,ttl,unixtime"`
+	Status       Status    `dynamodbav:"status,omitempty"`
+	Tags         []string  `dynamodbav:"tags,stringset"`
+	Count        int       `dynamodbav:"count,omitempty"`
+	Labels       map[string]string
+	Ignored      string `dynamodbav:"-"`
+	unexported   string
+}
+
+// reflectCounter is identical to Counter but without the generated methods.
+type reflectCounter struct {
+	ID    string  `dynamodbav:"id,hashkey" tableName:""`
+	Value uint32  `dynamodbav:"value,version"`
+	Note  *string `dynamodbav:"note,omitempty"`
+}
+
+var testTime = time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)
+
+func clock(opts *ddbfns.RequestOptions) {
+	opts.Clock = func() time.Time {
+		return testTime
+	}
+}
+
+func testItems() []Item {
+	return []Item{
+		{PK: "hello", SK: "world"},
+		// an empty non-nil set must not become an empty SS which DynamoDB rejects.
+		{PK: "hello", SK: "world", Tags: []string{}},
+		{
+			PK:           "hello",
+			SK:           "world",
+			Version:      3,
+			CreatedTime:  testTime.Add(-time.Hour),
+			ModifiedTime: testTime.Add(-time.Minute),
+			ExpiresAt:    testTime.Add(time.Hour),
+			Status:       "active",
+			Tags:         []string{"a", "b"},
+			Count:        7,
+			Labels:       map[string]string{"key": "value"},
+			Ignored:      "ignored",
+		},
+	}
+}
+
+func TestFns_GeneratedPut(t *testing.T) {
+	f := &ddbfns.Fns{}
+	for _, item := range testItems() {
+		want, err := f.Put(reflectItem(item), func(opts *ddbfns.PutOpts) {
+			clock(&opts.RequestOptions)
+		})
+		if err != nil {
+			t.Errorf("Put() error = %v", err)
+			return
+		}
+
+		got, err := f.Put(item, func(opts *ddbfns.PutOpts) {
+			clock(&opts.RequestOptions)
+		})
+		if err != nil {
+			t.Errorf("Put() error = %v", err)
+			return
+		}
+
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestFns_GeneratedUpdate(t *testing.T) {
+	f := &ddbfns.Fns{}
+	for _, item := range testItems() {
+		want, err := f.Update(reflectItem(item), func(opts *ddbfns.UpdateOpts) {
+			opts.Set("status", "inactive")
+			clock(&opts.RequestOptions)
+		})
+		if err != nil {
+			t.Errorf("Update() error = %v", err)
+			return
+		}
+
+		got, err := f.Update(item, func(opts *ddbfns.UpdateOpts) {
+			opts.Set(ItemStatusAttr, "inactive")
+			clock(&opts.RequestOptions)
+		})
+		if err != nil {
+			t.Errorf("Update() error = %v", err)
+			return
+		}
+
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestFns_GeneratedGetAndDelete(t *testing.T) {
+	f := &ddbfns.Fns{}
+	for _, item := range testItems() {
+		wantGet, err := f.Get(reflectItem(item))
+		if err != nil {
+			t.Errorf("Get() error = %v", err)
+			return
+		}
+
+		gotGet, err := f.Get(item)
+		if err != nil {
+			t.Errorf("Get() error = %v", err)
+			return
+		}
+
+		assert.Equal(t, wantGet, gotGet)
+
+		wantDelete, err := f.Delete(reflectItem(item))
+		if err != nil {
+			t.Errorf("Delete() error = %v", err)
+			return
+		}
+
+		gotDelete, err := f.Delete(item)
+		if err != nil {
+			t.Errorf("Delete() error = %v", err)
+			return
+		}
+
+		assert.Equal(t, wantDelete, gotDelete)
+	}
+}
+
+func TestFns_GeneratedCounter(t *testing.T) {
+	f := &ddbfns.Fns{}
+	note := "note"
+	for _, counter := range []Counter{{ID: "hello"}, {ID: "hello", Value: 2, Note: &note}} {
+		want, err := f.Put(reflectCounter(counter), func(opts *ddbfns.PutOpts) {
+			opts.WithTableName("counters")
+		})
+		if err != nil {
+			t.Errorf("Put() error = %v", err)
+			return
+		}
+
+		got, err := f.Put(counter, func(opts *ddbfns.PutOpts) {
+			opts.WithTableName("counters")
+		})
+		if err != nil {
+			t.Errorf("Put() error = %v", err)
+			return
+		}
+
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestGenerated_Marshal(t *testing.T) {
+	for _, item := range testItems() {
+		want, err := attributevalue.Marshal(reflectItem(item))
+		if err != nil {
+			t.Errorf("Marshal() error = %v", err)
+			return
+		}
+
+		got, err := attributevalue.Marshal(item)
+		if err != nil {
+			t.Errorf("Marshal() error = %v", err)
+			return
+		}
+
+		assert.Equal(t, want, got)
+
+		var decoded Item
+		if err = attributevalue.Unmarshal(got, &decoded); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+			return
+		}
+
+		var wantDecoded reflectItem
+		if err = attributevalue.Unmarshal(want, &wantDecoded); err != nil {
+			t.Errorf("Unmarshal() error = %v", err)
+			return
+		}
+
+		assert.Equal(t, wantDecoded, reflectItem(decoded))
+	}
+}
+
+func TestGenerated_Version(t *testing.T) {
+	assert.Equal(t, int64(4), Item{Version: 3}.NextVersion())
+
+	expr, err := expression.NewBuilder().WithCondition(Item{}.VersionCondition()).Build()
+	if err != nil {
+		t.Errorf("Build() error = %v", err)
+		return
+	}
+	assert.Equal(t, "attribute_not_exists (#0)", *expr.Condition())
+	assert.Equal(t, map[string]string{"#0": ItemPKAttr}, expr.Names())
+
+	expr, err = expression.NewBuilder().WithCondition(Item{Version: 3}.VersionCondition()).Build()
+	if err != nil {
+		t.Errorf("Build() error = %v", err)
+		return
+	}
+	assert.Equal(t, "#0 = :0", *expr.Condition())
+	assert.Equal(t, map[string]types.AttributeValue{":0": &types.AttributeValueMemberN{Value: "3"}}, expr.Values())
+}
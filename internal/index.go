@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Index contains metadata about a secondary index parsed from the `gsi` and `lsi` struct tags.
+type Index struct {
+	// Name is the name of the index.
+	Name string
+	// HashKey is the partition key of the index. For local secondary indexes, this is always the table's hashkey.
+	HashKey *Attribute
+	// SortKey is the optional sort key of the index.
+	SortKey *Attribute
+}
+
+// parseIndexTags parses the `gsi` and `lsi` struct tags of the given field.
+//
+// The `gsi` tag is a space-separated list of `IndexName,hashkey` or `IndexName,sortkey` while the `lsi` tag is a
+// space-separated list of index names for which the field is the sort key.
+func (m *Model) parseIndexTags(attr *Attribute, structField reflect.StructField) error {
+	if v, ok := structField.Tag.Lookup("gsi"); ok {
+		if !validKeyAttribute(structField) {
+			return fmt.Errorf(`unsupported gsi field type "%s"`, structField.Type)
+		}
+
+		for _, s := range strings.Fields(v) {
+			name, role, _ := strings.Cut(s, ",")
+			if name == "" {
+				return fmt.Errorf(`empty index name in gsi tag on field "%s"`, structField.Name)
+			}
+
+			index := findIndex(m.GlobalSecondaryIndexes, name)
+			if index == nil {
+				index = &Index{Name: name}
+				m.GlobalSecondaryIndexes = append(m.GlobalSecondaryIndexes, index)
+			}
+
+			var target **Attribute
+			switch role {
+			case "hashkey":
+				target = &index.HashKey
+			case "sortkey":
+				target = &index.SortKey
+			default:
+				return fmt.Errorf(`invalid gsi tag "%s" on field "%s"; must be either IndexName,hashkey or IndexName,sortkey`, s, structField.Name)
+			}
+
+			if *target != nil {
+				return fmt.Errorf(`found multiple %s fields for gsi "%s"`, role, name)
+			}
+			*target = attr
+		}
+	}
+
+	if v, ok := structField.Tag.Lookup("lsi"); ok {
+		if !validKeyAttribute(structField) {
+			return fmt.Errorf(`unsupported lsi field type "%s"`, structField.Type)
+		}
+
+		for _, name := range strings.Fields(v) {
+			if findIndex(m.LocalSecondaryIndexes, name) != nil {
+				return fmt.Errorf(`found multiple sortkey fields for lsi "%s"`, name)
+			}
+
+			m.LocalSecondaryIndexes = append(m.LocalSecondaryIndexes, &Index{Name: name, SortKey: attr})
+		}
+	}
+
+	return nil
+}
+
+// validateIndexes validates the indexes once all fields have been parsed.
+func (m *Model) validateIndexes() error {
+	for _, index := range m.GlobalSecondaryIndexes {
+		if index.HashKey == nil {
+			return fmt.Errorf(`no hashkey field for gsi "%s"`, index.Name)
+		}
+	}
+
+	for _, index := range m.LocalSecondaryIndexes {
+		if m.SortKey == nil {
+			return fmt.Errorf(`lsi "%s" requires a sortkey field in type "%s"`, index.Name, m.StructType.Name())
+		}
+		index.HashKey = m.HashKey
+	}
+
+	return nil
+}
+
+func findIndex(indexes []*Index, name string) *Index {
+	for _, index := range indexes {
+		if index.Name == name {
+			return index
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// KeyFormat is parsed from the `keyFormat` struct tag of a hashkey or sortkey field.
+//
+// The format is a template such as `USER#{UserID}` where each `{FieldName}` placeholder refers to another exported
+// field of the same struct.
+type KeyFormat struct {
+	// Template is the original `keyFormat` struct tag value.
+	Template string
+	// Literals are the constant parts of the template; Literals[i] precedes Fields[i] so there is always one more
+	// literal than there are fields.
+	Literals []string
+	// Fields are the struct fields referenced by the placeholders.
+	Fields []reflect.StructField
+
+	re *regexp.Regexp
+}
+
+// ParseKeyFormat parses the given template against the fields of struct type t.
+func ParseKeyFormat(t reflect.Type, template string) (*KeyFormat, error) {
+	k := &KeyFormat{Template: template}
+
+	var pattern strings.Builder
+	pattern.WriteString("^")
+
+	rest := template
+	for {
+		i := strings.IndexByte(rest, '{')
+		if i == -1 {
+			k.Literals = append(k.Literals, rest)
+			pattern.WriteString(regexp.QuoteMeta(rest))
+			break
+		}
+
+		j := strings.IndexByte(rest[i:], '}')
+		if j == -1 {
+			return nil, fmt.Errorf(`unclosed placeholder in keyFormat "%s"`, template)
+		}
+
+		name := rest[i+1 : i+j]
+		structField, ok := t.FieldByName(name)
+		if !ok || !structField.IsExported() {
+			return nil, fmt.Errorf(`keyFormat "%s" references unknown field "%s"`, template, name)
+		}
+
+		switch kind := structField.Type.Kind(); {
+		case kind == reflect.String, isNumeric(kind):
+		default:
+			return nil, fmt.Errorf(`keyFormat "%s" references field "%s" of unsupported type "%s"`, template, name, structField.Type)
+		}
+
+		k.Literals = append(k.Literals, rest[:i])
+		k.Fields = append(k.Fields, structField)
+		pattern.WriteString(regexp.QuoteMeta(rest[:i]))
+		pattern.WriteString("(.*?)")
+
+		rest = rest[i+j+1:]
+	}
+
+	if len(k.Fields) == 0 {
+		return nil, fmt.Errorf(`keyFormat "%s" has no placeholders`, template)
+	}
+
+	pattern.WriteString("$")
+	k.re = regexp.MustCompile(pattern.String())
+
+	return k, nil
+}
+
+// Format renders the template using the field values from the given struct value.
+func (k *KeyFormat) Format(v reflect.Value) (string, error) {
+	var b strings.Builder
+	for i, structField := range k.Fields {
+		b.WriteString(k.Literals[i])
+
+		fv, err := v.FieldByIndexErr(structField.Index)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case fv.Kind() == reflect.String:
+			b.WriteString(fv.String())
+		case fv.CanInt():
+			b.WriteString(strconv.FormatInt(fv.Int(), 10))
+		case fv.CanUint():
+			b.WriteString(strconv.FormatUint(fv.Uint(), 10))
+		case fv.CanFloat():
+			b.WriteString(strconv.FormatFloat(fv.Float(), 'f', -1, 64))
+		}
+	}
+	b.WriteString(k.Literals[len(k.Literals)-1])
+
+	return b.String(), nil
+}
+
+// Parse is the inverse of Format; it parses the composite key s and sets the component fields of the given addressable
+// struct value.
+func (k *KeyFormat) Parse(s string, v reflect.Value) error {
+	matches := k.re.FindStringSubmatch(s)
+	if matches == nil {
+		return fmt.Errorf(`key "%s" does not match keyFormat "%s"`, s, k.Template)
+	}
+
+	for i, structField := range k.Fields {
+		fv, err := v.FieldByIndexErr(structField.Index)
+		if err != nil {
+			return err
+		}
+
+		m := matches[i+1]
+		switch {
+		case fv.Kind() == reflect.String:
+			fv.SetString(m)
+		case fv.CanInt():
+			n, err := strconv.ParseInt(m, 10, fv.Type().Bits())
+			if err != nil {
+				return fmt.Errorf(`parse field "%s" from key "%s" error: %w`, structField.Name, s, err)
+			}
+			fv.SetInt(n)
+		case fv.CanUint():
+			n, err := strconv.ParseUint(m, 10, fv.Type().Bits())
+			if err != nil {
+				return fmt.Errorf(`parse field "%s" from key "%s" error: %w`, structField.Name, s, err)
+			}
+			fv.SetUint(n)
+		case fv.CanFloat():
+			n, err := strconv.ParseFloat(m, fv.Type().Bits())
+			if err != nil {
+				return fmt.Errorf(`parse field "%s" from key "%s" error: %w`, structField.Name, s, err)
+			}
+			fv.SetFloat(n)
+		}
+	}
+
+	return nil
+}
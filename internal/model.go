@@ -22,6 +22,18 @@ type Model struct {
 	Version      *Attribute
 	CreatedTime  *Attribute
 	ModifiedTime *Attribute
+	DeletedTime  *Attribute
+	TTL          *Attribute
+	// Immutables are the attributes whose `dynamodbav` struct tag includes `immutable`.
+	Immutables []*Attribute
+	// Validated are the attributes that have at least one validation rule such as `required` or `pattern`.
+	Validated []*Attribute
+	// Uniques are the attributes that have a `unique` struct tag.
+	Uniques []*Attribute
+	// GlobalSecondaryIndexes are parsed from the `gsi` struct tags.
+	GlobalSecondaryIndexes []*Index
+	// LocalSecondaryIndexes are parsed from the `lsi` struct tags.
+	LocalSecondaryIndexes []*Index
 }
 
 // DereferencedType returns the innermost type that is not reflect.Interface or reflect.Ptr.
@@ -46,6 +58,10 @@ func ParseFromStruct(v interface{}) (*Model, error) {
 // Returns an error if there are validation issues.
 func ParseFromType(t reflect.Type) (*Model, error) {
 	t = DereferencedType(t)
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf(`type "%s" is not a struct`, t)
+	}
+
 	m := &Model{StructType: t}
 
 	for i, n := 0, t.NumField(); i < n; i++ {
@@ -65,67 +81,194 @@ func ParseFromType(t reflect.Type) (*Model, error) {
 			continue
 		}
 
-		attr := &Attribute{Name: name, Field: structField}
-		for _, tag = range tags[1:] {
-			switch tag {
-			case "hashkey":
-				if m.HashKey != nil {
-					return nil, fmt.Errorf(`found multiple hashkey fields in type "%s"`, t.Name())
-				}
-
-				if !validKeyAttribute(structField) {
-					return nil, fmt.Errorf(`unsupported hashkey field type "%s"`, structField.Type)
-				}
-
-				m.HashKey = attr
-				if v, ok := structField.Tag.Lookup("tableName"); !ok {
-					return nil, fmt.Errorf(`missing tableName tag on hashkey field`)
-				} else if v != "" {
-					m.TableName = &v
-				}
-			case "sortkey":
-				if m.SortKey != nil {
-					return nil, fmt.Errorf(`found multiple sortkey fields in type "%s"`, t.Name())
-				}
-
-				if !validKeyAttribute(structField) {
-					return nil, fmt.Errorf(`unsupported sortkey field type "%s"`, structField.Type)
-				}
-
-				m.SortKey = attr
-			case "version":
-				if m.Version != nil {
-					return nil, fmt.Errorf(`found multiple version fields in type "%s"`, t.Name())
-				}
-
-				if !validVersionAttribute(structField) {
-					return nil, fmt.Errorf(`unsupported version field type "%s"`, structField.Type)
-				}
-
-				m.Version = attr
-			case "createdTime":
-				if m.CreatedTime != nil {
-					return nil, fmt.Errorf(`found multiple createdTime fields in type "%s"`, t.Name())
-				}
-
-				if !validTimeAttribute(structField) {
-					return nil, fmt.Errorf(`unsupported createdTime field type "%s"`, structField.Type)
-				}
-
-				m.CreatedTime = attr
-			case "modifiedTime":
-				if m.ModifiedTime != nil {
-					return nil, fmt.Errorf(`found multiple modifiedTime fields in type "%s"`, t.Name())
-				}
-
-				if !validTimeAttribute(structField) {
-					return nil, fmt.Errorf(`unsupported modifiedTime field type "%s"`, structField.Type)
-				}
-
-				m.ModifiedTime = attr
-			case "unixtime":
-				attr.UnixTime = true
+		attr, err := m.addField(structField, name, tags[1:])
+		if err != nil {
+			return nil, err
+		}
+
+		if attr == m.HashKey {
+			if v, ok := structField.Tag.Lookup("tableName"); !ok {
+				return nil, fmt.Errorf(`missing tableName tag on hashkey field`)
+			} else if v != "" {
+				m.TableName = &v
+			}
+		}
+
+		if v, ok := structField.Tag.Lookup("keyFormat"); ok {
+			if attr != m.HashKey && attr != m.SortKey {
+				return nil, fmt.Errorf(`keyFormat tag on non-key field "%s"`, structField.Name)
+			}
+
+			if structField.Type.Kind() != reflect.String {
+				return nil, fmt.Errorf(`unsupported keyFormat field type "%s"`, structField.Type)
+			}
+
+			keyFormat, err := ParseKeyFormat(t, v)
+			if err != nil {
+				return nil, err
+			}
+
+			attr.KeyFormat = keyFormat
+		}
+
+		if v, ok := structField.Tag.Lookup("unique"); ok {
+			if v == "" {
+				return nil, fmt.Errorf(`empty unique tag on field "%s"`, structField.Name)
+			}
+
+			if !validKeyAttribute(structField) {
+				return nil, fmt.Errorf(`unsupported unique field type "%s"`, structField.Type)
+			}
+
+			attr.Unique = v
+			m.Uniques = append(m.Uniques, attr)
+		}
+
+		if err := m.parseIndexTags(attr, structField); err != nil {
+			return nil, err
+		}
+
+		if err := parseValidationTags(attr, structField); err != nil {
+			return nil, err
+		}
+
+		if attr.HasRules() {
+			m.Validated = append(m.Validated, attr)
+		}
+	}
+
+	if err := m.validateIndexes(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// addField adds the attribute of the given struct field with the options from the `dynamodbav` struct tag.
+func (m *Model) addField(structField reflect.StructField, name string, options []string) (*Attribute, error) {
+	attr := &Attribute{Name: name, Field: structField}
+	for _, option := range options {
+		switch option {
+		case "hashkey":
+			if m.HashKey != nil {
+				return nil, fmt.Errorf(`found multiple hashkey fields in type "%s"`, m.StructType.Name())
 			}
+
+			if !validKeyAttribute(structField) {
+				return nil, fmt.Errorf(`unsupported hashkey field type "%s"`, structField.Type)
+			}
+
+			m.HashKey = attr
+		case "sortkey":
+			if m.SortKey != nil {
+				return nil, fmt.Errorf(`found multiple sortkey fields in type "%s"`, m.StructType.Name())
+			}
+
+			if !validKeyAttribute(structField) {
+				return nil, fmt.Errorf(`unsupported sortkey field type "%s"`, structField.Type)
+			}
+
+			m.SortKey = attr
+		case "version":
+			if m.Version != nil {
+				return nil, fmt.Errorf(`found multiple version fields in type "%s"`, m.StructType.Name())
+			}
+
+			if !validVersionAttribute(structField) {
+				return nil, fmt.Errorf(`unsupported version field type "%s"`, structField.Type)
+			}
+
+			m.Version = attr
+		case "createdTime":
+			if m.CreatedTime != nil {
+				return nil, fmt.Errorf(`found multiple createdTime fields in type "%s"`, m.StructType.Name())
+			}
+
+			if !validTimeAttribute(structField) {
+				return nil, fmt.Errorf(`unsupported createdTime field type "%s"`, structField.Type)
+			}
+
+			m.CreatedTime = attr
+		case "modifiedTime":
+			if m.ModifiedTime != nil {
+				return nil, fmt.Errorf(`found multiple modifiedTime fields in type "%s"`, m.StructType.Name())
+			}
+
+			if !validTimeAttribute(structField) {
+				return nil, fmt.Errorf(`unsupported modifiedTime field type "%s"`, structField.Type)
+			}
+
+			m.ModifiedTime = attr
+		case "deletedTime":
+			if m.DeletedTime != nil {
+				return nil, fmt.Errorf(`found multiple deletedTime fields in type "%s"`, m.StructType.Name())
+			}
+
+			if !validTimeAttribute(structField) {
+				return nil, fmt.Errorf(`unsupported deletedTime field type "%s"`, structField.Type)
+			}
+
+			m.DeletedTime = attr
+		case "ttl":
+			if m.TTL != nil {
+				return nil, fmt.Errorf(`found multiple ttl fields in type "%s"`, m.StructType.Name())
+			}
+
+			if !validTimeAttribute(structField) && !validVersionAttribute(structField) {
+				return nil, fmt.Errorf(`unsupported ttl field type "%s"`, structField.Type)
+			}
+
+			m.TTL = attr
+		case "omitempty":
+			attr.OmitEmpty = true
+		case "unixtime":
+			attr.UnixTime = true
+		case "string":
+			attr.AsString = true
+		case "immutable":
+			attr.Immutable = true
+			m.Immutables = append(m.Immutables, attr)
+		case "required":
+			return nil, fmt.Errorf(`required must be its own struct tag on field "%s" such as required:"true"`, structField.Name)
+		}
+
+		switch option {
+		case "omitempty", "omitemptyelem", "nullempty", "nullemptyelem", "stringset", "numberset", "binaryset", "unixtime":
+			attr.EncoderOptions = true
+		}
+	}
+
+	return attr, nil
+}
+
+// Field describes a struct field that has been parsed ahead of time, such as by code generation.
+type Field struct {
+	// Name is the name of the Go struct field.
+	Name string
+	// Attribute is the name of the DynamoDB attribute.
+	Attribute string
+	// Options are the options that would have followed the attribute name in the `dynamodbav` struct tag.
+	Options []string
+}
+
+// NewModel creates the Model of the given struct type from fields that have been parsed ahead of time.
+//
+// Unlike ParseFromType, the struct tags are not parsed; only the fields named by the given fields are looked up.
+func NewModel(t reflect.Type, tableName *string, fields []Field) (*Model, error) {
+	t = DereferencedType(t)
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf(`type "%s" is not a struct`, t)
+	}
+
+	m := &Model{StructType: t, TableName: tableName}
+	for _, field := range fields {
+		structField, ok := t.FieldByName(field.Name)
+		if !ok {
+			return nil, fmt.Errorf(`no field "%s" in type "%s"`, field.Name, t.Name())
+		}
+
+		if _, err := m.addField(structField, field.Attribute, field.Options); err != nil {
+			return nil, err
 		}
 	}
 
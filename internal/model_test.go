@@ -37,3 +37,54 @@ func TestParse(t *testing.T) {
 
 	assert.Equal(t, a, c)
 }
+
+// validation tags on fields of unsupported types are ignored instead of failing the model.
+func TestParseValidationTagsUnsupportedType(t *testing.T) {
+	type Test struct {
+		Id        string    `dynamodbav:",hashkey" tableName:""`
+		CreatedAt time.Time `dynamodbav:"createdAt" min:"1" maxLength:"10" pattern:"^a"`
+		Count     int       `dynamodbav:"count" min:"1"`
+	}
+
+	m, err := ParseFromType(reflect.TypeFor[Test]())
+	if err != nil {
+		t.Errorf("ParseFromType() error: %v", err)
+		return
+	}
+
+	assert.Equal(t, 1, len(m.Validated))
+	assert.Equal(t, "count", m.Validated[0].Name)
+}
+
+// required is its own struct tag like the other validation rules.
+func TestParseRequiredTag(t *testing.T) {
+	type Test struct {
+		Id    string `dynamodbav:",hashkey" tableName:""`
+		Email string `dynamodbav:"email" required:"true"`
+		Notes string `dynamodbav:"notes" required:"false"`
+	}
+
+	m, err := ParseFromType(reflect.TypeFor[Test]())
+	if err != nil {
+		t.Errorf("ParseFromType() error: %v", err)
+		return
+	}
+
+	assert.Equal(t, 1, len(m.Validated))
+	assert.Equal(t, "email", m.Validated[0].Name)
+	assert.True(t, m.Validated[0].Required)
+
+	type Invalid struct {
+		Id    string `dynamodbav:",hashkey" tableName:""`
+		Email string `dynamodbav:"email" required:"yes"`
+	}
+	_, err = ParseFromType(reflect.TypeFor[Invalid]())
+	assert.ErrorContains(t, err, "invalid required tag")
+
+	type Option struct {
+		Id    string `dynamodbav:",hashkey" tableName:""`
+		Email string `dynamodbav:"email,required"`
+	}
+	_, err = ParseFromType(reflect.TypeFor[Option]())
+	assert.ErrorContains(t, err, "required must be its own struct tag")
+}
@@ -0,0 +1,188 @@
+package internal
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parseValidationTags parses the `required`, `min`, `max`, `maxLength`, `pattern`, and `oneof` struct tags.
+//
+// Tags on fields of types they don't apply to are ignored since the same tag names may be used by other libraries, but
+// tags with invalid values are errors.
+func parseValidationTags(attr *Attribute, structField reflect.StructField) error {
+	ft := DereferencedType(structField.Type)
+
+	if v, ok := structField.Tag.Lookup("required"); ok {
+		required, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf(`invalid required tag on field "%s": %w`, structField.Name, err)
+		}
+
+		attr.Required = required
+	}
+
+	for _, key := range []string{"min", "max"} {
+		v, ok := structField.Tag.Lookup(key)
+		if !ok {
+			continue
+		}
+
+		if !isNumeric(ft.Kind()) {
+			continue
+		}
+
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf(`invalid %s tag on field "%s": %w`, key, structField.Name, err)
+		}
+
+		if key == "min" {
+			attr.Min = &f
+		} else {
+			attr.Max = &f
+		}
+	}
+
+	if v, ok := structField.Tag.Lookup("maxLength"); ok && hasLength(ft.Kind()) {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf(`invalid maxLength tag on field "%s": %w`, structField.Name, err)
+		}
+
+		attr.MaxLength = &n
+	}
+
+	if v, ok := structField.Tag.Lookup("pattern"); ok && ft.Kind() == reflect.String {
+		re, err := regexp.Compile(v)
+		if err != nil {
+			return fmt.Errorf(`invalid pattern tag on field "%s": %w`, structField.Name, err)
+		}
+
+		attr.Pattern = re
+	}
+
+	if v, ok := structField.Tag.Lookup("oneof"); ok && (ft.Kind() == reflect.String || isNumeric(ft.Kind())) {
+		attr.OneOf = strings.Fields(v)
+	}
+
+	return nil
+}
+
+// HasRules returns true if the attribute has at least one validation rule.
+func (a *Attribute) HasRules() bool {
+	return a.Required || a.Min != nil || a.Max != nil || a.MaxLength != nil || a.Pattern != nil || len(a.OneOf) != 0
+}
+
+// Validate checks the given attribute value against the validation rules.
+//
+// Returns the reasons why the value fails validation. Empty return value means the value passes validation. Other than
+// `required`, rules are not checked against nil pointers, empty strings, or empty collections.
+func (a *Attribute) Validate(v reflect.Value) (reasons []string) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if a.Required {
+				return []string{"required attribute is missing"}
+			}
+			return nil
+		}
+
+		v = v.Elem()
+	}
+
+	switch k := v.Kind(); {
+	case k == reflect.String || k == reflect.Slice || k == reflect.Map:
+		if v.Len() == 0 {
+			if a.Required {
+				return []string{"required attribute is empty"}
+			}
+			return nil
+		}
+	case k == reflect.Invalid:
+		if a.Required {
+			return []string{"required attribute is missing"}
+		}
+		return nil
+	case !isNumeric(k) && a.Required && v.IsZero():
+		return []string{"required attribute is empty"}
+	}
+
+	if a.Min != nil || a.Max != nil {
+		if f, ok := toFloat(v); ok {
+			if a.Min != nil && f < *a.Min {
+				reasons = append(reasons, fmt.Sprintf("value %v is less than min %v", f, *a.Min))
+			}
+			if a.Max != nil && f > *a.Max {
+				reasons = append(reasons, fmt.Sprintf("value %v is greater than max %v", f, *a.Max))
+			}
+		}
+	}
+
+	if a.MaxLength != nil {
+		switch v.Kind() {
+		case reflect.String:
+			if n := len([]rune(v.String())); n > *a.MaxLength {
+				reasons = append(reasons, fmt.Sprintf("length %d is greater than maxLength %d", n, *a.MaxLength))
+			}
+		case reflect.Slice, reflect.Map, reflect.Array:
+			if n := v.Len(); n > *a.MaxLength {
+				reasons = append(reasons, fmt.Sprintf("length %d is greater than maxLength %d", n, *a.MaxLength))
+			}
+		}
+	}
+
+	if a.Pattern != nil && v.Kind() == reflect.String && !a.Pattern.MatchString(v.String()) {
+		reasons = append(reasons, fmt.Sprintf("value %q does not match pattern %q", v.String(), a.Pattern.String()))
+	}
+
+	if len(a.OneOf) != 0 {
+		var s string
+		if v.Kind() == reflect.String {
+			s = v.String()
+		} else if f, ok := toFloat(v); ok {
+			s = strconv.FormatFloat(f, 'f', -1, 64)
+		}
+
+		if !slices.Contains(a.OneOf, s) {
+			reasons = append(reasons, fmt.Sprintf("value %q is not one of [%s]", s, strings.Join(a.OneOf, " ")))
+		}
+	}
+
+	return reasons
+}
+
+func isNumeric(k reflect.Kind) bool {
+	switch k {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	default:
+		return false
+	}
+}
+
+func hasLength(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Slice, reflect.Map, reflect.Array:
+		return true
+	default:
+		return false
+	}
+}
+
+func toFloat(v reflect.Value) (float64, bool) {
+	switch {
+	case v.CanInt():
+		return float64(v.Int()), true
+	case v.CanUint():
+		return float64(v.Uint()), true
+	case v.CanFloat():
+		return v.Float(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,185 @@
+package ddbfns
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// KeyBuilder can be implemented by the struct to compute its key attributes from other fields.
+//
+// This is useful in single-table designs where the hash and sort keys are composites such as `USER#123` and
+// `ORDER#2024-01-01#abc`. [Fns.Put], [Fns.Get], [Fns.Update], and [Fns.Delete] will encode the returned values as the
+// hash and sort key attributes instead of the values of the hashkey and sortkey fields. The sortKey return value is
+// ignored if the struct has no sortkey field.
+//
+// For simple templates, prefer the `keyFormat` struct tag which also allows the composite keys to be parsed back into
+// their component fields on decoding:
+//
+//	type Order struct {
+//		PK      string `dynamodbav:"pk,hashkey" tableName:"my-table" keyFormat:"USER#{UserID}"`
+//		SK      string `dynamodbav:"sk,sortkey" keyFormat:"ORDER#{Date}#{OrderID}"`
+//		UserID  string `dynamodbav:"userId"`
+//		Date    string `dynamodbav:"date"`
+//		OrderID string `dynamodbav:"orderId"`
+//	}
+type KeyBuilder interface {
+	DynamoDBKeys() (hashKey, sortKey interface{})
+}
+
+// computeKeys sets the key attributes in item that are computed from either KeyBuilder or `keyFormat` struct tags.
+func (f *Fns) computeKeys(m *internal.Model, v interface{}, item map[string]types.AttributeValue) error {
+	_, kb, _ := shallowCopy[KeyBuilder](v)
+	return f.computeKeysWith(m, v, kb, item)
+}
+
+// computeKeysWith is a variant of computeKeys for callers that already have the KeyBuilder of v, which is nil if v
+// doesn't implement KeyBuilder.
+func (f *Fns) computeKeysWith(m *internal.Model, v interface{}, kb KeyBuilder, item map[string]types.AttributeValue) error {
+	if kb != nil {
+		hashKey, sortKey := kb.DynamoDBKeys()
+
+		av, err := f.Encoder.Encode(hashKey)
+		if err != nil {
+			return fmt.Errorf("encode hashkey error: %w", err)
+		}
+		item[m.HashKey.Name] = av
+
+		if m.SortKey != nil {
+			if av, err = f.Encoder.Encode(sortKey); err != nil {
+				return fmt.Errorf("encode sortkey error: %w", err)
+			}
+			item[m.SortKey.Name] = av
+		}
+
+		return nil
+	}
+
+	iv := reflect.Indirect(reflect.ValueOf(v))
+	for _, attr := range []*internal.Attribute{m.HashKey, m.SortKey} {
+		if attr == nil || attr.KeyFormat == nil {
+			continue
+		}
+
+		s, err := attr.KeyFormat.Format(iv)
+		if err != nil {
+			return fmt.Errorf("format %s error: %w", attr.Name, err)
+		}
+
+		item[attr.Name] = &types.AttributeValueMemberS{Value: s}
+	}
+
+	return nil
+}
+
+// encodeKey returns the key attributes (hash key and optional sort key) of the given struct value.
+//
+// Only the key fields are encoded (or none at all if they are computed) so that the cost doesn't scale with the size of
+// the struct. If a key field has other encoding options than `string` in its `dynamodbav` tag, the whole struct is
+// encoded instead so that the options are applied exactly as f.Encoder would.
+func (f *Fns) encodeKey(m *internal.Model, v interface{}) (map[string]types.AttributeValue, error) {
+	key := make(map[string]types.AttributeValue, 2)
+
+	_, kb, ok := shallowCopy[KeyBuilder](v)
+	if !ok {
+		var item map[string]types.AttributeValue
+		for _, attr := range []*internal.Attribute{m.HashKey, m.SortKey} {
+			if attr != nil && attr.KeyFormat == nil && attr.EncoderOptions {
+				av, err := f.Encoder.Encode(v)
+				if err != nil {
+					return nil, err
+				}
+
+				asMap, ok := av.(*types.AttributeValueMemberM)
+				if !ok {
+					return nil, fmt.Errorf("item did not encode to M type")
+				}
+
+				item = asMap.Value
+				break
+			}
+		}
+
+		iv := reflect.Indirect(reflect.ValueOf(v))
+		for _, attr := range []*internal.Attribute{m.HashKey, m.SortKey} {
+			if attr == nil || attr.KeyFormat != nil {
+				continue
+			}
+
+			if item != nil {
+				if av, ok := item[attr.Name]; ok {
+					key[attr.Name] = av
+				}
+				continue
+			}
+
+			av, err := f.encodeField(attr, iv)
+			if err != nil {
+				return nil, err
+			}
+
+			key[attr.Name] = av
+		}
+	}
+
+	if err := f.computeKeysWith(m, v, kb, key); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// encodeField encodes the attribute's field of the given struct value the same way f.Encoder would when encoding the
+// whole struct.
+func (f *Fns) encodeField(attr *internal.Attribute, iv reflect.Value) (types.AttributeValue, error) {
+	fv, err := attr.Get(iv)
+	if err != nil {
+		return nil, fmt.Errorf("get %s value error: %w", attr.Name, err)
+	}
+
+	av, err := f.Encoder.Encode(fv.Interface())
+	if err != nil {
+		return nil, fmt.Errorf("encode %s error: %w", attr.Name, err)
+	}
+
+	if n, ok := av.(*types.AttributeValueMemberN); ok && attr.AsString {
+		return &types.AttributeValueMemberS{Value: n.Value}, nil
+	}
+
+	return av, nil
+}
+
+// parseKeys parses the composite key fields of the decoded struct pointer back into their component fields.
+func (f *Fns) parseKeys(out interface{}) error {
+	t := reflect.TypeOf(out)
+	if t == nil || t.Kind() != reflect.Pointer || internal.DereferencedType(t).Kind() != reflect.Struct {
+		return nil
+	}
+
+	m, err := f.loadOrParse(t)
+	if err != nil {
+		return err
+	}
+
+	ov := reflect.Indirect(reflect.ValueOf(out))
+	for _, attr := range []*internal.Attribute{m.HashKey, m.SortKey} {
+		if attr == nil || attr.KeyFormat == nil {
+			continue
+		}
+
+		fv, err := attr.Get(ov)
+		if err != nil {
+			return fmt.Errorf("get %s value error: %w", attr.Name, err)
+		}
+
+		if s := fv.String(); s != "" {
+			if err = attr.KeyFormat.Parse(s, ov); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package ddbfns
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type keyFormatTest struct {
+	PK      string `dynamodbav:"pk,hashkey" tableName:"" keyFormat:"USER#{UserID}"`
+	SK      string `dynamodbav:"sk,sortkey" keyFormat:"ORDER#{Date}#{OrderID}"`
+	UserID  string `dynamodbav:"userId"`
+	Date    string `dynamodbav:"date"`
+	OrderID int64  `dynamodbav:"orderId"`
+}
+
+type keyBuilderTest struct {
+	PK     string `dynamodbav:"pk,hashkey" tableName:""`
+	UserID string `dynamodbav:"-"`
+}
+
+func (k keyBuilderTest) DynamoDBKeys() (hashKey, sortKey interface{}) {
+	return "USER#" + k.UserID, nil
+}
+
+func TestFns_KeyFormat(t *testing.T) {
+	input := keyFormatTest{UserID: "123", Date: "2024-01-01", OrderID: 42}
+
+	// this is to make sure the input item is not mutated.
+	before := MustToJSON(input)
+
+	putItemInput, err := Put(input)
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+	assert.JSONEq(t, before, MustToJSON(input))
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "USER#123"}, putItemInput.Item["pk"])
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "ORDER#2024-01-01#42"}, putItemInput.Item["sk"])
+
+	getItemInput, err := Get(&input)
+	if err != nil {
+		t.Errorf("Get() error = %v", err)
+		return
+	}
+	assert.Equal(t, map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "USER#123"},
+		"sk": &types.AttributeValueMemberS{Value: "ORDER#2024-01-01#42"},
+	}, getItemInput.Key)
+
+	// decoding will parse the composite keys back into their component fields.
+	var out keyFormatTest
+	err = DefaultFns.decode(map[string]types.AttributeValue{
+		"pk": &types.AttributeValueMemberS{Value: "USER#456"},
+		"sk": &types.AttributeValueMemberS{Value: "ORDER#2025-02-03#7"},
+	}, &out)
+	assert.NoError(t, err)
+	assert.Equal(t, keyFormatTest{PK: "USER#456", SK: "ORDER#2025-02-03#7", UserID: "456", Date: "2025-02-03", OrderID: 7}, out)
+
+	err = DefaultFns.decode(map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "ACCOUNT#456"}}, &out)
+	assert.Error(t, err)
+}
+
+func TestFns_KeyBuilder(t *testing.T) {
+	got, err := Delete(keyBuilderTest{UserID: "123"})
+	if err != nil {
+		t.Errorf("Delete() error = %v", err)
+		return
+	}
+	assert.Equal(t, map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "USER#123"}}, got.Key)
+}
+
+func TestFns_EncodeKeyMatchesEncoder(t *testing.T) {
+	type Test struct {
+		Id   int64  `dynamodbav:"id,hashkey,string" tableName:""`
+		Sort []byte `dynamodbav:"sort,sortkey"`
+	}
+
+	f := &Fns{}
+	f.init.Do(f.initFn)
+	v := Test{Id: 42, Sort: []byte("hello")}
+
+	m, err := f.loadOrParse(reflect.TypeOf(v))
+	if err != nil {
+		t.Errorf("loadOrParse() error = %v", err)
+		return
+	}
+
+	key, err := f.encodeKey(m, v)
+	if err != nil {
+		t.Errorf("encodeKey() error = %v", err)
+		return
+	}
+
+	av, err := f.Encoder.Encode(v)
+	if err != nil {
+		t.Errorf("Encode() error = %v", err)
+		return
+	}
+	assert.Equal(t, av.(*types.AttributeValueMemberM).Value, key)
+}
+
+func TestFns_EncodeKeyEncoderOptions(t *testing.T) {
+	type Test struct {
+		Id   string `dynamodbav:"id,hashkey,nullempty" tableName:""`
+		Sort string `dynamodbav:"sort,sortkey"`
+	}
+
+	f := &Fns{}
+	f.init.Do(f.initFn)
+	v := Test{Sort: "world"}
+
+	m, err := f.loadOrParse(reflect.TypeOf(v))
+	if err != nil {
+		t.Errorf("loadOrParse() error = %v", err)
+		return
+	}
+
+	key, err := f.encodeKey(m, v)
+	if err != nil {
+		t.Errorf("encodeKey() error = %v", err)
+		return
+	}
+
+	// nullempty must be honoured the same way the encoder does for the whole struct.
+	av, err := f.Encoder.Encode(v)
+	if err != nil {
+		t.Errorf("Encode() error = %v", err)
+		return
+	}
+	assert.Equal(t, av.(*types.AttributeValueMemberM).Value, key)
+	assert.Equal(t, &types.AttributeValueMemberNULL{Value: true}, key["id"])
+}
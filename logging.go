@@ -0,0 +1,76 @@
+package ddbfns
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// SlogMiddleware returns a [Middleware] that logs every DynamoDB request to the given logger.
+//
+// Each record has the operation, table, index (if any), key (if any), duration, and the consumed capacity units if the
+// request's ReturnConsumedCapacity is set. Requests with a condition expression also have a "condition" attribute that is
+// either "passed" or "failed".
+//
+// Successful requests and requests whose condition failed are logged at [slog.LevelInfo], while other errors are
+// logged at [slog.LevelError] with an "error" attribute. If logger is nil, [slog.Default] is used.
+func SlogMiddleware(logger *slog.Logger) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (interface{}, error) {
+			l := logger
+			if l == nil {
+				l = slog.Default()
+			}
+
+			start := time.Now()
+			output, err := next(ctx, req)
+
+			attrs := []slog.Attr{
+				slog.String("operation", req.Operation),
+				slog.String("table", strings.Join(req.TableNames, ",")),
+			}
+			if req.IndexName != "" {
+				attrs = append(attrs, slog.String("index", req.IndexName))
+			}
+			if len(req.Key) != 0 {
+				attrs = append(attrs, slog.String("key", explainItem(req.Key)))
+			}
+
+			level := slog.LevelInfo
+			conditionFailed := IsConditionalCheckFailed(err)
+			if req.HasCondition() || conditionFailed {
+				if conditionFailed {
+					attrs = append(attrs, slog.String("condition", "failed"))
+				} else if err == nil {
+					attrs = append(attrs, slog.String("condition", "passed"))
+				}
+			}
+			if cc := ConsumedCapacity(output); len(cc) != 0 {
+				attrs = append(attrs, slog.Float64("consumed_capacity", totalCapacityUnits(cc)))
+			}
+			attrs = append(attrs, slog.Duration("duration", time.Since(start)))
+			if err != nil && !conditionFailed {
+				level = slog.LevelError
+				attrs = append(attrs, slog.String("error", err.Error()))
+			}
+
+			l.LogAttrs(ctx, level, "dynamodb "+req.Operation, attrs...)
+			return output, err
+		}
+	}
+}
+
+// totalCapacityUnits sums the capacity units across all the tables of a request.
+func totalCapacityUnits(cc []types.ConsumedCapacity) float64 {
+	var total float64
+	for _, c := range cc {
+		if c.CapacityUnits != nil {
+			total += *c.CapacityUnits
+		}
+	}
+
+	return total
+}
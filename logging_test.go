@@ -0,0 +1,59 @@
+package ddbfns
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogMiddleware(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey || a.Key == "duration" {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		switch operation {
+		case "GetItem":
+			return 200, `{"Item":{"id":{"S":"hello"},"sort":{"S":"world"}},"ConsumedCapacity":{"TableName":"my-table","CapacityUnits":0.5}}`
+		case "UpdateItem":
+			return 200, `{}`
+		case "PutItem":
+			return 400, conditionalCheckFailedBody
+		default:
+			return 500, `{"__type":"com.amazonaws.dynamodb.v20120810#InternalServerError","message":"oops"}`
+		}
+	})
+
+	f := &Fns{}
+	f.Use(SlogMiddleware(logger))
+
+	item := middlewareTest{Id: "hello", Sort: "world", Version: 1}
+	_, err := f.DoGet(context.Background(), client, item, func(opts *GetOpts) {
+		opts.ReturnConsumedCapacity = types.ReturnConsumedCapacityTotal
+	})
+	assert.NoError(t, err)
+	_, err = f.DoUpdate(context.Background(), client, item, func(opts *UpdateOpts) {
+		opts.Set("notes", "hi")
+	})
+	assert.NoError(t, err)
+	_, err = f.DoPut(context.Background(), client, item)
+	assert.True(t, IsConditionalCheckFailed(err))
+	_, err = f.DoDelete(context.Background(), client, item)
+	assert.Error(t, err)
+
+	assert.Equal(t, `{"level":"INFO","msg":"dynamodb GetItem","operation":"GetItem","table":"my-table","key":"{id: \"hello\", sort: \"world\"}","consumed_capacity":0.5}
+{"level":"INFO","msg":"dynamodb UpdateItem","operation":"UpdateItem","table":"my-table","key":"{id: \"hello\", sort: \"world\"}","condition":"passed"}
+{"level":"INFO","msg":"dynamodb PutItem","operation":"PutItem","table":"my-table","key":"{id: \"hello\", sort: \"world\"}","condition":"failed"}
+{"level":"ERROR","msg":"dynamodb DeleteItem","operation":"DeleteItem","table":"my-table","key":"{id: \"hello\", sort: \"world\"}","error":"operation error DynamoDB: DeleteItem, https response error StatusCode: 500, RequestID: , InternalServerError: oops"}
+`, buf.String())
+}
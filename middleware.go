@@ -0,0 +1,275 @@
+package ddbfns
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Request describes a DynamoDB call that is passed through the [Middleware] chain of [Fns].
+type Request struct {
+	// Operation is the name of the DynamoDB API such as "GetItem" or "Query".
+	Operation string
+	// TableNames are the distinct names of the tables targeted by the request in order of appearance.
+	//
+	// There is only one table except for TransactWriteItems.
+	TableNames []string
+	// IndexName is the name of the index of Query and Scan requests.
+	IndexName string
+	// Key is the primary key of the item targeted by GetItem, PutItem, UpdateItem, and DeleteItem requests.
+	Key map[string]types.AttributeValue
+	// Input is the request input such as *dynamodb.GetItemInput.
+	//
+	// Middleware may replace Input before calling the next Handler, but the replacement must be of the same type.
+	Input interface{}
+}
+
+// HasCondition returns true if the request has a condition expression, or any of the actions of a TransactWriteItems
+// request does.
+func (r *Request) HasCondition() bool {
+	switch input := r.Input.(type) {
+	case *dynamodb.PutItemInput:
+		return input.ConditionExpression != nil
+	case *dynamodb.UpdateItemInput:
+		return input.ConditionExpression != nil
+	case *dynamodb.DeleteItemInput:
+		return input.ConditionExpression != nil
+	case *dynamodb.TransactWriteItemsInput:
+		for _, item := range input.TransactItems {
+			switch {
+			case item.ConditionCheck != nil,
+				item.Put != nil && item.Put.ConditionExpression != nil,
+				item.Update != nil && item.Update.ConditionExpression != nil,
+				item.Delete != nil && item.Delete.ConditionExpression != nil:
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Handler executes a DynamoDB request and returns its output such as *dynamodb.GetItemOutput.
+type Handler func(ctx context.Context, req *Request) (interface{}, error)
+
+// Middleware wraps a Handler to intercept DynamoDB requests.
+//
+// The Middleware may inspect or replace the request before calling next, inspect the output and error after, or
+// short-circuit the call entirely by not calling next at all.
+type Middleware func(next Handler) Handler
+
+// Use appends the middleware to the chain that wraps every DynamoDB call made by Fns, such as [Fns.DoGet], [Fns.DoPut],
+// [Fns.DoUpdate], [Fns.DoDelete], [Fns.DoTransactPut], and each page of [Fns.DoQuery] and [Fns.DoScan].
+//
+// The first middleware is the outermost one. Use is not safe to call concurrently with requests; add all middleware
+// when setting up Fns.
+func (f *Fns) Use(mw ...Middleware) {
+	f.middlewares = append(f.middlewares, mw...)
+}
+
+// invoke passes the request through the middleware chain before executing it with call.
+//
+// If the context has a CapacityTracker, the budget is checked before the request and the consumed capacity is added to
+// the tracker after. If Fns.Cache is given, the entries of the written items are refreshed or invalidated after.
+func invoke[In, Out any](ctx context.Context, f *Fns, req *Request, call func(context.Context, In, ...func(*dynamodb.Options)) (Out, error), optFns []func(*dynamodb.Options)) (out Out, err error) {
+	tracker, ok := CapacityTrackerFromContext(ctx)
+	if ok {
+		if err = tracker.checkBudget(); err != nil {
+			return out, err
+		}
+
+		req.Input = withReturnConsumedCapacity(req.Input)
+		defer func() {
+			tracker.add(req.Operation, ConsumedCapacity(out))
+		}()
+	}
+
+	if f.Cache != nil {
+		defer func() {
+			f.updateCache(req, out, err)
+		}()
+	}
+
+	if len(f.middlewares) == 0 {
+		return call(ctx, req.Input.(In), optFns...)
+	}
+
+	h := Handler(func(ctx context.Context, req *Request) (interface{}, error) {
+		return call(ctx, req.Input.(In), optFns...)
+	})
+	for i := len(f.middlewares) - 1; i >= 0; i-- {
+		h = f.middlewares[i](h)
+	}
+
+	v, err := h(ctx, req)
+	out, _ = v.(Out)
+	return out, err
+}
+
+// newRequest describes the given input.
+func newRequest(input interface{}) *Request {
+	r := &Request{Input: input}
+	switch input := input.(type) {
+	case *dynamodb.GetItemInput:
+		r.Operation, r.Key = "GetItem", input.Key
+		r.addTableName(input.TableName)
+	case *dynamodb.PutItemInput:
+		r.Operation = "PutItem"
+		r.addTableName(input.TableName)
+	case *dynamodb.UpdateItemInput:
+		r.Operation, r.Key = "UpdateItem", input.Key
+		r.addTableName(input.TableName)
+	case *dynamodb.DeleteItemInput:
+		r.Operation, r.Key = "DeleteItem", input.Key
+		r.addTableName(input.TableName)
+	case *dynamodb.QueryInput:
+		r.Operation = "Query"
+		r.addTableName(input.TableName)
+		if input.IndexName != nil {
+			r.IndexName = *input.IndexName
+		}
+	case *dynamodb.ScanInput:
+		r.Operation = "Scan"
+		r.addTableName(input.TableName)
+		if input.IndexName != nil {
+			r.IndexName = *input.IndexName
+		}
+	case *dynamodb.TransactWriteItemsInput:
+		r.Operation = "TransactWriteItems"
+		for _, item := range input.TransactItems {
+			switch {
+			case item.ConditionCheck != nil:
+				r.addTableName(item.ConditionCheck.TableName)
+			case item.Put != nil:
+				r.addTableName(item.Put.TableName)
+			case item.Update != nil:
+				r.addTableName(item.Update.TableName)
+			case item.Delete != nil:
+				r.addTableName(item.Delete.TableName)
+			}
+		}
+	}
+
+	return r
+}
+
+func (r *Request) addTableName(tableName *string) {
+	if tableName == nil {
+		return
+	}
+
+	for _, name := range r.TableNames {
+		if name == *tableName {
+			return
+		}
+	}
+
+	r.TableNames = append(r.TableNames, *tableName)
+}
+
+// ConsumedCapacity returns the consumed capacity of the given output such as *dynamodb.GetItemOutput.
+//
+// Returns nil if the output has no consumed capacity, which is the case unless the request's ReturnConsumedCapacity is
+// set.
+func ConsumedCapacity(output interface{}) []types.ConsumedCapacity {
+	var cc *types.ConsumedCapacity
+	switch output := output.(type) {
+	case *dynamodb.GetItemOutput:
+		if output != nil {
+			cc = output.ConsumedCapacity
+		}
+	case *dynamodb.PutItemOutput:
+		if output != nil {
+			cc = output.ConsumedCapacity
+		}
+	case *dynamodb.UpdateItemOutput:
+		if output != nil {
+			cc = output.ConsumedCapacity
+		}
+	case *dynamodb.DeleteItemOutput:
+		if output != nil {
+			cc = output.ConsumedCapacity
+		}
+	case *dynamodb.QueryOutput:
+		if output != nil {
+			cc = output.ConsumedCapacity
+		}
+	case *dynamodb.ScanOutput:
+		if output != nil {
+			cc = output.ConsumedCapacity
+		}
+	case *dynamodb.TransactWriteItemsOutput:
+		if output != nil {
+			return output.ConsumedCapacity
+		}
+	}
+
+	if cc == nil {
+		return nil
+	}
+
+	return []types.ConsumedCapacity{*cc}
+}
+
+// IsConditionalCheckFailed returns true if the error is a [types.ConditionalCheckFailedException], or a
+// [types.TransactionCanceledException] where at least one of the cancellation reasons is a failed condition.
+func IsConditionalCheckFailed(err error) bool {
+	var ccfe *types.ConditionalCheckFailedException
+	if errors.As(err, &ccfe) {
+		return true
+	}
+
+	var tce *types.TransactionCanceledException
+	if errors.As(err, &tce) {
+		for _, reason := range tce.CancellationReasons {
+			if reason.Code != nil && *reason.Code == "ConditionalCheckFailed" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// queryClient passes each Query request through [invoke].
+type queryClient struct {
+	f      *Fns
+	client dynamodb.QueryAPIClient
+}
+
+func (c queryClient) Query(ctx context.Context, params *dynamodb.QueryInput, optFns ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	return invoke(ctx, c.f, newRequest(params), c.client.Query, optFns)
+}
+
+// scanClient passes each Scan request through [invoke].
+type scanClient struct {
+	f      *Fns
+	client dynamodb.ScanAPIClient
+}
+
+func (c scanClient) Scan(ctx context.Context, params *dynamodb.ScanInput, optFns ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return invoke(ctx, c.f, newRequest(params), c.client.Scan, optFns)
+}
+
+// queryClient returns the client that paginators should use so that every page goes through [invoke].
+func (f *Fns) queryClient(client dynamodb.QueryAPIClient) dynamodb.QueryAPIClient {
+	return queryClient{f: f, client: client}
+}
+
+// scanClient is the Scan variant of [Fns.queryClient].
+func (f *Fns) scanClient(client dynamodb.ScanAPIClient) dynamodb.ScanAPIClient {
+	return scanClient{f: f, client: client}
+}
+
+// newPutRequest is a variant of newRequest that also sets the Key from the item.
+func (f *Fns) newPutRequest(v interface{}, input *dynamodb.PutItemInput) *Request {
+	r := newRequest(input)
+	if attrs, err := f.loadOrParse(reflect.TypeOf(v)); err == nil {
+		r.Key = itemKey(attrs, input.Item)
+	}
+
+	return r
+}
@@ -0,0 +1,166 @@
+package ddbfns
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type middlewareTest struct {
+	Id      string `dynamodbav:"id,hashkey" tableName:"my-table"`
+	Sort    string `dynamodbav:"sort,sortkey"`
+	Version int64  `dynamodbav:"version,version"`
+	Notes   string `dynamodbav:"notes"`
+}
+
+// fakeHTTPClient responds to DynamoDB requests with the status code and JSON body returned by fn, which receives the
+// operation name such as "GetItem".
+type fakeHTTPClient func(operation string, body []byte) (int, string)
+
+func (fn fakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	_, operation, _ := strings.Cut(req.Header.Get("X-Amz-Target"), ".")
+	statusCode, respBody := fn(operation, body)
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(bytes.NewBufferString(respBody)),
+		Request:    req,
+	}, nil
+}
+
+// newFakeClient creates a DynamoDB client that never makes network calls.
+func newFakeClient(fn fakeHTTPClient) *dynamodb.Client {
+	return dynamodb.New(dynamodb.Options{
+		Region:       "us-west-2",
+		BaseEndpoint: aws.String("http://localhost:8000"),
+		Credentials:  aws.AnonymousCredentials{},
+		HTTPClient:   fn,
+		Retryer:      aws.NopRetryer{},
+	})
+}
+
+const conditionalCheckFailedBody = `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`
+
+func TestFns_Use(t *testing.T) {
+	var calls []string
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		calls = append(calls, "client "+operation)
+		return 200, `{"Item":{"id":{"S":"hello"},"sort":{"S":"world"},"notes":{"S":"hi"}}}`
+	})
+
+	var requests []*Request
+	f := &Fns{}
+	f.Use(func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (interface{}, error) {
+			calls = append(calls, "outer")
+			requests = append(requests, req)
+			return next(ctx, req)
+		}
+	}, func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (interface{}, error) {
+			calls = append(calls, "inner")
+			return next(ctx, req)
+		}
+	})
+
+	got := middlewareTest{}
+	_, err := f.DoGet(context.Background(), client, middlewareTest{Id: "hello", Sort: "world"}, func(opts *GetOpts) {
+		opts.Decode(&got)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"outer", "inner", "client GetItem"}, calls)
+	assert.Equal(t, middlewareTest{Id: "hello", Sort: "world", Notes: "hi"}, got)
+	if assert.Len(t, requests, 1) {
+		assert.Equal(t, "GetItem", requests[0].Operation)
+		assert.Equal(t, []string{"my-table"}, requests[0].TableNames)
+		assert.Equal(t, map[string]types.AttributeValue{
+			"id":   &types.AttributeValueMemberS{Value: "hello"},
+			"sort": &types.AttributeValueMemberS{Value: "world"},
+		}, requests[0].Key)
+		assert.False(t, requests[0].HasCondition())
+	}
+}
+
+func TestFns_Use_shortCircuit(t *testing.T) {
+	f := &Fns{}
+	f.Use(func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (interface{}, error) {
+			assert.Equal(t, "PutItem", req.Operation)
+			assert.True(t, req.HasCondition())
+			assert.Equal(t, map[string]types.AttributeValue{
+				"id":   &types.AttributeValueMemberS{Value: "hello"},
+				"sort": &types.AttributeValueMemberS{Value: "world"},
+			}, req.Key)
+			return &dynamodb.PutItemOutput{ConsumedCapacity: &types.ConsumedCapacity{CapacityUnits: aws.Float64(1)}}, nil
+		}
+	})
+
+	// the client is never called.
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		t.Errorf("unexpected call to %s", operation)
+		return 500, "{}"
+	})
+
+	output, err := f.DoPut(context.Background(), client, middlewareTest{Id: "hello", Sort: "world"})
+	assert.NoError(t, err)
+	assert.Equal(t, []types.ConsumedCapacity{{CapacityUnits: aws.Float64(1)}}, ConsumedCapacity(output))
+}
+
+func TestFns_Use_DoQuery(t *testing.T) {
+	f := &Fns{}
+	assert.NoError(t, RegisterType[middlewareTest](f, "middlewareTest"))
+
+	pages := 0
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		pages++
+		if pages == 1 {
+			return 200, `{"Items":[{"_type":{"S":"middlewareTest"},"id":{"S":"hello"},"sort":{"S":"a"}}],"LastEvaluatedKey":{"id":{"S":"hello"},"sort":{"S":"a"}}}`
+		}
+		return 200, `{"Items":[{"_type":{"S":"middlewareTest"},"id":{"S":"hello"},"sort":{"S":"b"}}]}`
+	})
+
+	var operations []string
+	f.Use(func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (interface{}, error) {
+			operations = append(operations, req.Operation+" "+req.IndexName)
+			return next(ctx, req)
+		}
+	})
+
+	var sorts []string
+	err := f.DoQuery(context.Background(), client, &dynamodb.QueryInput{TableName: aws.String("my-table"), IndexName: aws.String("my-index")}, func(v interface{}) error {
+		sorts = append(sorts, v.(*middlewareTest).Sort)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, sorts)
+	assert.Equal(t, []string{"Query my-index", "Query my-index"}, operations)
+}
+
+func TestIsConditionalCheckFailed(t *testing.T) {
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		return 400, conditionalCheckFailedBody
+	})
+
+	_, err := DefaultFns.DoDelete(context.Background(), client, middlewareTest{Id: "hello", Sort: "world", Version: 1})
+	assert.True(t, IsConditionalCheckFailed(err))
+	assert.True(t, IsConditionalCheckFailed(&types.TransactionCanceledException{CancellationReasons: []types.CancellationReason{
+		{Code: aws.String("None")},
+		{Code: aws.String("ConditionalCheckFailed")},
+	}}))
+	assert.False(t, IsConditionalCheckFailed(&types.TransactionCanceledException{}))
+	assert.False(t, IsConditionalCheckFailed(nil))
+}
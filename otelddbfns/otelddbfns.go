@@ -0,0 +1,189 @@
+// Package otelddbfns provides the OpenTelemetry tracing middleware for ddbfns.
+package otelddbfns
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/smithy-go"
+	ddbfns "github.com/nguyengg/go-ddb-fns"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.30.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// ScopeName is the instrumentation scope name of the tracer.
+const ScopeName = "github.com/nguyengg/go-ddb-fns/otelddbfns"
+
+// ConditionKey is the attribute key for the outcome of the request's condition expression, either "passed" or
+// "failed". It is not part of the semantic conventions.
+const ConditionKey = attribute.Key("ddbfns.condition")
+
+// Opts customises [Middleware].
+type Opts struct {
+	// TracerProvider creates the tracer.
+	//
+	// If nil, [otel.GetTracerProvider] is used.
+	TracerProvider trace.TracerProvider
+}
+
+// Middleware returns a [ddbfns.Middleware] that creates a client span for every DynamoDB request.
+//
+// The spans are named "{operation} {table}" and have the database, RPC, and AWS DynamoDB semantic convention attributes
+// such as `db.system.name`, `db.operation.name`, `aws.dynamodb.table_names`, and `aws.dynamodb.consumed_capacity`.
+// Requests with a condition expression also have a [ConditionKey] attribute.
+//
+// Usage:
+//
+//	f := &ddbfns.Fns{}
+//	f.Use(otelddbfns.Middleware())
+func Middleware(optFns ...func(*Opts)) ddbfns.Middleware {
+	opts := &Opts{}
+	for _, fn := range optFns {
+		fn(opts)
+	}
+
+	tp := opts.TracerProvider
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	tracer := tp.Tracer(ScopeName)
+
+	return func(next ddbfns.Handler) ddbfns.Handler {
+		return func(ctx context.Context, req *ddbfns.Request) (interface{}, error) {
+			name := req.Operation
+			if len(req.TableNames) == 1 {
+				name += " " + req.TableNames[0]
+			}
+
+			ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(requestAttributes(req)...))
+			defer span.End()
+
+			output, err := next(ctx, req)
+
+			span.SetAttributes(outputAttributes(output)...)
+
+			conditionFailed := ddbfns.IsConditionalCheckFailed(err)
+			if conditionFailed {
+				span.SetAttributes(ConditionKey.String("failed"))
+			} else if err == nil && req.HasCondition() {
+				span.SetAttributes(ConditionKey.String("passed"))
+			}
+
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+				span.SetAttributes(semconv.ErrorTypeKey.String(errorType(err)))
+			}
+
+			return output, err
+		}
+	}
+}
+
+func requestAttributes(req *ddbfns.Request) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemNameAWSDynamoDB,
+		semconv.DBOperationName(req.Operation),
+		semconv.RPCSystemKey.String("aws-api"),
+		semconv.RPCService("DynamoDB"),
+		semconv.RPCMethod(req.Operation),
+	}
+	if len(req.TableNames) != 0 {
+		attrs = append(attrs, semconv.AWSDynamoDBTableNames(req.TableNames...))
+	}
+	if req.IndexName != "" {
+		attrs = append(attrs, semconv.AWSDynamoDBIndexName(req.IndexName))
+	}
+
+	switch input := req.Input.(type) {
+	case *dynamodb.GetItemInput:
+		if input.ConsistentRead != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBConsistentRead(*input.ConsistentRead))
+		}
+		if input.ProjectionExpression != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBProjection(*input.ProjectionExpression))
+		}
+	case *dynamodb.QueryInput:
+		if input.ConsistentRead != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBConsistentRead(*input.ConsistentRead))
+		}
+		if input.ProjectionExpression != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBProjection(*input.ProjectionExpression))
+		}
+		if input.Limit != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBLimit(int(*input.Limit)))
+		}
+		if input.ScanIndexForward != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBScanForward(*input.ScanIndexForward))
+		}
+		if input.Select != "" {
+			attrs = append(attrs, semconv.AWSDynamoDBSelect(string(input.Select)))
+		}
+	case *dynamodb.ScanInput:
+		if input.ConsistentRead != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBConsistentRead(*input.ConsistentRead))
+		}
+		if input.ProjectionExpression != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBProjection(*input.ProjectionExpression))
+		}
+		if input.Limit != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBLimit(int(*input.Limit)))
+		}
+		if input.Segment != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBSegment(int(*input.Segment)))
+		}
+		if input.TotalSegments != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBTotalSegments(int(*input.TotalSegments)))
+		}
+		if input.Select != "" {
+			attrs = append(attrs, semconv.AWSDynamoDBSelect(string(input.Select)))
+		}
+	}
+
+	return attrs
+}
+
+func outputAttributes(output interface{}) []attribute.KeyValue {
+	var attrs []attribute.KeyValue
+
+	if cc := ddbfns.ConsumedCapacity(output); len(cc) != 0 {
+		values := make([]string, 0, len(cc))
+		for _, c := range cc {
+			data, err := json.Marshal(c)
+			if err != nil {
+				continue
+			}
+			values = append(values, string(data))
+		}
+		attrs = append(attrs, semconv.AWSDynamoDBConsumedCapacity(values...))
+	}
+
+	switch output := output.(type) {
+	case *dynamodb.QueryOutput:
+		if output != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBCount(int(output.Count)), semconv.AWSDynamoDBScannedCount(int(output.ScannedCount)))
+		}
+	case *dynamodb.ScanOutput:
+		if output != nil {
+			attrs = append(attrs, semconv.AWSDynamoDBCount(int(output.Count)), semconv.AWSDynamoDBScannedCount(int(output.ScannedCount)))
+		}
+	}
+
+	return attrs
+}
+
+// errorType returns the error code of API errors, or "_OTHER" for other errors.
+func errorType(err error) string {
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && strings.TrimSpace(apiErr.ErrorCode()) != "" {
+		return apiErr.ErrorCode()
+	}
+
+	return semconv.ErrorTypeOther.Value.AsString()
+}
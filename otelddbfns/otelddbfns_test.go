@@ -0,0 +1,128 @@
+package otelddbfns
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	ddbfns "github.com/nguyengg/go-ddb-fns"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type Item struct {
+	Id      string `dynamodbav:"id,hashkey" tableName:"my-table"`
+	Version int64  `dynamodbav:"version,version"`
+}
+
+type fakeHTTPClient func(operation string) (int, string)
+
+func (fn fakeHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	_, operation, _ := strings.Cut(req.Header.Get("X-Amz-Target"), ".")
+	statusCode, body := fn(operation)
+	return &http.Response{
+		StatusCode: statusCode,
+		Header:     http.Header{"Content-Type": []string{"application/x-amz-json-1.0"}},
+		Body:       io.NopCloser(bytes.NewBufferString(body)),
+		Request:    req,
+	}, nil
+}
+
+func TestMiddleware(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	client := dynamodb.New(dynamodb.Options{
+		Region:       "us-west-2",
+		BaseEndpoint: aws.String("http://localhost:8000"),
+		Credentials:  aws.AnonymousCredentials{},
+		Retryer:      aws.NopRetryer{},
+		HTTPClient: fakeHTTPClient(func(operation string) (int, string) {
+			switch operation {
+			case "GetItem":
+				return 200, `{"Item":{"id":{"S":"hello"}},"ConsumedCapacity":{"TableName":"my-table","CapacityUnits":0.5}}`
+			case "Query":
+				return 200, `{"Items":[],"Count":0,"ScannedCount":3}`
+			default:
+				return 400, `{"__type":"com.amazonaws.dynamodb.v20120810#ConditionalCheckFailedException","message":"The conditional request failed"}`
+			}
+		}),
+	})
+
+	f := &ddbfns.Fns{}
+	f.Use(Middleware(func(opts *Opts) {
+		opts.TracerProvider = tp
+	}))
+
+	_, err := f.DoGet(context.Background(), client, Item{Id: "hello"}, func(opts *ddbfns.GetOpts) {
+		opts.ReturnConsumedCapacity = types.ReturnConsumedCapacityTotal
+	})
+	assert.NoError(t, err)
+	_, err = f.DoPut(context.Background(), client, Item{Id: "hello"})
+	assert.True(t, ddbfns.IsConditionalCheckFailed(err))
+	err = f.DoQuery(context.Background(), client, &dynamodb.QueryInput{
+		TableName: aws.String("my-table"),
+		IndexName: aws.String("my-index"),
+		Limit:     aws.Int32(10),
+	}, func(v interface{}) error {
+		return nil
+	})
+	assert.NoError(t, err)
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 3) {
+		return
+	}
+
+	get := spans[0]
+	assert.Equal(t, "GetItem my-table", get.Name)
+	assert.Equal(t, trace.SpanKindClient, get.SpanKind)
+	assert.Equal(t, codes.Unset, get.Status.Code)
+	assert.Equal(t, ScopeName, get.InstrumentationScope.Name)
+	assert.Equal(t, map[attribute.Key]attribute.Value{
+		"db.system.name":                 attribute.StringValue("aws.dynamodb"),
+		"db.operation.name":              attribute.StringValue("GetItem"),
+		"rpc.system":                     attribute.StringValue("aws-api"),
+		"rpc.service":                    attribute.StringValue("DynamoDB"),
+		"rpc.method":                     attribute.StringValue("GetItem"),
+		"aws.dynamodb.table_names":       attribute.StringSliceValue([]string{"my-table"}),
+		"aws.dynamodb.consumed_capacity": attribute.StringSliceValue([]string{`{"CapacityUnits":0.5,"GlobalSecondaryIndexes":null,"LocalSecondaryIndexes":null,"ReadCapacityUnits":null,"Table":null,"TableName":"my-table","WriteCapacityUnits":null}`}),
+	}, attributes(get))
+
+	put := spans[1]
+	assert.Equal(t, "PutItem my-table", put.Name)
+	assert.Equal(t, codes.Error, put.Status.Code)
+	attrs := attributes(put)
+	assert.Equal(t, attribute.StringValue("failed"), attrs[ConditionKey])
+	assert.Equal(t, attribute.StringValue("ConditionalCheckFailedException"), attrs["error.type"])
+	if assert.Len(t, put.Events, 1) {
+		assert.Equal(t, "exception", put.Events[0].Name)
+	}
+
+	query := spans[2]
+	assert.Equal(t, "Query my-table", query.Name)
+	attrs = attributes(query)
+	assert.Equal(t, attribute.StringValue("my-index"), attrs["aws.dynamodb.index_name"])
+	assert.Equal(t, attribute.IntValue(10), attrs["aws.dynamodb.limit"])
+	assert.Equal(t, attribute.IntValue(0), attrs["aws.dynamodb.count"])
+	assert.Equal(t, attribute.IntValue(3), attrs["aws.dynamodb.scanned_count"])
+}
+
+func attributes(span tracetest.SpanStub) map[attribute.Key]attribute.Value {
+	m := make(map[attribute.Key]attribute.Value, len(span.Attributes))
+	for _, kv := range span.Attributes {
+		m[kv.Key] = kv.Value
+	}
+
+	return m
+}
@@ -0,0 +1,335 @@
+package ddbfns
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// expressions are the expressions of a request that share the same names and values.
+//
+// The expressions are listed in the order their placeholders are renamed.
+type expressions struct {
+	update       *string
+	condition    *string
+	keyCondition *string
+	filter       *string
+	projection   *string
+	names        map[string]string
+	values       map[string]types.AttributeValue
+}
+
+// rewrite renames the placeholders and reorders the expressions according to Fns.ReadablePlaceholders and
+// Fns.CanonicalExpressions.
+//
+// The expression strings are never modified in place since they may be shared with the plan; the pointers are replaced
+// instead.
+func (f *Fns) rewrite(e *expressions) {
+	if !f.ReadablePlaceholders && !f.CanonicalExpressions {
+		return
+	}
+
+	if f.CanonicalExpressions {
+		if e.update != nil {
+			e.update = ptr(canonicalUpdate(*e.update, e.names, e.values))
+		}
+		if e.condition != nil {
+			e.condition = ptr(canonicalAnd(*e.condition, e.names, e.values))
+		}
+		if e.filter != nil {
+			e.filter = ptr(canonicalAnd(*e.filter, e.names, e.values))
+		}
+	}
+
+	r := &renamer{
+		readable:     f.ReadablePlaceholders,
+		oldNames:     e.names,
+		oldValues:    e.values,
+		nameMapping:  make(map[string]string, len(e.names)),
+		valueMapping: make(map[string]string, len(e.values)),
+	}
+	if e.names != nil {
+		r.newNames = make(map[string]string, len(e.names))
+	}
+	if e.values != nil {
+		r.newValues = make(map[string]types.AttributeValue, len(e.values))
+	}
+
+	for _, expr := range []struct {
+		s      **string
+		suffix string
+	}{
+		{s: &e.update},
+		// values in condition expressions are compared against the stored item, hence the suffix.
+		{s: &e.condition, suffix: "_old"},
+		{s: &e.keyCondition},
+		{s: &e.filter},
+		{s: &e.projection},
+	} {
+		if *expr.s != nil {
+			*expr.s = ptr(r.rename(**expr.s, expr.suffix))
+		}
+	}
+
+	e.names, e.values = r.newNames, r.newValues
+}
+
+// renamer renames the placeholders in the order they appear.
+type renamer struct {
+	readable              bool
+	oldNames              map[string]string
+	oldValues             map[string]types.AttributeValue
+	nameMapping           map[string]string
+	valueMapping          map[string]string
+	newNames              map[string]string
+	newValues             map[string]types.AttributeValue
+	nameCount, valueCount int
+}
+
+func (r *renamer) rename(s, suffix string) string {
+	// lastName is the most recent attribute name in the expression which is used to name the value placeholders.
+	lastName := ""
+
+	return placeholderRegex.ReplaceAllStringFunc(s, func(placeholder string) string {
+		if placeholder[0] == '#' {
+			name, ok := r.oldNames[placeholder]
+			if !ok {
+				return placeholder
+			}
+			lastName = name
+
+			if renamed, ok := r.nameMapping[placeholder]; ok {
+				return renamed
+			}
+
+			renamed := fmt.Sprintf("#%d", r.nameCount)
+			if r.readable {
+				renamed = unusedPlaceholder("#"+sanitizePlaceholder(name), r.newNames)
+			}
+			r.nameCount++
+			r.nameMapping[placeholder] = renamed
+			r.newNames[renamed] = name
+			return renamed
+		}
+
+		av, ok := r.oldValues[placeholder]
+		if !ok {
+			return placeholder
+		}
+
+		if renamed, ok := r.valueMapping[placeholder]; ok {
+			return renamed
+		}
+
+		renamed := fmt.Sprintf(":%d", r.valueCount)
+		if r.readable {
+			base := "value"
+			if lastName != "" {
+				base = sanitizePlaceholder(lastName)
+			}
+			renamed = unusedPlaceholder(":"+base+suffix, r.newValues)
+		}
+		r.valueCount++
+		r.valueMapping[placeholder] = renamed
+		r.newValues[renamed] = av
+		return renamed
+	})
+}
+
+var invalidPlaceholderRegex = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// sanitizePlaceholder replaces the characters that are not allowed in placeholders with underscores.
+func sanitizePlaceholder(name string) string {
+	if s := invalidPlaceholderRegex.ReplaceAllString(name, "_"); s != "" {
+		return s
+	}
+
+	return "_"
+}
+
+// unusedPlaceholder returns placeholder if it is not in m, or placeholder with the smallest numeric suffix (starting at
+// 2) that is not in m.
+func unusedPlaceholder[V any](placeholder string, m map[string]V) string {
+	if _, ok := m[placeholder]; !ok {
+		return placeholder
+	}
+
+	for i := 2; ; i++ {
+		s := fmt.Sprintf("%s_%d", placeholder, i)
+		if _, ok := m[s]; !ok {
+			return s
+		}
+	}
+}
+
+// canonicalUpdate sorts the actions within each clause of the update expression.
+//
+// expression.Builder already orders the clauses themselves (ADD, DELETE, REMOVE, SET), but the actions in each clause
+// are in the order they were added.
+func canonicalUpdate(s string, names map[string]string, values map[string]types.AttributeValue) string {
+	clauses := strings.Split(s, "\n")
+	for i, clause := range clauses {
+		keyword, actions, ok := strings.Cut(clause, " ")
+		if !ok {
+			continue
+		}
+
+		parts := splitTopLevel(actions, ", ")
+		sortBySubstituted(parts, names, values)
+		clauses[i] = keyword + " " + strings.Join(parts, ", ")
+	}
+
+	return strings.Join(clauses, "\n")
+}
+
+// canonicalAnd sorts the operands of the top-level AND chain of a condition expression, flattening nested ANDs.
+//
+// Expressions that are not AND chains are returned as-is.
+func canonicalAnd(s string, names map[string]string, values map[string]types.AttributeValue) string {
+	operands := flattenAnd(s)
+	if len(operands) == 1 {
+		return s
+	}
+
+	sortBySubstituted(operands, names, values)
+	return "(" + strings.Join(operands, ") AND (") + ")"
+}
+
+// flattenAnd returns the operands of the AND chain without their enclosing parentheses.
+func flattenAnd(s string) []string {
+	parts := splitTopLevel(s, " AND ")
+	if len(parts) == 1 {
+		return parts
+	}
+
+	// expression.Builder wraps every operand of AND in parentheses; if any part isn't, then the AND belongs to another
+	// operator such as BETWEEN.
+	var operands []string
+	for _, part := range parts {
+		inner, ok := unwrapParentheses(part)
+		if !ok {
+			return []string{s}
+		}
+
+		operands = append(operands, flattenAnd(inner)...)
+	}
+
+	return operands
+}
+
+// unwrapParentheses returns the string without its enclosing parentheses if the opening and closing ones match.
+func unwrapParentheses(s string) (string, bool) {
+	if !strings.HasPrefix(s, "(") || !strings.HasSuffix(s, ")") {
+		return s, false
+	}
+
+	depth := 0
+	for i, c := range s {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 && i != len(s)-1 {
+				return s, false
+			}
+		}
+	}
+
+	return s[1 : len(s)-1], true
+}
+
+// splitTopLevel splits s by sep only where sep is not enclosed in parentheses.
+func splitTopLevel(s, sep string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		default:
+			if depth == 0 && strings.HasPrefix(s[i:], sep) {
+				parts = append(parts, s[start:i])
+				i += len(sep) - 1
+				start = i + 1
+			}
+		}
+	}
+
+	return append(parts, s[start:])
+}
+
+// sortBySubstituted sorts the expressions by their renderings with the names and values substituted so that the order
+// does not depend on how the placeholders were numbered.
+func sortBySubstituted(exprs []string, names map[string]string, values map[string]types.AttributeValue) {
+	e := &explainer{names: names, values: values}
+	keys := make(map[string]string, len(exprs))
+	for _, expr := range exprs {
+		e.parts = nil
+		e.clause("", &expr)
+		keys[expr] = e.String()
+	}
+
+	slices.SortStableFunc(exprs, func(a, b string) int {
+		return strings.Compare(keys[a], keys[b])
+	})
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func (f *Fns) rewritePut(input *dynamodb.PutItemInput) *dynamodb.PutItemInput {
+	e := &expressions{condition: input.ConditionExpression, names: input.ExpressionAttributeNames, values: input.ExpressionAttributeValues}
+	f.rewrite(e)
+	input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues = e.condition, e.names, e.values
+	return input
+}
+
+func (f *Fns) rewriteUpdate(input *dynamodb.UpdateItemInput) *dynamodb.UpdateItemInput {
+	e := &expressions{update: input.UpdateExpression, condition: input.ConditionExpression, names: input.ExpressionAttributeNames, values: input.ExpressionAttributeValues}
+	f.rewrite(e)
+	input.UpdateExpression, input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues = e.update, e.condition, e.names, e.values
+	return input
+}
+
+func (f *Fns) rewriteDelete(input *dynamodb.DeleteItemInput) *dynamodb.DeleteItemInput {
+	e := &expressions{condition: input.ConditionExpression, names: input.ExpressionAttributeNames, values: input.ExpressionAttributeValues}
+	f.rewrite(e)
+	input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues = e.condition, e.names, e.values
+	return input
+}
+
+func (f *Fns) rewriteGet(input *dynamodb.GetItemInput) *dynamodb.GetItemInput {
+	e := &expressions{projection: input.ProjectionExpression, names: input.ExpressionAttributeNames}
+	f.rewrite(e)
+	input.ProjectionExpression, input.ExpressionAttributeNames = e.projection, e.names
+	return input
+}
+
+func (f *Fns) rewriteQuery(input *dynamodb.QueryInput) *dynamodb.QueryInput {
+	e := &expressions{keyCondition: input.KeyConditionExpression, filter: input.FilterExpression, projection: input.ProjectionExpression, names: input.ExpressionAttributeNames, values: input.ExpressionAttributeValues}
+	f.rewrite(e)
+	input.KeyConditionExpression, input.FilterExpression, input.ProjectionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues = e.keyCondition, e.filter, e.projection, e.names, e.values
+	return input
+}
+
+func (f *Fns) rewriteTransactPut(input *types.Put) *types.Put {
+	e := &expressions{condition: input.ConditionExpression, names: input.ExpressionAttributeNames, values: input.ExpressionAttributeValues}
+	f.rewrite(e)
+	input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues = e.condition, e.names, e.values
+	return input
+}
+
+func (f *Fns) rewriteTransactDelete(input *types.Delete) *types.Delete {
+	e := &expressions{condition: input.ConditionExpression, names: input.ExpressionAttributeNames, values: input.ExpressionAttributeValues}
+	f.rewrite(e)
+	input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues = e.condition, e.names, e.values
+	return input
+}
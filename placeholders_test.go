@@ -0,0 +1,170 @@
+package ddbfns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type placeholdersTest struct {
+	Id           string    `dynamodbav:"id,hashkey" tableName:""`
+	Version      int64     `dynamodbav:"version,version"`
+	ModifiedTime time.Time `dynamodbav:"modifiedTime,modifiedTime,unixtime"`
+}
+
+func TestFns_ReadablePlaceholders(t *testing.T) {
+	f := &Fns{ReadablePlaceholders: true}
+
+	put, err := f.Put(placeholdersTest{Id: "hello", Version: 3})
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+	assert.Equal(t, "#version = :version_old", *put.ConditionExpression)
+	assert.Equal(t, map[string]string{"#version": "version"}, put.ExpressionAttributeNames)
+	assert.Equal(t, map[string]types.AttributeValue{":version_old": &types.AttributeValueMemberN{Value: "3"}}, put.ExpressionAttributeValues)
+
+	update, err := f.Update(placeholdersTest{Id: "hello", Version: 3}, func(opts *UpdateOpts) {
+		opts.Set("a-b", "hello").Set("a_b", "world").Remove("notes")
+		opts.Clock = func() time.Time {
+			return time.Unix(1700000000, 0)
+		}
+	})
+	if err != nil {
+		t.Errorf("Update() error = %v", err)
+		return
+	}
+	assert.Equal(t, "ADD #version :version\nREMOVE #notes\nSET #a_b = :a_b, #a_b_2 = :a_b_2, #modifiedTime = :modifiedTime\n", *update.UpdateExpression)
+	assert.Equal(t, "#version = :version_old", *update.ConditionExpression)
+	assert.Equal(t, map[string]string{
+		"#version":      "version",
+		"#notes":        "notes",
+		"#a_b":          "a-b",
+		"#a_b_2":        "a_b",
+		"#modifiedTime": "modifiedTime",
+	}, update.ExpressionAttributeNames)
+	assert.Equal(t, map[string]types.AttributeValue{
+		":version":      &types.AttributeValueMemberN{Value: "1"},
+		":a_b":          &types.AttributeValueMemberS{Value: "hello"},
+		":a_b_2":        &types.AttributeValueMemberS{Value: "world"},
+		":modifiedTime": &types.AttributeValueMemberN{Value: "1700000000"},
+		":version_old":  &types.AttributeValueMemberN{Value: "3"},
+	}, update.ExpressionAttributeValues)
+
+	get, err := f.Get(placeholdersTest{Id: "hello"}, func(opts *GetOpts) {
+		opts.WithProjectionExpression("id", "version")
+	})
+	if err != nil {
+		t.Errorf("Get() error = %v", err)
+		return
+	}
+	assert.Equal(t, "#id, #version", *get.ProjectionExpression)
+}
+
+func TestFns_CanonicalExpressions(t *testing.T) {
+	f := &Fns{CanonicalExpressions: true}
+	clock := func() time.Time {
+		return time.Unix(1700000000, 0)
+	}
+
+	a, err := f.Update(placeholdersTest{Id: "hello", Version: 3}, func(opts *UpdateOpts) {
+		opts.Set("b", 2).Set("a", 1)
+		opts.And(expression.Name("status").Equal(expression.Value("active")))
+		opts.And(expression.Name("count").Between(expression.Value(1), expression.Value(10)))
+		opts.Clock = clock
+	})
+	if err != nil {
+		t.Errorf("Update() error = %v", err)
+		return
+	}
+
+	b, err := f.Update(placeholdersTest{Id: "hello", Version: 3}, func(opts *UpdateOpts) {
+		opts.Set("a", 1).Set("b", 2)
+		opts.And(expression.Name("count").Between(expression.Value(1), expression.Value(10)))
+		opts.And(expression.Name("status").Equal(expression.Value("active")))
+		opts.Clock = clock
+	})
+	if err != nil {
+		t.Errorf("Update() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, a, b)
+	assert.Equal(t, "ADD #0 :0\nSET #1 = :1, #2 = :2, #3 = :3\n", *a.UpdateExpression)
+	assert.Equal(t, map[string]string{"#0": "version", "#1": "a", "#2": "b", "#3": "modifiedTime", "#4": "count", "#5": "status"}, a.ExpressionAttributeNames)
+	assert.Equal(t, "(#4 BETWEEN :4 AND :5) AND (#5 = :6) AND (#0 = :7)", *a.ConditionExpression)
+}
+
+func TestFns_CanonicalExpressionsNotAndChain(t *testing.T) {
+	type Test struct {
+		Id string `dynamodbav:"id,hashkey" tableName:""`
+	}
+
+	f := &Fns{CanonicalExpressions: true}
+
+	got, err := f.Delete(Test{Id: "hello"}, func(opts *DeleteOpts) {
+		opts.And(expression.Name("b").Equal(expression.Value(2))).Or(expression.Name("a").Equal(expression.Value(1)))
+	})
+	if err != nil {
+		t.Errorf("Delete() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, "(#0 = :0) OR (#1 = :1)", *got.ConditionExpression)
+	assert.Equal(t, map[string]string{"#0": "b", "#1": "a"}, got.ExpressionAttributeNames)
+}
+
+func TestFns_ReadablePlaceholdersTransactAndQuery(t *testing.T) {
+	f := &Fns{ReadablePlaceholders: true}
+
+	put, err := f.TransactPut(uniqueTest{Id: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Errorf("TransactPut() error = %v", err)
+		return
+	}
+	sentinel := put.TransactItems[1].Put
+	assert.Equal(t, "(attribute_not_exists (#id)) OR (#_owner = :_owner_old)", *sentinel.ConditionExpression)
+	assert.Equal(t, map[string]string{"#id": "id", "#_owner": "_owner"}, sentinel.ExpressionAttributeNames)
+	assert.Equal(t, map[string]types.AttributeValue{":_owner_old": &types.AttributeValueMemberS{Value: "alice"}}, sentinel.ExpressionAttributeValues)
+
+	del, err := f.TransactDelete(uniqueTest{Id: "alice", Email: "alice@example.com", Version: 1})
+	if err != nil {
+		t.Errorf("TransactDelete() error = %v", err)
+		return
+	}
+	assert.Equal(t, "(attribute_not_exists (#id)) OR (#_owner = :_owner_old)", *del.TransactItems[1].Delete.ConditionExpression)
+
+	f.HistoryOpts = &HistoryOpts{}
+	put, err = f.TransactPut(historyTest{PK: "DOC#1", SK: "META", Title: "hello"})
+	if err != nil {
+		t.Errorf("TransactPut() error = %v", err)
+		return
+	}
+	hist := put.TransactItems[1].Put
+	assert.Equal(t, "attribute_not_exists (#pk)", *hist.ConditionExpression)
+	assert.Equal(t, map[string]string{"#pk": "pk"}, hist.ExpressionAttributeNames)
+
+	client := &fakeQueryClient{pages: []*dynamodb.QueryOutput{{}}}
+	_, err = f.History(context.Background(), client, historyTest{PK: "DOC#1", SK: "META"})
+	if err != nil {
+		t.Errorf("History() error = %v", err)
+		return
+	}
+	assert.Equal(t, "(#pk = :pk) AND (begins_with (#sk, :sk))", *client.inputs[0].KeyConditionExpression)
+	assert.Equal(t, map[string]string{"#pk": "pk", "#sk": "sk"}, client.inputs[0].ExpressionAttributeNames)
+
+	client = &fakeQueryClient{pages: []*dynamodb.QueryOutput{{}}}
+	var got struct{}
+	err = f.LoadCollection(context.Background(), client, orderTest{PK: "ORDER#1"}, &got)
+	if err != nil {
+		t.Errorf("LoadCollection() error = %v", err)
+		return
+	}
+	assert.Equal(t, "#pk = :pk", *client.inputs[0].KeyConditionExpression)
+	assert.Equal(t, map[string]types.AttributeValue{":pk": &types.AttributeValueMemberS{Value: "ORDER#1"}}, client.inputs[0].ExpressionAttributeValues)
+}
@@ -0,0 +1,185 @@
+package ddbfns
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// cachedType is the value stored in Fns.cache.
+type cachedType struct {
+	model *internal.Model
+	plan  *plan
+}
+
+// plan contains the per-type accessors and pre-rendered expressions used by Put, Update, and Delete.
+//
+// The plan is computed once per struct type so that the hot paths don't have to re-resolve the kinds of the version and
+// timestamp fields or build the standard optimistic locking conditions with expression.NewBuilder every time.
+type plan struct {
+	version      *versionAccessor
+	createdTime  *fieldAccessor
+	modifiedTime *fieldAccessor
+	deletedTime  *fieldAccessor
+	ttl          *fieldAccessor
+
+	// notExists is the `attribute_not_exists(#hashkey)` condition.
+	notExists renderedCondition
+	// versionEquals is the `#version = :version` condition.
+	versionEquals renderedCondition
+}
+
+// renderedCondition is a condition expression rendered the same way expression.Builder would, with at most one value.
+type renderedCondition struct {
+	expression string
+	names      map[string]string
+}
+
+// fieldAccessor reads a struct field by its index.
+type fieldAccessor struct {
+	index []int
+}
+
+// versionAccessor reads a version field and formats its current and next values as N attribute values.
+type versionAccessor struct {
+	fieldAccessor
+	format func(fv reflect.Value) (current, next string, zero bool)
+}
+
+// newPlan computes the plan of the given model.
+func newPlan(m *internal.Model) (*plan, error) {
+	p := &plan{}
+
+	if m.HashKey != nil {
+		expr, err := expression.NewBuilder().WithCondition(expression.Name(m.HashKey.Name).AttributeNotExists()).Build()
+		if err != nil {
+			return nil, fmt.Errorf("build expressions error: %w", err)
+		}
+		p.notExists = renderedCondition{expression: *expr.Condition(), names: expr.Names()}
+	}
+
+	if attr := m.Version; attr != nil {
+		expr, err := expression.NewBuilder().
+			WithCondition(expression.Name(attr.Name).Equal(expression.Value(&types.AttributeValueMemberN{Value: "0"}))).
+			Build()
+		if err != nil {
+			return nil, fmt.Errorf("build expressions error: %w", err)
+		}
+		p.versionEquals = renderedCondition{expression: *expr.Condition(), names: expr.Names()}
+
+		p.version = &versionAccessor{fieldAccessor: fieldAccessor{index: attr.Field.Index}}
+		switch kind := attr.Field.Type.Kind(); kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			p.version.format = func(fv reflect.Value) (string, string, bool) {
+				v := fv.Int()
+				return strconv.FormatInt(v, 10), strconv.FormatInt(v+1, 10), v == 0
+			}
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			p.version.format = func(fv reflect.Value) (string, string, bool) {
+				v := fv.Uint()
+				return strconv.FormatUint(v, 10), strconv.FormatUint(v+1, 10), v == 0
+			}
+		case reflect.Float32, reflect.Float64:
+			bitSize := 64
+			if kind == reflect.Float32 {
+				bitSize = 32
+			}
+
+			p.version.format = func(fv reflect.Value) (string, string, bool) {
+				v := fv.Float()
+				return strconv.FormatFloat(v, 'f', -1, bitSize), strconv.FormatFloat(v+1, 'f', -1, bitSize), v == 0
+			}
+		default:
+			return nil, fmt.Errorf("version attribute's type (%s) is unknown numeric type", attr.Field.Type)
+		}
+	}
+
+	if attr := m.CreatedTime; attr != nil {
+		p.createdTime = &fieldAccessor{index: attr.Field.Index}
+	}
+	if attr := m.ModifiedTime; attr != nil {
+		p.modifiedTime = &fieldAccessor{index: attr.Field.Index}
+	}
+	if attr := m.DeletedTime; attr != nil {
+		p.deletedTime = &fieldAccessor{index: attr.Field.Index}
+	}
+	if attr := m.TTL; attr != nil {
+		p.ttl = &fieldAccessor{index: attr.Field.Index}
+	}
+
+	return p, nil
+}
+
+// get returns the field from the given struct value.
+func (a *fieldAccessor) get(iv reflect.Value) (reflect.Value, error) {
+	if len(a.index) == 1 {
+		return iv.Field(a.index[0]), nil
+	}
+
+	return iv.FieldByIndexErr(a.index)
+}
+
+// get returns the current and next values of the version field from the given struct value, and whether the current
+// value is zero.
+func (a *versionAccessor) get(iv reflect.Value) (current, next string, zero bool, err error) {
+	fv, err := a.fieldAccessor.get(iv)
+	if err != nil {
+		return "", "", false, fmt.Errorf("get version value error: %w", err)
+	}
+
+	current, next, zero = a.format(fv)
+	return current, next, zero, nil
+}
+
+// copyNames returns a copy of the rendered names.
+func (c renderedCondition) copyNames() map[string]string {
+	names := make(map[string]string, len(c.names))
+	for k, v := range c.names {
+		names[k] = v
+	}
+
+	return names
+}
+
+// fastCondition tracks whether the standard optimistic locking condition is the only condition of a request.
+type fastCondition struct {
+	disabled bool
+	rc       *renderedCondition
+	value    types.AttributeValue
+}
+
+// newFastCondition creates a fastCondition that is disabled if the user has already added a condition.
+func newFastCondition(condition expression.ConditionBuilder) *fastCondition {
+	return &fastCondition{disabled: condition.IsSet()}
+}
+
+// use records the standard condition; the fast path is disabled if there was already one.
+func (c *fastCondition) use(rc *renderedCondition, value types.AttributeValue) {
+	if c.rc != nil {
+		c.disabled = true
+	}
+
+	c.rc, c.value = rc, value
+}
+
+// disable disables the fast path because another condition has been or will be added.
+func (c *fastCondition) disable() {
+	c.disabled = true
+}
+
+// get returns the standard condition and its values if it is the only condition of the request.
+func (c *fastCondition) get() (*renderedCondition, map[string]types.AttributeValue, bool) {
+	if c.disabled || c.rc == nil {
+		return nil, nil, false
+	}
+
+	if c.value == nil {
+		return c.rc, nil, true
+	}
+
+	return c.rc, map[string]types.AttributeValue{":0": c.value}, true
+}
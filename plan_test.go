@@ -0,0 +1,70 @@
+package ddbfns
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFns_PlanFastPath(t *testing.T) {
+	type Test struct {
+		Id      string  `dynamodbav:"id,hashkey" tableName:""`
+		Version float32 `dynamodbav:"version,version"`
+	}
+
+	// the fast path must render the same expressions as expression.Builder.
+	for _, version := range []float32{0, 1.5} {
+		got, err := Put(Test{Id: "hello", Version: version})
+		if err != nil {
+			t.Errorf("Put() error = %v", err)
+			return
+		}
+
+		var condition expression.ConditionBuilder
+		if version == 0 {
+			condition = expression.Name("id").AttributeNotExists()
+		} else {
+			condition = expression.Name("version").Equal(expression.Value(&types.AttributeValueMemberN{Value: "1.5"}))
+		}
+		expr, err := expression.NewBuilder().WithCondition(condition).Build()
+		if err != nil {
+			t.Errorf("Build() error = %v", err)
+			return
+		}
+
+		assert.Equal(t, expr.Condition(), got.ConditionExpression)
+		assert.Equal(t, expr.Names(), got.ExpressionAttributeNames)
+		assert.Equal(t, expr.Values(), got.ExpressionAttributeValues)
+
+		got2, err := Delete(Test{Id: "hello", Version: version})
+		if err != nil {
+			t.Errorf("Delete() error = %v", err)
+			return
+		}
+
+		assert.Equal(t, expr.Condition(), got2.ConditionExpression)
+		assert.Equal(t, expr.Names(), got2.ExpressionAttributeNames)
+		assert.Equal(t, expr.Values(), got2.ExpressionAttributeValues)
+	}
+
+	// the version is incremented for float types too.
+	got, err := Put(Test{Id: "hello", Version: 1.5})
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "2.5"}, got.Item["version"])
+
+	// user conditions disable the fast path.
+	got, err = Put(Test{Id: "hello", Version: 1}, func(opts *PutOpts) {
+		opts.And(expression.Name("status").Equal(expression.Value("active")))
+	})
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+	assert.Equal(t, "(#0 = :0) AND (#1 = :1)", *got.ConditionExpression)
+	assert.Equal(t, map[string]string{"#0": "status", "#1": "version"}, got.ExpressionAttributeNames)
+}
@@ -4,13 +4,12 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"strconv"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
 )
 
 // Put creates the PutItem request for the given item.
@@ -23,23 +22,52 @@ import (
 // optimistic locking. The version attribute in the `map[string]AttributeValue` return value will be incremented by 1.
 //
 // Any zero-value created or modified timestamps will be set to [time.Now] unless disabled by PutOpts.
+//
+// If the item's version is not at its zero value, every attribute tagged with `immutable` is also added to the condition
+// expression (`#immutable = :value`) so that an existing item cannot have those attributes reassigned.
+//
+// Before the request is built, the item is validated against the `required`, `min`, `max`, `maxLength`, `pattern`, and
+// `oneof` struct tags, then against [Validator.Validate] if the struct implements Validator. All violations are
+// aggregated into a [ValidationError].
+//
+// If the struct implements [BeforePutHook], the hook is called on a copy of the struct before validation.
+//
+// If the struct type has been registered with [RegisterType], the type discriminator attribute is also written.
+//
+// If the struct has attributes tagged with `unique`, an error is returned since a single PutItem request cannot
+// maintain their sentinel items; use [Fns.TransactPut] instead.
 func (f *Fns) Put(v interface{}, optFns ...func(*PutOpts)) (*dynamodb.PutItemInput, error) {
-	f.init.Do(f.initFn)
-
-	opts := &PutOpts{}
-	for _, fn := range optFns {
-		fn(opts)
+	if f.hasUniques(v) {
+		attrs, _ := f.loadOrParse(reflect.TypeOf(v))
+		return nil, errUniques(attrs, "TransactPut")
 	}
 
-	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+	return f.put(context.Background(), v, applyOpts(optFns))
+}
+
+func (f *Fns) put(ctx context.Context, v interface{}, opts *PutOpts) (*dynamodb.PutItemInput, error) {
+	f.init.Do(f.initFn)
+
+	attrs, p, err := f.loadPlan(reflect.TypeOf(v))
 	if err != nil {
 		return nil, err
 	}
 
+	if cp, hook, ok := shallowCopy[BeforePutHook](v); ok {
+		if err = hook.BeforePut(ctx, opts); err != nil {
+			return nil, err
+		}
+		v = cp
+	}
+
 	if opts.TableName == nil {
 		opts.TableName = attrs.TableName
 	}
 
+	if err = validateItem(attrs, v); err != nil {
+		return nil, err
+	}
+
 	// PutItem requires the entire map[string]AttributeValue item.
 	var item map[string]types.AttributeValue
 	if av, err := f.Encoder.Encode(v); err != nil {
@@ -50,36 +78,78 @@ func (f *Fns) Put(v interface{}, optFns ...func(*PutOpts)) (*dynamodb.PutItemInp
 		item = asMap.Value
 	}
 
+	if err = f.computeKeys(attrs, v, item); err != nil {
+		return nil, err
+	}
+
+	if typeName, ok := f.typeName(attrs); ok {
+		item[f.typeAttribute()] = &types.AttributeValueMemberS{Value: typeName}
+	}
+
 	iv := reflect.Indirect(reflect.ValueOf(v))
 
+	// attributevalue never omits a zero time.Time so a live item would otherwise be written as tombstoned.
+	for _, a := range []struct {
+		attr     *internal.Attribute
+		accessor *fieldAccessor
+	}{{attrs.DeletedTime, p.deletedTime}, {attrs.TTL, p.ttl}} {
+		if a.attr == nil {
+			continue
+		}
+
+		fv, err := a.accessor.get(iv)
+		if err != nil {
+			return nil, fmt.Errorf("get %s value error: %w", a.attr.Name, err)
+		}
+		if fv.IsZero() {
+			delete(item, a.attr.Name)
+		}
+	}
+
+	// if the only condition is the standard optimistic locking one, its pre-rendered form is used.
+	fast := newFastCondition(opts.condition)
+
 	if versionAttr := attrs.Version; !opts.DisableOptimisticLocking && versionAttr != nil {
-		version, err := versionAttr.Get(iv)
+		current, next, zero, err := p.version.get(iv)
 		if err != nil {
-			return nil, fmt.Errorf("get version value error: %w", err)
+			return nil, err
 		}
 
-		switch {
-		case version.IsZero():
+		if zero {
 			opts.And(expression.Name(attrs.HashKey.Name).AttributeNotExists())
+			fast.use(&p.notExists, nil)
 			item[versionAttr.Name] = &types.AttributeValueMemberN{Value: "1"}
-		case version.CanInt():
-			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(item[versionAttr.Name])))
-			item[versionAttr.Name] = &types.AttributeValueMemberN{Value: strconv.FormatInt(version.Int()+1, 10)}
-		case version.CanUint():
-			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(item[versionAttr.Name])))
-			item[versionAttr.Name] = &types.AttributeValueMemberN{Value: strconv.FormatUint(version.Uint()+1, 10)}
-		case version.CanFloat():
-			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(item[versionAttr.Name])))
-			item[versionAttr.Name] = &types.AttributeValueMemberN{Value: strconv.FormatFloat(version.Float(), 'f', -1, 64)}
-		default:
-			panic(fmt.Errorf("version attribute's type (%s) is unknown numeric type", version.Type()))
+		} else {
+			av := &types.AttributeValueMemberN{Value: current}
+			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(av)))
+			fast.use(&p.versionEquals, av)
+			item[versionAttr.Name] = &types.AttributeValueMemberN{Value: next}
 		}
 	}
 
-	now := time.Now()
+	// on an existing item, immutable attributes must keep their stored values.
+	if versionAttr := attrs.Version; versionAttr != nil && len(attrs.Immutables) != 0 {
+		_, _, zero, err := p.version.get(iv)
+		if err != nil {
+			return nil, err
+		}
+
+		if !zero {
+			fast.disable()
+			for _, attr := range attrs.Immutables {
+				if av, ok := item[attr.Name]; ok {
+					opts.And(expression.Name(attr.Name).Equal(expression.Value(av)))
+				} else {
+					opts.And(expression.Name(attr.Name).AttributeNotExists())
+				}
+			}
+		}
+	}
+
+	now := opts.now()
 
 	if createdTimeAttr := attrs.CreatedTime; !opts.DisableAutoGeneratedTimestamps && createdTimeAttr != nil {
-		createdTime, err := createdTimeAttr.Get(iv)
+		createdTime, err := p.createdTime.get(iv)
 		if err != nil {
 			return nil, fmt.Errorf("get createdTime value error: %w", err)
 		}
@@ -102,7 +172,7 @@ func (f *Fns) Put(v interface{}, optFns ...func(*PutOpts)) (*dynamodb.PutItemInp
 	}
 
 	if modifiedTimeAttr := attrs.ModifiedTime; !opts.DisableAutoGeneratedTimestamps && modifiedTimeAttr != nil {
-		modifiedTime, err := modifiedTimeAttr.Get(iv)
+		modifiedTime, err := p.modifiedTime.get(iv)
 		if err != nil {
 			return nil, fmt.Errorf("get modifiedTime value error: %w", err)
 		}
@@ -125,13 +195,34 @@ func (f *Fns) Put(v interface{}, optFns ...func(*PutOpts)) (*dynamodb.PutItemInp
 		}
 	}
 
+	if opts.beforeBuild != nil {
+		fast.disable()
+		if err = opts.beforeBuild(item); err != nil {
+			return nil, err
+		}
+	}
+
+	if rc, values, ok := fast.get(); ok {
+		return f.rewritePut(&dynamodb.PutItemInput{
+			Item:                                item,
+			TableName:                           opts.TableName,
+			ConditionExpression:                 &rc.expression,
+			ExpressionAttributeNames:            rc.copyNames(),
+			ExpressionAttributeValues:           values,
+			ReturnConsumedCapacity:              opts.ReturnConsumedCapacity,
+			ReturnItemCollectionMetrics:         opts.ReturnItemCollectionMetrics,
+			ReturnValues:                        opts.ReturnValues,
+			ReturnValuesOnConditionCheckFailure: opts.ReturnValuesOnConditionCheckFailure,
+		}), nil
+	}
+
 	if opts.condition.IsSet() {
 		expr, err := expression.NewBuilder().WithCondition(opts.condition).Build()
 		if err != nil {
 			return nil, fmt.Errorf("build expressions error: %w", err)
 		}
 
-		return &dynamodb.PutItemInput{
+		return f.rewritePut(&dynamodb.PutItemInput{
 			Item:                                item,
 			TableName:                           opts.TableName,
 			ConditionExpression:                 expr.Condition(),
@@ -141,7 +232,7 @@ func (f *Fns) Put(v interface{}, optFns ...func(*PutOpts)) (*dynamodb.PutItemInp
 			ReturnItemCollectionMetrics:         opts.ReturnItemCollectionMetrics,
 			ReturnValues:                        opts.ReturnValues,
 			ReturnValuesOnConditionCheckFailure: opts.ReturnValuesOnConditionCheckFailure,
-		}, nil
+		}), nil
 	}
 
 	return &dynamodb.PutItemInput{
@@ -155,24 +246,46 @@ func (f *Fns) Put(v interface{}, optFns ...func(*PutOpts)) (*dynamodb.PutItemInp
 }
 
 // DoPut performs a [Fns.Put] and then executes the request with the specified DynamoDB client.
+//
+// If [Fns.HistoryOpts] is given, or if the struct has attributes tagged with `unique`, [Fns.DoTransactPut] is performed
+// instead to also write the history item or the uniqueness sentinel items. ReturnValues and Decode are not supported by
+// TransactWriteItems so an error is returned if either is given.
 func (f *Fns) DoPut(ctx context.Context, client *dynamodb.Client, v interface{}, optFns ...func(*PutOpts)) (*dynamodb.PutItemOutput, error) {
-	var opts *PutOpts
-	optFns = append(optFns, func(o *PutOpts) {
-		opts = o
-	})
+	opts := applyOpts(optFns)
+
+	if f.HistoryOpts != nil || f.hasUniques(v) {
+		if err := checkTransactReturnValues(&opts.RequestOptions); err != nil {
+			return nil, err
+		}
+
+		input, refs, err := f.transactPut(ctx, v, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := f.doTransactWriteItems(ctx, client, input, refs, opts.ClientOptions)
+		if output == nil {
+			return nil, err
+		}
+
+		return &dynamodb.PutItemOutput{
+			ConsumedCapacity: firstConsumedCapacity(output.ConsumedCapacity),
+			ResultMetadata:   output.ResultMetadata,
+		}, err
+	}
 
-	input, err := f.Put(v, optFns...)
+	input, err := f.put(ctx, v, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	putItemOutput, err := client.PutItem(ctx, input)
+	putItemOutput, err := invoke(ctx, f, f.newPutRequest(v, input), client.PutItem, opts.ClientOptions)
 	if err != nil || opts.out == nil {
 		return putItemOutput, err
 	}
 
 	if item := putItemOutput.Attributes; len(item) != 0 {
-		err = f.Decoder.Decode(&types.AttributeValueMemberM{Value: item}, opts.out)
+		err = f.decode(item, opts.out)
 	}
 
 	return putItemOutput, err
@@ -7,24 +7,15 @@ import (
 
 // PutOpts customises [Fns.Put] operations per each invocation.
 type PutOpts struct {
+	RequestOptions
+
 	// DisableOptimisticLocking, if true, will skip all logic concerning version attribute.
 	DisableOptimisticLocking bool
 	// DisableAutoGeneratedTimestamps, if true, will skip all logic concerning timestamp attributes.
 	DisableAutoGeneratedTimestamps bool
 
-	// TableName modifies the [dynamodb.PutItemInput.TableName]
-	TableName *string
-	// ReturnConsumedCapacity modifies the [dynamodb.PutItemInput.ReturnConsumedCapacity]
-	ReturnConsumedCapacity types.ReturnConsumedCapacity
-	// ReturnItemCollectionMetrics modifies the [dynamodb.PutItemInput.ReturnItemCollectionMetrics]
-	ReturnItemCollectionMetrics types.ReturnItemCollectionMetrics
-	// ReturnValues modifies the [dynamodb.PutItemInput.ReturnValues]
-	ReturnValues types.ReturnValue
-	// ReturnValuesOnConditionCheckFailure modifies the [dynamodb.PutItemInput.ReturnValuesOnConditionCheckFailure].
-	ReturnValuesOnConditionCheckFailure types.ReturnValuesOnConditionCheckFailure
-
-	condition expression.ConditionBuilder
-	out       interface{}
+	// beforeBuild, if set, is called with the final item right before the expressions are built.
+	beforeBuild func(item map[string]types.AttributeValue) error
 }
 
 // Decode will decode the [dynamodb.PutItemOutput.Attributes] into the given struct pointer.
@@ -58,36 +49,12 @@ func (o *PutOpts) WithReturnValuesOnConditionCheckFailure(returnValuesOnConditio
 
 // And adds an expression.And to the condition expression.
 func (o *PutOpts) And(right expression.ConditionBuilder, other ...expression.ConditionBuilder) *PutOpts {
-	if o.condition.IsSet() {
-		o.condition = o.condition.And(right, other...)
-		return o
-	}
-
-	switch len(other) {
-	case 0:
-		o.condition = right
-	case 1:
-		o.condition = right.And(other[0])
-	default:
-		o.condition = right.And(other[0], other[1:]...)
-	}
+	o.and(right, other...)
 	return o
 }
 
-// Or adds an expression.And to the condition expression.
+// Or adds an expression.Or to the condition expression.
 func (o *PutOpts) Or(right expression.ConditionBuilder, other ...expression.ConditionBuilder) *PutOpts {
-	if o.condition.IsSet() {
-		o.condition = o.condition.Or(right, other...)
-		return o
-	}
-
-	switch len(other) {
-	case 0:
-		o.condition = right
-	case 1:
-		o.condition = right.Or(other[0])
-	default:
-		o.condition = right.Or(other[0], other[1:]...)
-	}
+	o.or(right, other...)
 	return o
 }
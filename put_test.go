@@ -1,6 +1,7 @@
 package ddbfns
 
 import (
+	"fmt"
 	"strconv"
 	"testing"
 	"time"
@@ -100,3 +101,68 @@ func TestFns_PutIncrementVersion(t *testing.T) {
 	assert.Equal(t, map[string]types.AttributeValue{":0": &types.AttributeValueMemberN{Value: "3"}}, got.ExpressionAttributeValues)
 	assert.Equal(t, map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "hello"}, "version": &types.AttributeValueMemberN{Value: "4"}}, got.Item)
 }
+
+func TestFns_PutImmutable(t *testing.T) {
+	type Test struct {
+		Id      string `dynamodbav:"id,hashkey" tableName:""`
+		OwnerId string `dynamodbav:"ownerId,immutable"`
+		Tenant  string `dynamodbav:"tenant,omitempty,immutable"`
+		Version int64  `dynamodbav:"version,version"`
+	}
+
+	// new items don't need the immutable conditions.
+	got, err := Put(Test{Id: "hello", OwnerId: "alice"})
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+	assert.Equal(t, "attribute_not_exists (#0)", *got.ConditionExpression)
+
+	got, err = Put(Test{Id: "hello", OwnerId: "alice", Version: 3})
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+	assert.Equal(t, "((#0 = :0) AND (#1 = :1)) AND (attribute_not_exists (#2))", *got.ConditionExpression)
+	assert.Equal(t, map[string]string{"#0": "version", "#1": "ownerId", "#2": "tenant"}, got.ExpressionAttributeNames)
+	assert.Equal(t, map[string]types.AttributeValue{":0": &types.AttributeValueMemberN{Value: "3"}, ":1": &types.AttributeValueMemberS{Value: "alice"}}, got.ExpressionAttributeValues)
+}
+
+type validatedTest struct {
+	Id    string `dynamodbav:"id,hashkey" tableName:""`
+	Email string `dynamodbav:"email" required:"true" maxLength:"10" pattern:"@"`
+	Color string `dynamodbav:"color" oneof:"red green"`
+	Count int    `dynamodbav:"count" min:"1" max:"5"`
+}
+
+func (v *validatedTest) Validate() error {
+	if v.Color == "green" && v.Count > 1 {
+		return fmt.Errorf("only one green allowed")
+	}
+
+	return nil
+}
+
+func TestFns_PutValidation(t *testing.T) {
+	_, err := Put(validatedTest{Id: "hello", Email: "alice@example.com", Color: "blue", Count: 0})
+
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []*FieldError{
+			{Field: "Email", Attribute: "email", Reason: "length 17 is greater than maxLength 10"},
+			{Field: "Color", Attribute: "color", Reason: `value "blue" is not one of [red green]`},
+			{Field: "Count", Attribute: "count", Reason: "value 0 is less than min 1"},
+		}, verr.Errors)
+	}
+
+	_, err = Put(&validatedTest{Id: "hello", Color: "green", Count: 2})
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []*FieldError{
+			{Field: "Email", Attribute: "email", Reason: "required attribute is empty"},
+			{Reason: "only one green allowed"},
+		}, verr.Errors)
+	}
+
+	_, err = Put(validatedTest{Id: "hello", Email: "a@b.c", Count: 1})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,129 @@
+package ddbfns
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// QueryOpts customises [Fns.DoQuery] and [Fns.DoScan] operations per each invocation.
+//
+// Of the [RequestOptions], only TableName, ReturnConsumedCapacity, and ClientOptions are used; TableName and
+// ReturnConsumedCapacity override the values in the input if given.
+type QueryOpts struct {
+	RequestOptions
+
+	// IncludeDeleted, if true, will also pass items that have been tombstoned by [Fns.SoftDelete] to the visitor.
+	IncludeDeleted bool
+}
+
+// WithTableName overrides [QueryOpts.TableName].
+func (o *QueryOpts) WithTableName(tableName string) *QueryOpts {
+	o.TableName = &tableName
+	return o
+}
+
+// DoQuery executes the Query request with the specified DynamoDB client, paginating until there are no more results.
+//
+// Each returned item is decoded with [Fns.DecodeItem] into the type registered with [RegisterType] and passed to the
+// visitor fn. If fn returns a non-nil error, pagination stops and that error is returned. Items that have been
+// tombstoned by [Fns.SoftDelete] are skipped unless [QueryOpts.IncludeDeleted] is true. History items written in
+// history mode (see [HistoryOpts]) and uniqueness sentinel items (see [UniqueOwnerAttribute]) are always skipped.
+func (f *Fns) DoQuery(ctx context.Context, client dynamodb.QueryAPIClient, input *dynamodb.QueryInput, fn func(v interface{}) error, optFns ...func(*QueryOpts)) error {
+	opts := applyOpts(optFns)
+
+	if opts.TableName != nil || opts.ReturnConsumedCapacity != "" {
+		copied := *input
+		copied.TableName, copied.ReturnConsumedCapacity = opts.tableName(input.TableName), opts.returnConsumedCapacity(input.ReturnConsumedCapacity)
+		input = &copied
+	}
+
+	paginator := dynamodb.NewQueryPaginator(f.queryClient(client), input)
+	for paginator.HasMorePages() {
+		queryOutput, err := paginator.NextPage(ctx, opts.ClientOptions...)
+		if err != nil {
+			return err
+		}
+
+		if err = f.visitItems(queryOutput.Items, opts, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DoScan executes the Scan request with the specified DynamoDB client, paginating until there are no more results.
+//
+// Each returned item is decoded with [Fns.DecodeItem] into the type registered with [RegisterType] and passed to the
+// visitor fn. If fn returns a non-nil error, pagination stops and that error is returned. Items that have been
+// tombstoned by [Fns.SoftDelete] are skipped unless [QueryOpts.IncludeDeleted] is true. History items written in
+// history mode (see [HistoryOpts]) and uniqueness sentinel items (see [UniqueOwnerAttribute]) are always skipped.
+func (f *Fns) DoScan(ctx context.Context, client dynamodb.ScanAPIClient, input *dynamodb.ScanInput, fn func(v interface{}) error, optFns ...func(*QueryOpts)) error {
+	opts := applyOpts(optFns)
+
+	if opts.TableName != nil || opts.ReturnConsumedCapacity != "" {
+		copied := *input
+		copied.TableName, copied.ReturnConsumedCapacity = opts.tableName(input.TableName), opts.returnConsumedCapacity(input.ReturnConsumedCapacity)
+		input = &copied
+	}
+
+	paginator := dynamodb.NewScanPaginator(f.scanClient(client), input)
+	for paginator.HasMorePages() {
+		scanOutput, err := paginator.NextPage(ctx, opts.ClientOptions...)
+		if err != nil {
+			return err
+		}
+
+		if err = f.visitItems(scanOutput.Items, opts, fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// visitItems decodes each item polymorphically and passes it to fn.
+func (f *Fns) visitItems(items []map[string]types.AttributeValue, opts *QueryOpts, fn func(v interface{}) error) error {
+	f.init.Do(f.initFn)
+
+	for _, item := range items {
+		if isHistoryItem(item) || isSentinelItem(item) {
+			continue
+		}
+
+		t, err := f.itemType(item)
+		if err != nil {
+			return err
+		}
+
+		if !opts.IncludeDeleted {
+			if m, err := f.loadOrParse(t); err == nil && isDeleted(m, item) {
+				continue
+			}
+		}
+
+		out := reflect.New(t).Interface()
+		if err = f.decode(item, out); err != nil {
+			return err
+		}
+
+		if err = fn(out); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DoQuery is a wrapper around [DefaultFns.DoQuery]; see [Fns.DoQuery] for more information.
+func DoQuery(ctx context.Context, client dynamodb.QueryAPIClient, input *dynamodb.QueryInput, fn func(v interface{}) error, optFns ...func(*QueryOpts)) error {
+	return DefaultFns.DoQuery(ctx, client, input, fn, optFns...)
+}
+
+// DoScan is a wrapper around [DefaultFns.DoScan]; see [Fns.DoScan] for more information.
+func DoScan(ctx context.Context, client dynamodb.ScanAPIClient, input *dynamodb.ScanInput, fn func(v interface{}) error, optFns ...func(*QueryOpts)) error {
+	return DefaultFns.DoScan(ctx, client, input, fn, optFns...)
+}
@@ -0,0 +1,460 @@
+package ddbfns
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Rate is a rate of read and write capacity units per second.
+type Rate struct {
+	ReadCapacityUnits  float64
+	WriteCapacityUnits float64
+}
+
+// RateLimiterOpts customises [NewRateLimiter].
+type RateLimiterOpts struct {
+	// Rate is the target rate of every table and index that is not in Rates.
+	//
+	// A zero ReadCapacityUnits or WriteCapacityUnits means reads or writes respectively are not limited.
+	Rate Rate
+	// Rates are the target rates of specific tables and indexes, keyed by table name for tables or by
+	// "table-name/index-name" for indexes.
+	Rates map[string]Rate
+	// MinRateFraction is the fraction of the target rate below which the rate will not adapt.
+	//
+	// Defaults to 0.1.
+	MinRateFraction float64
+	// RecoveryFraction is the fraction of the target rate that is added back after every successful request until the
+	// rate recovers to the target.
+	//
+	// Defaults to 0.05.
+	RecoveryFraction float64
+
+	// Clock returns the current time.
+	//
+	// If nil, [time.Now] is used.
+	Clock func() time.Time
+	// Sleep waits for the given duration or until the context is done.
+	//
+	// If nil, a timer is used. Tests may use a fake Sleep that advances a fake Clock instead.
+	Sleep func(ctx context.Context, d time.Duration) error
+}
+
+// RateLimiter is a client-side token bucket rate limiter that keeps the requests under target read and write capacity
+// unit rates for bulk jobs such as backfills.
+//
+// Every table and index has its own read and write buckets whose capacity is one second worth of the rate. Before each
+// request, the estimated capacity is taken from the buckets, waiting if they do not have enough tokens. The estimate is
+// based on the item size for PutItem and TransactWriteItems, and one capacity unit (half for eventually consistent
+// reads) otherwise. After the request, the difference between the actual ConsumedCapacity and the estimate is charged,
+// including the capacity consumed by indexes.
+//
+// Since a write may or may not update an index, the write buckets of the indexes of a table are estimated at zero
+// units; writes to the table then wait until those buckets have been refilled from the capacity charged by previous
+// writes. Only the indexes that are in Rates or that have shown up in a previous ConsumedCapacity are known.
+//
+// On ProvisionedThroughputExceededException, the rate of the bucket is halved (down to MinRateFraction of the target),
+// then recovers by RecoveryFraction of the target after every successful request.
+//
+// RateLimiter is installed with [Fns.Use]:
+//
+//	limiter := ddbfns.NewRateLimiter(func(opts *ddbfns.RateLimiterOpts) {
+//		opts.Rate = ddbfns.Rate{ReadCapacityUnits: 100, WriteCapacityUnits: 25}
+//	})
+//	f.Use(limiter.Middleware())
+//
+// RateLimiter is safe for concurrent use.
+type RateLimiter struct {
+	opts RateLimiterOpts
+
+	mu      sync.Mutex
+	buckets map[bucketKey]*bucket
+}
+
+// bucketKey identifies the read or write bucket of a table or index.
+type bucketKey struct {
+	name  string
+	write bool
+}
+
+type bucket struct {
+	target, rate float64
+	tokens       float64
+	last         time.Time
+}
+
+// NewRateLimiter creates a new RateLimiter.
+func NewRateLimiter(optFns ...func(*RateLimiterOpts)) *RateLimiter {
+	opts := RateLimiterOpts{
+		MinRateFraction:  0.1,
+		RecoveryFraction: 0.05,
+	}
+	for _, fn := range optFns {
+		fn(&opts)
+	}
+
+	if opts.Clock == nil {
+		opts.Clock = time.Now
+	}
+	if opts.Sleep == nil {
+		opts.Sleep = sleep
+	}
+
+	return &RateLimiter{opts: opts, buckets: make(map[bucketKey]*bucket)}
+}
+
+// Middleware returns the [Middleware] that limits the requests of [Fns].
+//
+// ReturnConsumedCapacity of the requests is set to INDEXES unless specified otherwise so that the actual capacity can
+// be charged.
+func (l *RateLimiter) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, req *Request) (interface{}, error) {
+			req.Input = withReturnConsumedCapacity(req.Input)
+
+			estimates := estimateCapacity(req)
+			l.addIndexWrites(estimates)
+			if err := l.wait(ctx, estimates); err != nil {
+				return nil, err
+			}
+
+			output, err := next(ctx, req)
+
+			var ptee *types.ProvisionedThroughputExceededException
+			if errors.As(err, &ptee) {
+				l.throttled(estimates)
+				return output, err
+			}
+
+			if cc := ConsumedCapacity(output); len(cc) != 0 {
+				l.charge(estimates, actualCapacity(req, cc))
+			}
+			if err == nil {
+				l.recover(estimates)
+			}
+
+			return output, err
+		}
+	}
+}
+
+// Rate returns the current rate of the table, or of the index if indexName is not empty.
+func (l *RateLimiter) Rate(tableName, indexName string) Rate {
+	name := bucketName(tableName, indexName)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return Rate{
+		ReadCapacityUnits:  l.bucket(bucketKey{name: name}).rate,
+		WriteCapacityUnits: l.bucket(bucketKey{name: name, write: true}).rate,
+	}
+}
+
+// bucket returns the bucket of the key, creating it full if necessary.
+//
+// Must be called while holding l.mu.
+func (l *RateLimiter) bucket(key bucketKey) *bucket {
+	b, ok := l.buckets[key]
+	if ok {
+		return b
+	}
+
+	rate, ok := l.opts.Rates[key.name]
+	if !ok {
+		rate = l.opts.Rate
+	}
+
+	target := rate.ReadCapacityUnits
+	if key.write {
+		target = rate.WriteCapacityUnits
+	}
+
+	b = &bucket{target: target, rate: target, tokens: target, last: l.opts.Clock()}
+	l.buckets[key] = b
+	return b
+}
+
+// addIndexWrites adds the known index write buckets of the tables being written to with zero units.
+func (l *RateLimiter) addIndexWrites(estimates map[bucketKey]float64) {
+	tables := make(map[string]bool)
+	for key := range estimates {
+		if key.write {
+			tables[key.name] = true
+		}
+	}
+	if len(tables) == 0 {
+		return
+	}
+
+	isIndexOf := func(name string) bool {
+		tableName, _, ok := strings.Cut(name, "/")
+		return ok && tables[tableName]
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for name := range l.opts.Rates {
+		if isIndexOf(name) {
+			estimates[bucketKey{name: name, write: true}] += 0
+		}
+	}
+	for key := range l.buckets {
+		if key.write && isIndexOf(key.name) {
+			estimates[key] += 0
+		}
+	}
+}
+
+// refill adds the tokens accrued since the last refill, up to one second worth of the rate.
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(b.rate, b.tokens+b.rate*elapsed.Seconds())
+		b.last = now
+	}
+}
+
+// wait takes the estimated capacity from the buckets, sleeping until the buckets have enough tokens.
+func (l *RateLimiter) wait(ctx context.Context, estimates map[bucketKey]float64) error {
+	l.mu.Lock()
+	now := l.opts.Clock()
+	var d time.Duration
+	for key, units := range estimates {
+		b := l.bucket(key)
+		if b.target <= 0 {
+			continue
+		}
+
+		b.refill(now)
+
+		// requests larger than the bucket only need a full bucket, while index buckets estimated at zero units only need
+		// to have paid back the capacity charged after the fact.
+		if need := math.Min(units, b.rate); b.tokens < need {
+			d = max(d, time.Duration((need-b.tokens)/b.rate*float64(time.Second)))
+		}
+		b.tokens -= units
+	}
+	l.mu.Unlock()
+
+	if d == 0 {
+		return nil
+	}
+
+	if err := l.opts.Sleep(ctx, d); err != nil {
+		// refund the tokens since the request will not be made.
+		l.mu.Lock()
+		for key, units := range estimates {
+			if b := l.bucket(key); b.target > 0 {
+				b.tokens += units
+			}
+		}
+		l.mu.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// charge takes the difference between the actual and estimated capacity from the buckets.
+func (l *RateLimiter) charge(estimates, actuals map[bucketKey]float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.opts.Clock()
+	for key, units := range actuals {
+		if b := l.bucket(key); b.target > 0 {
+			b.refill(now)
+			b.tokens -= units - estimates[key]
+		}
+	}
+}
+
+// throttled halves the rates of the buckets.
+//
+// The index buckets that were only estimated at zero units are not affected since the request may not have written to
+// them.
+func (l *RateLimiter) throttled(estimates map[bucketKey]float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, units := range estimates {
+		if b := l.bucket(key); b.target > 0 && units > 0 {
+			b.rate = math.Max(b.rate/2, b.target*l.opts.MinRateFraction)
+			b.tokens = math.Min(b.tokens, b.rate)
+		}
+	}
+}
+
+// recover increases the rates of the buckets towards their targets.
+func (l *RateLimiter) recover(estimates map[bucketKey]float64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, units := range estimates {
+		if b := l.bucket(key); b.target > 0 && units > 0 && b.rate < b.target {
+			b.rate = math.Min(b.rate+b.target*l.opts.RecoveryFraction, b.target)
+		}
+	}
+}
+
+func bucketName(tableName, indexName string) string {
+	if indexName == "" {
+		return tableName
+	}
+
+	return tableName + "/" + indexName
+}
+
+// estimateCapacity estimates the capacity units of the request by bucket.
+func estimateCapacity(req *Request) map[bucketKey]float64 {
+	estimates := make(map[bucketKey]float64)
+	readUnits := func(consistentRead *bool) float64 {
+		if consistentRead != nil && *consistentRead {
+			return 1
+		}
+		return 0.5
+	}
+
+	switch input := req.Input.(type) {
+	case *dynamodb.GetItemInput:
+		estimates[bucketKey{name: aws.ToString(input.TableName)}] = readUnits(input.ConsistentRead)
+	case *dynamodb.QueryInput:
+		estimates[bucketKey{name: bucketName(aws.ToString(input.TableName), aws.ToString(input.IndexName))}] = readUnits(input.ConsistentRead)
+	case *dynamodb.ScanInput:
+		estimates[bucketKey{name: bucketName(aws.ToString(input.TableName), aws.ToString(input.IndexName))}] = readUnits(input.ConsistentRead)
+	case *dynamodb.PutItemInput:
+		estimates[bucketKey{name: aws.ToString(input.TableName), write: true}] = writeUnits(input.Item)
+	case *dynamodb.UpdateItemInput:
+		estimates[bucketKey{name: aws.ToString(input.TableName), write: true}] = 1
+	case *dynamodb.DeleteItemInput:
+		estimates[bucketKey{name: aws.ToString(input.TableName), write: true}] = 1
+	case *dynamodb.TransactWriteItemsInput:
+		// transactions consume twice the capacity.
+		for _, item := range input.TransactItems {
+			switch {
+			case item.Put != nil:
+				estimates[bucketKey{name: aws.ToString(item.Put.TableName), write: true}] += 2 * writeUnits(item.Put.Item)
+			case item.Update != nil:
+				estimates[bucketKey{name: aws.ToString(item.Update.TableName), write: true}] += 2
+			case item.Delete != nil:
+				estimates[bucketKey{name: aws.ToString(item.Delete.TableName), write: true}] += 2
+			case item.ConditionCheck != nil:
+				estimates[bucketKey{name: aws.ToString(item.ConditionCheck.TableName), write: true}] += 2
+			}
+		}
+	}
+
+	return estimates
+}
+
+// actualCapacity returns the consumed capacity units by bucket.
+func actualCapacity(req *Request, cc []types.ConsumedCapacity) map[bucketKey]float64 {
+	write := isWriteOperation(req.Operation)
+	units := func(c Capacity) float64 {
+		if write {
+			return c.WriteCapacityUnits
+		}
+		return c.ReadCapacityUnits
+	}
+
+	actuals := make(map[bucketKey]float64)
+	for _, c := range cc {
+		tableName := aws.ToString(c.TableName)
+
+		// for Query and Scan on an index, the index bucket is the one that was estimated.
+		if req.IndexName != "" {
+			actuals[bucketKey{name: bucketName(tableName, req.IndexName)}] += units(toCapacity(c.CapacityUnits, c.ReadCapacityUnits, c.WriteCapacityUnits, write))
+			continue
+		}
+
+		if c.Table != nil {
+			actuals[bucketKey{name: tableName, write: write}] += units(toCapacity(c.Table.CapacityUnits, c.Table.ReadCapacityUnits, c.Table.WriteCapacityUnits, write))
+		} else {
+			actuals[bucketKey{name: tableName, write: write}] += units(toCapacity(c.CapacityUnits, c.ReadCapacityUnits, c.WriteCapacityUnits, write))
+		}
+
+		for _, indexes := range []map[string]types.Capacity{c.GlobalSecondaryIndexes, c.LocalSecondaryIndexes} {
+			for indexName, ic := range indexes {
+				actuals[bucketKey{name: bucketName(tableName, indexName), write: write}] += units(toCapacity(ic.CapacityUnits, ic.ReadCapacityUnits, ic.WriteCapacityUnits, write))
+			}
+		}
+	}
+
+	return actuals
+}
+
+// writeUnits estimates the write capacity units of the item, one per KB rounded up.
+func writeUnits(item map[string]types.AttributeValue) float64 {
+	return math.Max(1, math.Ceil(float64(itemSize(item))/1024))
+}
+
+// itemSize estimates the size of the item in bytes, which is the sum of the lengths of its attribute names and values.
+func itemSize(item map[string]types.AttributeValue) int {
+	size := 0
+	for name, av := range item {
+		size += len(name) + attributeValueSize(av)
+	}
+
+	return size
+}
+
+func attributeValueSize(av types.AttributeValue) int {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return len(v.Value)
+	case *types.AttributeValueMemberN:
+		return len(v.Value)/2 + 1
+	case *types.AttributeValueMemberB:
+		return len(v.Value)
+	case *types.AttributeValueMemberSS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)
+		}
+		return size
+	case *types.AttributeValueMemberNS:
+		size := 0
+		for _, s := range v.Value {
+			size += len(s)/2 + 1
+		}
+		return size
+	case *types.AttributeValueMemberBS:
+		size := 0
+		for _, b := range v.Value {
+			size += len(b)
+		}
+		return size
+	case *types.AttributeValueMemberL:
+		size := 3
+		for _, e := range v.Value {
+			size += 1 + attributeValueSize(e)
+		}
+		return size
+	case *types.AttributeValueMemberM:
+		return 3 + itemSize(v.Value)
+	default:
+		return 1
+	}
+}
+
+// sleep waits for the duration or until the context is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,196 @@
+package ddbfns
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeClock is a clock that only advances when Sleep is called.
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func (c *fakeClock) Sleep(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+	return nil
+}
+
+func newTestRateLimiter(clock *fakeClock, optFns ...func(*RateLimiterOpts)) *RateLimiter {
+	return NewRateLimiter(append([]func(*RateLimiterOpts){func(opts *RateLimiterOpts) {
+		opts.Clock = clock.Now
+		opts.Sleep = clock.Sleep
+	}}, optFns...)...)
+}
+
+func TestRateLimiter(t *testing.T) {
+	clock := &fakeClock{now: testTime}
+	limiter := newTestRateLimiter(clock, func(opts *RateLimiterOpts) {
+		opts.Rate = Rate{ReadCapacityUnits: 2, WriteCapacityUnits: 1}
+	})
+
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		assert.Contains(t, string(body), `"ReturnConsumedCapacity":"INDEXES"`)
+		return 200, `{}`
+	})
+
+	f := &Fns{}
+	f.Use(limiter.Middleware())
+
+	// the write bucket starts full with 1 token, so only the first put doesn't wait.
+	for i := 0; i < 3; i++ {
+		_, err := f.DoPut(context.Background(), client, middlewareTest{Id: "hello", Sort: "world"})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []time.Duration{time.Second, time.Second}, clock.sleeps)
+
+	// the read bucket is separate and starts full with 2 tokens; eventually consistent reads are estimated at 0.5 RCU
+	// each so only the fifth get waits.
+	clock.sleeps = nil
+	for i := 0; i < 5; i++ {
+		_, err := f.DoGet(context.Background(), client, middlewareTest{Id: "hello", Sort: "world"})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []time.Duration{250 * time.Millisecond}, clock.sleeps)
+}
+
+func TestRateLimiter_chargesActualCapacity(t *testing.T) {
+	clock := &fakeClock{now: testTime}
+	limiter := newTestRateLimiter(clock, func(opts *RateLimiterOpts) {
+		opts.Rate = Rate{WriteCapacityUnits: 1}
+		opts.Rates = map[string]Rate{"my-table/my-index": {WriteCapacityUnits: 4}}
+	})
+
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		return 200, `{"ConsumedCapacity":{"TableName":"my-table","CapacityUnits":5,"Table":{"CapacityUnits":3},"GlobalSecondaryIndexes":{"my-index":{"CapacityUnits":2}}}}`
+	})
+
+	f := &Fns{}
+	f.Use(limiter.Middleware())
+
+	_, err := f.DoUpdate(context.Background(), client, middlewareTest{Id: "hello", Sort: "world"}, func(opts *UpdateOpts) {
+		opts.Set("notes", "hi")
+	})
+	assert.NoError(t, err)
+	assert.Empty(t, clock.sleeps)
+
+	// the table bucket is now at -2 tokens (1 - 3) so the next update waits 3 seconds to get 1 token back.
+	_, err = f.DoUpdate(context.Background(), client, middlewareTest{Id: "hello", Sort: "world"}, func(opts *UpdateOpts) {
+		opts.Set("notes", "hi")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []time.Duration{3 * time.Second}, clock.sleeps)
+
+	// the index bucket has its own rate and is charged after the fact: it refilled to 4 tokens before the second
+	// update took 2.
+	limiter.mu.Lock()
+	assert.Equal(t, float64(2), limiter.buckets[bucketKey{name: "my-table/my-index", write: true}].tokens)
+	limiter.mu.Unlock()
+}
+
+func TestRateLimiter_indexWrites(t *testing.T) {
+	clock := &fakeClock{now: testTime}
+	limiter := newTestRateLimiter(clock, func(opts *RateLimiterOpts) {
+		opts.Rate = Rate{WriteCapacityUnits: 10}
+		opts.Rates = map[string]Rate{"my-table/my-index": {WriteCapacityUnits: 1}}
+	})
+
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		return 200, `{"ConsumedCapacity":{"TableName":"my-table","CapacityUnits":3,"Table":{"CapacityUnits":1},"GlobalSecondaryIndexes":{"my-index":{"CapacityUnits":2}}}}`
+	})
+
+	f := &Fns{}
+	f.Use(limiter.Middleware())
+
+	// the table bucket has plenty of tokens, but the index bucket is at -1 token (1 - 2) after the first update so the
+	// second update waits 1 second for the index to pay back its capacity.
+	for i := 0; i < 2; i++ {
+		_, err := f.DoUpdate(context.Background(), client, middlewareTest{Id: "hello", Sort: "world"}, func(opts *UpdateOpts) {
+			opts.Set("notes", "hi")
+		})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []time.Duration{time.Second}, clock.sleeps)
+}
+
+func TestRateLimiter_adaptive(t *testing.T) {
+	clock := &fakeClock{now: testTime}
+	limiter := newTestRateLimiter(clock, func(opts *RateLimiterOpts) {
+		opts.Rate = Rate{ReadCapacityUnits: 100}
+		opts.RecoveryFraction = 0.25
+	})
+
+	throttle := true
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		if throttle {
+			return 400, `{"__type":"com.amazonaws.dynamodb.v20120810#ProvisionedThroughputExceededException","message":"slow down"}`
+		}
+		return 200, `{"Items":[]}`
+	})
+
+	f := &Fns{}
+	f.Use(limiter.Middleware())
+
+	input := &dynamodb.QueryInput{TableName: aws.String("my-table"), IndexName: aws.String("my-index")}
+	for _, expected := range []float64{50, 25, 12.5, 10, 10} {
+		err := f.DoQuery(context.Background(), client, input, func(v interface{}) error { return nil })
+		var ptee *types.ProvisionedThroughputExceededException
+		assert.ErrorAs(t, err, &ptee)
+		assert.Equal(t, Rate{ReadCapacityUnits: expected}, limiter.Rate("my-table", "my-index"))
+	}
+
+	// the table itself is not affected.
+	assert.Equal(t, Rate{ReadCapacityUnits: 100}, limiter.Rate("my-table", ""))
+
+	throttle = false
+	for _, expected := range []float64{35, 60, 85, 100, 100} {
+		err := f.DoQuery(context.Background(), client, input, func(v interface{}) error { return nil })
+		assert.NoError(t, err)
+		assert.Equal(t, Rate{ReadCapacityUnits: expected}, limiter.Rate("my-table", "my-index"))
+	}
+}
+
+func TestRateLimiter_cancelled(t *testing.T) {
+	clock := &fakeClock{now: testTime}
+	limiter := newTestRateLimiter(clock, func(opts *RateLimiterOpts) {
+		opts.Rate = Rate{WriteCapacityUnits: 1}
+	})
+
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		return 200, `{}`
+	})
+
+	f := &Fns{}
+	f.Use(limiter.Middleware())
+
+	// a 3KB item needs 3 WCUs; the bucket only needs to be full for the request to go through.
+	item := middlewareTest{Id: "hello", Sort: "world", Notes: strings.Repeat("a", 3000)}
+	_, err := f.DoPut(context.Background(), client, item)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = f.DoPut(ctx, client, item)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// the tokens of the cancelled request are refunded.
+	limiter.mu.Lock()
+	assert.Equal(t, float64(-2), limiter.buckets[bucketKey{name: "my-table", write: true}].tokens)
+	limiter.mu.Unlock()
+}
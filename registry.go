@@ -0,0 +1,139 @@
+package ddbfns
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// DefaultTypeAttribute is the default name of the type discriminator attribute.
+//
+// See [Fns.TypeAttribute] and [RegisterType].
+const DefaultTypeAttribute = "_type"
+
+// ErrUnknownType is returned by [Fns.DecodeItem] if the item's type discriminator attribute is missing or its value
+// has not been registered with [RegisterType].
+var ErrUnknownType = errors.New("unknown item type")
+
+// RegisterType registers struct type T with the given type name for polymorphic decoding.
+//
+// Once registered, [Fns.Put] will automatically write typeName to the type discriminator attribute (see
+// [Fns.TypeAttribute]) of every item of type T, and [Fns.DecodeItem] will use that attribute to decode items into a new
+// *T. This is useful in single-table designs where a Query returns heterogeneous items:
+//
+//	ddbfns.RegisterType[Order](fns, "Order")
+//	ddbfns.RegisterType[LineItem](fns, "LineItem")
+//
+//	err := fns.DoQuery(ctx, client, input, func(v interface{}) error {
+//		switch v := v.(type) {
+//		case *Order:
+//		case *LineItem:
+//		}
+//		return nil
+//	})
+//
+// Returns an error if T fails parsing, or if typeName or T has already been registered with a different counterpart.
+func RegisterType[T any](f *Fns, typeName string, optFns ...func(*ParseOpts)) error {
+	t := reflect.TypeFor[T]()
+	if err := f.ParseFromType(t, optFns...); err != nil {
+		return err
+	}
+
+	f.typesMu.Lock()
+	defer f.typesMu.Unlock()
+
+	if f.typesByName == nil {
+		f.typesByName = make(map[string]reflect.Type)
+		f.namesByType = make(map[reflect.Type]string)
+	}
+
+	if other, ok := f.typesByName[typeName]; ok && other != t {
+		return fmt.Errorf(`type name "%s" has already been registered to type "%s"`, typeName, other)
+	}
+	if other, ok := f.namesByType[t]; ok && other != typeName {
+		return fmt.Errorf(`type "%s" has already been registered with type name "%s"`, t, other)
+	}
+
+	f.typesByName[typeName] = t
+	f.namesByType[t] = typeName
+	return nil
+}
+
+// DecodeItem decodes the given item into a new pointer to the struct type registered with [RegisterType].
+//
+// The type is determined by the value of the type discriminator attribute (see [Fns.TypeAttribute]). The returned
+// value is always a pointer such as *Order. Like the Decode paths of DoXyz methods, composite keys are parsed and
+// [AfterDecodeHook] is called.
+//
+// Returns an error wrapping ErrUnknownType if the discriminator attribute is missing or has an unregistered value.
+func (f *Fns) DecodeItem(item map[string]types.AttributeValue) (interface{}, error) {
+	f.init.Do(f.initFn)
+
+	t, err := f.itemType(item)
+	if err != nil {
+		return nil, err
+	}
+
+	out := reflect.New(t).Interface()
+	if err := f.decode(item, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// DecodeItems is the batch version of [Fns.DecodeItem].
+//
+// The returned slice has the same length and order as items. The first decoding error is returned.
+func (f *Fns) DecodeItems(items []map[string]types.AttributeValue) ([]interface{}, error) {
+	values := make([]interface{}, len(items))
+	for i, item := range items {
+		v, err := f.DecodeItem(item)
+		if err != nil {
+			return nil, err
+		}
+
+		values[i] = v
+	}
+
+	return values, nil
+}
+
+// itemType returns the struct type registered for the item's type discriminator attribute.
+func (f *Fns) itemType(item map[string]types.AttributeValue) (reflect.Type, error) {
+	name := f.typeAttribute()
+	av, ok := item[name].(*types.AttributeValueMemberS)
+	if !ok {
+		return nil, fmt.Errorf(`%w: missing type attribute "%s"`, ErrUnknownType, name)
+	}
+
+	f.typesMu.RLock()
+	t, ok := f.typesByName[av.Value]
+	f.typesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf(`%w: "%s"`, ErrUnknownType, av.Value)
+	}
+
+	return t, nil
+}
+
+// typeAttribute returns the name of the type discriminator attribute.
+func (f *Fns) typeAttribute() string {
+	if f.TypeAttribute != "" {
+		return f.TypeAttribute
+	}
+
+	return DefaultTypeAttribute
+}
+
+// typeName returns the type name registered for the struct type of the given model.
+func (f *Fns) typeName(m *internal.Model) (string, bool) {
+	f.typesMu.RLock()
+	defer f.typesMu.RUnlock()
+
+	name, ok := f.namesByType[m.StructType]
+	return name, ok
+}
@@ -0,0 +1,78 @@
+package ddbfns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type orderTest struct {
+	PK string `dynamodbav:"pk,hashkey" tableName:"orders"`
+	SK string `dynamodbav:"sk,sortkey"`
+}
+
+type lineItemTest struct {
+	PK  string `dynamodbav:"pk,hashkey" tableName:"orders"`
+	SK  string `dynamodbav:"sk,sortkey"`
+	Qty int    `dynamodbav:"qty"`
+}
+
+// fakeQueryClient returns each page in order.
+type fakeQueryClient struct {
+	pages  []*dynamodb.QueryOutput
+	inputs []*dynamodb.QueryInput
+	calls  int
+}
+
+func (c *fakeQueryClient) Query(_ context.Context, input *dynamodb.QueryInput, _ ...func(*dynamodb.Options)) (*dynamodb.QueryOutput, error) {
+	c.inputs = append(c.inputs, input)
+	page := c.pages[c.calls]
+	c.calls++
+	return page, nil
+}
+
+func TestFns_DecodeItem(t *testing.T) {
+	f := &Fns{}
+	assert.NoError(t, RegisterType[orderTest](f, "Order"))
+	assert.NoError(t, RegisterType[lineItemTest](f, "LineItem"))
+	assert.Error(t, RegisterType[lineItemTest](f, "Order"))
+
+	putItemInput, err := f.Put(orderTest{PK: "ORDER#1", SK: "META"})
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "Order"}, putItemInput.Item["_type"])
+
+	client := &fakeQueryClient{pages: []*dynamodb.QueryOutput{
+		{
+			Items:            []map[string]types.AttributeValue{putItemInput.Item},
+			LastEvaluatedKey: map[string]types.AttributeValue{"pk": &types.AttributeValueMemberS{Value: "ORDER#1"}},
+		},
+		{
+			Items: []map[string]types.AttributeValue{{
+				"pk":    &types.AttributeValueMemberS{Value: "ORDER#1"},
+				"sk":    &types.AttributeValueMemberS{Value: "ITEM#1"},
+				"qty":   &types.AttributeValueMemberN{Value: "3"},
+				"_type": &types.AttributeValueMemberS{Value: "LineItem"},
+			}},
+		},
+	}}
+
+	var got []interface{}
+	err = f.DoQuery(context.Background(), client, &dynamodb.QueryInput{}, func(v interface{}) error {
+		got = append(got, v)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		&orderTest{PK: "ORDER#1", SK: "META"},
+		&lineItemTest{PK: "ORDER#1", SK: "ITEM#1", Qty: 3},
+	}, got)
+
+	_, err = f.DecodeItems([]map[string]types.AttributeValue{{"_type": &types.AttributeValueMemberS{Value: "Shipment"}}})
+	assert.ErrorIs(t, err, ErrUnknownType)
+}
@@ -0,0 +1,113 @@
+package ddbfns
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// RequestOptions contains the options shared by [GetOpts], [PutOpts], [UpdateOpts], [DeleteOpts], [QueryOpts], and
+// [LoadCollectionOpts].
+//
+// Fields that are not applicable to an operation are ignored; for example, GetItem has no ReturnValues.
+type RequestOptions struct {
+	// TableName modifies the TableName of the request.
+	//
+	// If nil, the `tableName` tag of the hashkey field is used.
+	TableName *string
+	// ReturnConsumedCapacity modifies the ReturnConsumedCapacity of the request.
+	//
+	// If empty and the context has a CapacityTracker (see WithCapacityTracker), INDEXES is used.
+	ReturnConsumedCapacity types.ReturnConsumedCapacity
+	// ReturnItemCollectionMetrics modifies the ReturnItemCollectionMetrics of the request.
+	ReturnItemCollectionMetrics types.ReturnItemCollectionMetrics
+	// ReturnValues modifies the ReturnValues of the request.
+	ReturnValues types.ReturnValue
+	// ReturnValuesOnConditionCheckFailure modifies the ReturnValuesOnConditionCheckFailure of the request.
+	ReturnValuesOnConditionCheckFailure types.ReturnValuesOnConditionCheckFailure
+
+	// Clock returns the time used for the auto-generated timestamps.
+	//
+	// If nil, [time.Now] is used.
+	Clock func() time.Time
+	// ClientOptions are passed to the DynamoDB client by the DoXyz methods.
+	//
+	// This is useful to add per-request middleware via [dynamodb.Options.APIOptions].
+	ClientOptions []func(*dynamodb.Options)
+
+	condition expression.ConditionBuilder
+	out       interface{}
+}
+
+// now returns the current time from Clock or time.Now.
+func (o *RequestOptions) now() time.Time {
+	if o.Clock != nil {
+		return o.Clock()
+	}
+
+	return time.Now()
+}
+
+// tableName returns TableName if given, or the given default value otherwise.
+func (o *RequestOptions) tableName(defaultValue *string) *string {
+	if o.TableName != nil {
+		return o.TableName
+	}
+
+	return defaultValue
+}
+
+// returnConsumedCapacity returns ReturnConsumedCapacity if given, or the given default value otherwise.
+func (o *RequestOptions) returnConsumedCapacity(defaultValue types.ReturnConsumedCapacity) types.ReturnConsumedCapacity {
+	if o.ReturnConsumedCapacity != "" {
+		return o.ReturnConsumedCapacity
+	}
+
+	return defaultValue
+}
+
+// and adds an expression.And to the condition expression.
+func (o *RequestOptions) and(right expression.ConditionBuilder, other ...expression.ConditionBuilder) {
+	if o.condition.IsSet() {
+		o.condition = o.condition.And(right, other...)
+		return
+	}
+
+	switch len(other) {
+	case 0:
+		o.condition = right
+	case 1:
+		o.condition = right.And(other[0])
+	default:
+		o.condition = right.And(other[0], other[1:]...)
+	}
+}
+
+// or adds an expression.Or to the condition expression.
+func (o *RequestOptions) or(right expression.ConditionBuilder, other ...expression.ConditionBuilder) {
+	if o.condition.IsSet() {
+		o.condition = o.condition.Or(right, other...)
+		return
+	}
+
+	switch len(other) {
+	case 0:
+		o.condition = right
+	case 1:
+		o.condition = right.Or(other[0])
+	default:
+		o.condition = right.Or(other[0], other[1:]...)
+	}
+}
+
+// applyOpts creates the opts and applies the given functional options in order.
+func applyOpts[T any](optFns []func(*T)) *T {
+	opts := new(T)
+	for _, fn := range optFns {
+		fn(opts)
+	}
+
+	return opts
+}
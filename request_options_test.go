@@ -0,0 +1,83 @@
+package ddbfns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestOptions_Clock(t *testing.T) {
+	type Test struct {
+		Id           string    `dynamodbav:"id,hashkey" tableName:""`
+		Version      int64     `dynamodbav:"version,version"`
+		CreatedTime  time.Time `dynamodbav:"createdTime,createdTime"`
+		ModifiedTime time.Time `dynamodbav:"modifiedTime,modifiedTime,unixtime"`
+	}
+
+	clock := func() time.Time {
+		return testTime
+	}
+
+	got, err := Put(Test{Id: "hello"}, func(opts *PutOpts) {
+		opts.Clock = clock
+	})
+	if err != nil {
+		t.Errorf("Put() error = %v", err)
+		return
+	}
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "2006-01-02T15:04:05Z"}, got.Item["createdTime"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1136214245"}, got.Item["modifiedTime"])
+
+	got2, err := Update(Test{Id: "hello", Version: 1}, func(opts *UpdateOpts) {
+		opts.Set("notes", "hello")
+	}, func(opts *UpdateOpts) {
+		opts.Clock = clock
+	})
+	if err != nil {
+		t.Errorf("Update() error = %v", err)
+		return
+	}
+	assert.Contains(t, got2.ExpressionAttributeValues, ":3")
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "1136214245"}, got2.ExpressionAttributeValues[":3"])
+}
+
+func TestRequestOptions_Delete(t *testing.T) {
+	type Test struct {
+		Id string `dynamodbav:"id,hashkey" tableName:""`
+	}
+
+	got, err := Delete(Test{Id: "hello"}, func(opts *DeleteOpts) {
+		opts.WithTableName("my-table").WithReturnValues(types.ReturnValueAllOld)
+	})
+	if err != nil {
+		t.Errorf("Delete() error = %v", err)
+		return
+	}
+	assert.Equal(t, "my-table", *got.TableName)
+	assert.Equal(t, types.ReturnValueAllOld, got.ReturnValues)
+}
+
+func TestRequestOptions_Query(t *testing.T) {
+	f := &Fns{}
+	assert.NoError(t, RegisterType[orderTest](f, "Order"))
+
+	input := &dynamodb.QueryInput{TableName: aws.String("original")}
+	client := &fakeQueryClient{pages: []*dynamodb.QueryOutput{{}}}
+	err := f.DoQuery(context.Background(), client, input, func(v interface{}) error {
+		return nil
+	}, func(opts *QueryOpts) {
+		opts.WithTableName("override")
+	})
+	if err != nil {
+		t.Errorf("DoQuery() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, "override", *client.inputs[0].TableName)
+	assert.Equal(t, "original", *input.TableName)
+}
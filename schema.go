@@ -0,0 +1,257 @@
+package ddbfns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// CheckSchemaAPIClient is the subset of the DynamoDB client used by [Fns.CheckSchema].
+type CheckSchemaAPIClient interface {
+	dynamodb.DescribeTableAPIClient
+	DescribeTimeToLive(ctx context.Context, params *dynamodb.DescribeTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error)
+}
+
+// SchemaMismatchKind is the kind of a SchemaMismatch.
+type SchemaMismatchKind string
+
+const (
+	// SchemaMismatchMissingTableName means the struct's `tableName` tag is empty so its table cannot be checked.
+	SchemaMismatchMissingTableName SchemaMismatchKind = "MissingTableName"
+	// SchemaMismatchTableNotFound means DescribeTable returned ResourceNotFoundException.
+	SchemaMismatchTableNotFound SchemaMismatchKind = "TableNotFound"
+	// SchemaMismatchKeyName means the name of a hash or range key differs.
+	SchemaMismatchKeyName SchemaMismatchKind = "KeyName"
+	// SchemaMismatchKeyType means the scalar type (S, N, or B) of a key attribute differs.
+	SchemaMismatchKeyType SchemaMismatchKind = "KeyType"
+	// SchemaMismatchMissingIndex means a secondary index declared with `gsi` or `lsi` tags does not exist.
+	SchemaMismatchMissingIndex SchemaMismatchKind = "MissingIndex"
+	// SchemaMismatchTTL means TTL is not enabled on the struct's ttl attribute.
+	SchemaMismatchTTL SchemaMismatchKind = "TTL"
+)
+
+// SchemaMismatch is a single difference between a struct and its table.
+type SchemaMismatch struct {
+	Kind SchemaMismatchKind
+	// Index is the name of the secondary index, or empty for the table itself.
+	Index string
+	// KeyType is the key (HASH or RANGE) that mismatched, if applicable.
+	KeyType types.KeyType
+	// Expected is the value derived from the struct tags.
+	Expected string
+	// Actual is the value described by DynamoDB.
+	Actual string
+}
+
+// String returns a human-readable description of the mismatch.
+func (m SchemaMismatch) String() string {
+	var b strings.Builder
+	b.WriteString(string(m.Kind))
+	if m.Index != "" {
+		_, _ = fmt.Fprintf(&b, ` index "%s"`, m.Index)
+	}
+	if m.KeyType != "" {
+		_, _ = fmt.Fprintf(&b, " %s key", m.KeyType)
+	}
+	if m.Expected != "" || m.Actual != "" {
+		_, _ = fmt.Fprintf(&b, `: expected "%s", got "%s"`, m.Expected, m.Actual)
+	}
+
+	return b.String()
+}
+
+// TableSchemaReport is the result of checking one struct against its table.
+type TableSchemaReport struct {
+	// Type is the struct type that was checked.
+	Type reflect.Type
+	// TableName is the table that was described; empty if the struct has no table name.
+	TableName string
+	// Mismatches is empty if the table matches the struct.
+	Mismatches []SchemaMismatch
+}
+
+// SchemaReport is returned by [Fns.CheckSchema].
+type SchemaReport struct {
+	Tables []*TableSchemaReport
+}
+
+// OK returns true if there are no mismatches.
+func (r *SchemaReport) OK() bool {
+	for _, t := range r.Tables {
+		if len(t.Mismatches) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Err returns a non-nil error describing all the mismatches, or nil if there are none.
+//
+// This is convenient for startup health checks that only need to fail fast.
+func (r *SchemaReport) Err() error {
+	var errs []error
+	for _, t := range r.Tables {
+		for _, m := range t.Mismatches {
+			errs = append(errs, fmt.Errorf(`type "%s" table "%s": %s`, t.Type, t.TableName, m))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckSchema compares the given structs against their tables and reports the differences.
+//
+// For each struct, DescribeTable and DescribeTimeToLive are called on the table from its `tableName` tag, and the
+// following are checked:
+//   - the names and scalar types of the hash and range keys.
+//   - every index declared with `gsi` or `lsi` tags exists with the same keys.
+//   - TTL is enabled on the ttl attribute, if any.
+//
+// Indexes and attributes that exist in the table but are not declared by the struct are not reported since multiple
+// structs can share the same table. A table that does not exist is reported as a mismatch; other errors from DynamoDB
+// are returned as-is.
+func (f *Fns) CheckSchema(ctx context.Context, client CheckSchemaAPIClient, models ...interface{}) (*SchemaReport, error) {
+	f.init.Do(f.initFn)
+
+	report := &SchemaReport{}
+	for _, v := range models {
+		t := reflect.TypeOf(v)
+		m, err := f.loadOrParse(t)
+		if err != nil {
+			return nil, err
+		}
+
+		tr := &TableSchemaReport{Type: m.StructType}
+		report.Tables = append(report.Tables, tr)
+
+		if m.TableName == nil {
+			tr.Mismatches = append(tr.Mismatches, SchemaMismatch{Kind: SchemaMismatchMissingTableName})
+			continue
+		}
+		tr.TableName = *m.TableName
+
+		expected, ttl, err := f.createTableInput(t, &CreateTableOpts{})
+		if err != nil {
+			return nil, err
+		}
+
+		describeTableOutput, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: expected.TableName})
+		if err != nil {
+			var rnfe *types.ResourceNotFoundException
+			if errors.As(err, &rnfe) {
+				tr.Mismatches = append(tr.Mismatches, SchemaMismatch{Kind: SchemaMismatchTableNotFound})
+				continue
+			}
+
+			return nil, fmt.Errorf("describe table error: %w", err)
+		}
+
+		actual := describeTableOutput.Table
+		c := &schemaComparer{
+			expectedDefs: expected.AttributeDefinitions,
+			actualDefs:   actual.AttributeDefinitions,
+		}
+
+		c.compareKeys("", expected.KeySchema, actual.KeySchema)
+
+		for _, gsi := range expected.GlobalSecondaryIndexes {
+			i := slices.IndexFunc(actual.GlobalSecondaryIndexes, func(d types.GlobalSecondaryIndexDescription) bool {
+				return *d.IndexName == *gsi.IndexName
+			})
+			if i == -1 {
+				c.mismatches = append(c.mismatches, SchemaMismatch{Kind: SchemaMismatchMissingIndex, Index: *gsi.IndexName})
+				continue
+			}
+
+			c.compareKeys(*gsi.IndexName, gsi.KeySchema, actual.GlobalSecondaryIndexes[i].KeySchema)
+		}
+
+		for _, lsi := range expected.LocalSecondaryIndexes {
+			i := slices.IndexFunc(actual.LocalSecondaryIndexes, func(d types.LocalSecondaryIndexDescription) bool {
+				return *d.IndexName == *lsi.IndexName
+			})
+			if i == -1 {
+				c.mismatches = append(c.mismatches, SchemaMismatch{Kind: SchemaMismatchMissingIndex, Index: *lsi.IndexName})
+				continue
+			}
+
+			c.compareKeys(*lsi.IndexName, lsi.KeySchema, actual.LocalSecondaryIndexes[i].KeySchema)
+		}
+
+		if ttl != nil {
+			describeTimeToLiveOutput, err := client.DescribeTimeToLive(ctx, &dynamodb.DescribeTimeToLiveInput{TableName: expected.TableName})
+			if err != nil {
+				return nil, fmt.Errorf("describe time to live error: %w", err)
+			}
+
+			var actualTTL string
+			if d := describeTimeToLiveOutput.TimeToLiveDescription; d != nil && d.AttributeName != nil &&
+				(d.TimeToLiveStatus == types.TimeToLiveStatusEnabled || d.TimeToLiveStatus == types.TimeToLiveStatusEnabling) {
+				actualTTL = *d.AttributeName
+			}
+
+			if actualTTL != *ttl.AttributeName {
+				c.mismatches = append(c.mismatches, SchemaMismatch{Kind: SchemaMismatchTTL, Expected: *ttl.AttributeName, Actual: actualTTL})
+			}
+		}
+
+		tr.Mismatches = c.mismatches
+	}
+
+	return report, nil
+}
+
+// CheckSchema is a wrapper around [DefaultFns.CheckSchema]; see [Fns.CheckSchema] for more information.
+func CheckSchema(ctx context.Context, client CheckSchemaAPIClient, models ...interface{}) (*SchemaReport, error) {
+	return DefaultFns.CheckSchema(ctx, client, models...)
+}
+
+type schemaComparer struct {
+	expectedDefs, actualDefs []types.AttributeDefinition
+	mismatches               []SchemaMismatch
+}
+
+// compareKeys compares the HASH and RANGE keys of the table or an index.
+func (c *schemaComparer) compareKeys(index string, expected, actual []types.KeySchemaElement) {
+	for _, keyType := range []types.KeyType{types.KeyTypeHash, types.KeyTypeRange} {
+		expectedName, actualName := keyName(expected, keyType), keyName(actual, keyType)
+		if expectedName != actualName {
+			c.mismatches = append(c.mismatches, SchemaMismatch{Kind: SchemaMismatchKeyName, Index: index, KeyType: keyType, Expected: expectedName, Actual: actualName})
+			continue
+		}
+		if expectedName == "" {
+			continue
+		}
+
+		if expectedType, actualType := attributeType(c.expectedDefs, expectedName), attributeType(c.actualDefs, actualName); expectedType != actualType {
+			c.mismatches = append(c.mismatches, SchemaMismatch{Kind: SchemaMismatchKeyType, Index: index, KeyType: keyType, Expected: string(expectedType), Actual: string(actualType)})
+		}
+	}
+}
+
+func keyName(keySchema []types.KeySchemaElement, keyType types.KeyType) string {
+	for _, e := range keySchema {
+		if e.KeyType == keyType && e.AttributeName != nil {
+			return *e.AttributeName
+		}
+	}
+
+	return ""
+}
+
+func attributeType(defs []types.AttributeDefinition, name string) types.ScalarAttributeType {
+	for _, d := range defs {
+		if d.AttributeName != nil && *d.AttributeName == name {
+			return d.AttributeType
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,134 @@
+package ddbfns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDescribeClient describes the tables from CreateTableInput.
+type fakeDescribeClient struct {
+	tables map[string]*types.TableDescription
+	ttls   map[string]*types.TimeToLiveDescription
+}
+
+func (c *fakeDescribeClient) DescribeTable(_ context.Context, input *dynamodb.DescribeTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	table, ok := c.tables[*input.TableName]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("not found")}
+	}
+	return &dynamodb.DescribeTableOutput{Table: table}, nil
+}
+
+func (c *fakeDescribeClient) DescribeTimeToLive(_ context.Context, input *dynamodb.DescribeTimeToLiveInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTimeToLiveOutput, error) {
+	return &dynamodb.DescribeTimeToLiveOutput{TimeToLiveDescription: c.ttls[*input.TableName]}, nil
+}
+
+// describe returns the TableDescription that DynamoDB would return for the given CreateTableInput.
+func describe(input *dynamodb.CreateTableInput) *types.TableDescription {
+	d := &types.TableDescription{
+		TableName:            input.TableName,
+		TableStatus:          types.TableStatusActive,
+		AttributeDefinitions: input.AttributeDefinitions,
+		KeySchema:            input.KeySchema,
+	}
+	for _, gsi := range input.GlobalSecondaryIndexes {
+		d.GlobalSecondaryIndexes = append(d.GlobalSecondaryIndexes, types.GlobalSecondaryIndexDescription{IndexName: gsi.IndexName, KeySchema: gsi.KeySchema})
+	}
+	for _, lsi := range input.LocalSecondaryIndexes {
+		d.LocalSecondaryIndexes = append(d.LocalSecondaryIndexes, types.LocalSecondaryIndexDescription{IndexName: lsi.IndexName, KeySchema: lsi.KeySchema})
+	}
+
+	return d
+}
+
+func TestFns_CheckSchemaOK(t *testing.T) {
+	f := &Fns{}
+	input, _, err := CreateTableInput[tableTest](f)
+	if err != nil {
+		t.Errorf("CreateTableInput() error = %v", err)
+		return
+	}
+
+	client := &fakeDescribeClient{
+		tables: map[string]*types.TableDescription{"orders": describe(input)},
+		ttls: map[string]*types.TimeToLiveDescription{"orders": {
+			AttributeName:    aws.String("expiresAt"),
+			TimeToLiveStatus: types.TimeToLiveStatusEnabled,
+		}},
+	}
+
+	report, err := f.CheckSchema(context.Background(), client, tableTest{})
+	if err != nil {
+		t.Errorf("CheckSchema() error = %v", err)
+		return
+	}
+
+	assert.True(t, report.OK())
+	assert.NoError(t, report.Err())
+	assert.Equal(t, "orders", report.Tables[0].TableName)
+}
+
+func TestFns_CheckSchemaMismatches(t *testing.T) {
+	type NoTableName struct {
+		Id string `dynamodbav:"id,hashkey" tableName:""`
+	}
+	type Missing struct {
+		Id string `dynamodbav:"id,hashkey" tableName:"missing"`
+	}
+
+	f := &Fns{}
+	input, _, err := CreateTableInput[tableTest](f)
+	if err != nil {
+		t.Errorf("CreateTableInput() error = %v", err)
+		return
+	}
+
+	// the table's range key is "sort" instead of "sk", "customer" is N instead of S, and ByStatus is missing.
+	d := describe(input)
+	d.KeySchema = []types.KeySchemaElement{
+		{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+		{AttributeName: aws.String("sort"), KeyType: types.KeyTypeRange},
+	}
+	d.AttributeDefinitions = []types.AttributeDefinition{
+		{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+		{AttributeName: aws.String("sort"), AttributeType: types.ScalarAttributeTypeS},
+		{AttributeName: aws.String("customer"), AttributeType: types.ScalarAttributeTypeN},
+		{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+		{AttributeName: aws.String("hash"), AttributeType: types.ScalarAttributeTypeB},
+		{AttributeName: aws.String("total"), AttributeType: types.ScalarAttributeTypeN},
+	}
+	d.GlobalSecondaryIndexes = []types.GlobalSecondaryIndexDescription{d.GlobalSecondaryIndexes[0], d.GlobalSecondaryIndexes[2]}
+
+	client := &fakeDescribeClient{
+		tables: map[string]*types.TableDescription{"orders": d},
+		ttls: map[string]*types.TimeToLiveDescription{"orders": {
+			AttributeName:    aws.String("ttl"),
+			TimeToLiveStatus: types.TimeToLiveStatusEnabled,
+		}},
+	}
+
+	report, err := f.CheckSchema(context.Background(), client, tableTest{}, NoTableName{}, &Missing{})
+	if err != nil {
+		t.Errorf("CheckSchema() error = %v", err)
+		return
+	}
+
+	assert.False(t, report.OK())
+	assert.Equal(t, []SchemaMismatch{
+		{Kind: SchemaMismatchKeyName, KeyType: types.KeyTypeRange, Expected: "sk", Actual: "sort"},
+		{Kind: SchemaMismatchKeyType, Index: "ByCustomer", KeyType: types.KeyTypeHash, Expected: "S", Actual: "N"},
+		{Kind: SchemaMismatchMissingIndex, Index: "ByStatus"},
+		{Kind: SchemaMismatchTTL, Expected: "expiresAt", Actual: "ttl"},
+	}, report.Tables[0].Mismatches)
+	assert.Equal(t, []SchemaMismatch{{Kind: SchemaMismatchMissingTableName}}, report.Tables[1].Mismatches)
+	assert.Equal(t, []SchemaMismatch{{Kind: SchemaMismatchTableNotFound}}, report.Tables[2].Mismatches)
+	assert.Equal(t, "missing", report.Tables[2].TableName)
+
+	assert.Equal(t, `KeyName RANGE key: expected "sk", got "sort"`, report.Tables[0].Mismatches[0].String())
+	assert.ErrorContains(t, report.Err(), `table "orders": MissingIndex index "ByStatus"`)
+}
@@ -0,0 +1,242 @@
+package ddbfns
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// SoftDelete creates the UpdateItem request that tombstones the given item instead of removing it.
+//
+// The struct must have a field tagged with `deletedTime`, which will be set to [time.Now]. Like [Fns.Update], the
+// version is incremented and used for optimistic locking, and modified time is set to the same [time.Now] unless
+// disabled by DeleteOpts. If [DeleteOpts.TTL] is positive, the field tagged with `ttl` is also set so that DynamoDB
+// will eventually remove the item:
+//
+//	Field time.Time `dynamodbav:"-,deletedTime,unixtime"`
+//	Field time.Time `dynamodbav:"-,ttl"`
+//
+// The item must not have already been tombstoned. By default, tombstoned items are excluded by [Fns.DoGet],
+// [Fns.DoQuery], [Fns.DoScan], and [Fns.LoadCollection]; use [Fns.Restore] to undo a soft delete.
+//
+// If the struct implements [BeforeDeleteHook], the hook is called before the request is built. Since the request is
+// an UpdateItem request, [BeforeUpdateHook] is also called.
+func (f *Fns) SoftDelete(v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.UpdateItemInput, error) {
+	input, _, err := f.softDelete(context.Background(), v, applyOpts(optFns))
+	return input, err
+}
+
+// softDelete also returns the UpdateOpts from which the request was built.
+func (f *Fns) softDelete(ctx context.Context, v interface{}, opts *DeleteOpts) (*dynamodb.UpdateItemInput, *UpdateOpts, error) {
+	f.init.Do(f.initFn)
+
+	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deletedTimeAttr := attrs.DeletedTime
+	if deletedTimeAttr == nil {
+		return nil, nil, fmt.Errorf(`no deletedTime field in type "%s"`, attrs.StructType.Name())
+	}
+
+	if cp, hook, ok := shallowCopy[BeforeDeleteHook](v); ok {
+		if err = hook.BeforeDelete(ctx, opts); err != nil {
+			return nil, nil, err
+		}
+		v = cp
+	}
+
+	now := opts.now()
+
+	deletedTime, err := f.encodeTimestamp(deletedTimeAttr, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode deletedTime error: %w", err)
+	}
+
+	o := &UpdateOpts{
+		RequestOptions:                 opts.RequestOptions,
+		DisableOptimisticLocking:       opts.DisableOptimisticLocking,
+		DisableAutoGeneratedTimestamps: opts.DisableAutoGeneratedTimestamps,
+	}
+	o.Clock = func() time.Time {
+		return now
+	}
+	o.And(expression.Name(deletedTimeAttr.Name).AttributeNotExists())
+
+	o.Set(deletedTimeAttr.Name, deletedTime)
+	if ttlAttr := attrs.TTL; ttlAttr != nil && opts.TTL > 0 {
+		o.Set(ttlAttr.Name, &types.AttributeValueMemberN{Value: strconv.FormatInt(now.Add(opts.TTL).Unix(), 10)})
+	}
+
+	input, err := f.update(ctx, v, o)
+	return input, o, err
+}
+
+// DoSoftDelete performs a [Fns.SoftDelete] and then executes the request with the specified DynamoDB client.
+//
+// This is the same as [Fns.DoDelete] with [DeleteOpts.Soft] set to true, except the UpdateItemOutput is returned.
+//
+// If [Fns.HistoryOpts] is given, the request is executed in the same transaction as its history item; see
+// [HistoryOpts].
+func (f *Fns) DoSoftDelete(ctx context.Context, client *dynamodb.Client, v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.UpdateItemOutput, error) {
+	return f.doSoftDelete(ctx, client, v, applyOpts(optFns))
+}
+
+func (f *Fns) doSoftDelete(ctx context.Context, client *dynamodb.Client, v interface{}, opts *DeleteOpts) (*dynamodb.UpdateItemOutput, error) {
+	if f.HistoryOpts != nil {
+		if err := checkTransactReturnValues(&opts.RequestOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	input, o, err := f.softDelete(ctx, v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.HistoryOpts != nil {
+		return f.doHistoryUpdate(ctx, client, v, input, o)
+	}
+
+	updateItemOutput, err := invoke(ctx, f, newRequest(input), client.UpdateItem, opts.ClientOptions)
+	if err != nil || opts.out == nil {
+		return updateItemOutput, err
+	}
+
+	if item := updateItemOutput.Attributes; len(item) != 0 {
+		err = f.decode(item, opts.out)
+	}
+
+	return updateItemOutput, err
+}
+
+// Restore creates the UpdateItem request that undoes a [Fns.SoftDelete].
+//
+// The `deletedTime` and `ttl` attributes are removed, and like [Fns.Update], the version is incremented and used for
+// optimistic locking, and modified time is set to [time.Now] unless disabled by UpdateOpts. The item must have been
+// tombstoned.
+func (f *Fns) Restore(v interface{}, optFns ...func(*UpdateOpts)) (*dynamodb.UpdateItemInput, error) {
+	return f.restore(context.Background(), v, applyOpts(optFns))
+}
+
+func (f *Fns) restore(ctx context.Context, v interface{}, opts *UpdateOpts) (*dynamodb.UpdateItemInput, error) {
+	f.init.Do(f.initFn)
+
+	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	deletedTimeAttr := attrs.DeletedTime
+	if deletedTimeAttr == nil {
+		return nil, fmt.Errorf(`no deletedTime field in type "%s"`, attrs.StructType.Name())
+	}
+
+	opts.And(expression.Name(deletedTimeAttr.Name).AttributeExists())
+	opts.Remove(deletedTimeAttr.Name)
+	if ttlAttr := attrs.TTL; ttlAttr != nil {
+		opts.Remove(ttlAttr.Name)
+	}
+
+	return f.update(ctx, v, opts)
+}
+
+// DoRestore performs a [Fns.Restore] and then executes the request with the specified DynamoDB client.
+//
+// If [Fns.HistoryOpts] is given, the request is executed in the same transaction as its history item; see
+// [HistoryOpts].
+func (f *Fns) DoRestore(ctx context.Context, client *dynamodb.Client, v interface{}, optFns ...func(*UpdateOpts)) (*dynamodb.UpdateItemOutput, error) {
+	opts := applyOpts(optFns)
+
+	if f.HistoryOpts != nil {
+		if err := checkTransactReturnValues(&opts.RequestOptions); err != nil {
+			return nil, err
+		}
+	}
+
+	input, err := f.restore(ctx, v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.HistoryOpts != nil {
+		return f.doHistoryUpdate(ctx, client, v, input, opts)
+	}
+
+	updateItemOutput, err := invoke(ctx, f, newRequest(input), client.UpdateItem, opts.ClientOptions)
+	if err != nil || opts.out == nil {
+		return updateItemOutput, err
+	}
+
+	if item := updateItemOutput.Attributes; len(item) != 0 {
+		err = f.decode(item, opts.out)
+	}
+
+	return updateItemOutput, err
+}
+
+// encodeTimestamp encodes the given time for the given timestamp attribute, respecting `unixtime`.
+func (f *Fns) encodeTimestamp(attr *internal.Attribute, t time.Time) (types.AttributeValue, error) {
+	if attr.UnixTime {
+		return attributevalue.UnixTime(t).MarshalDynamoDBAttributeValue()
+	}
+
+	return f.Encoder.Encode(reflect.ValueOf(t).Convert(attr.Field.Type).Interface())
+}
+
+// zeroUnixTime and zeroTime are the encodings of the zero time.Time with and without `unixtime`.
+var (
+	zeroUnixTime = strconv.FormatInt(time.Time{}.Unix(), 10)
+	zeroTime     = time.Time{}.Format(time.RFC3339Nano)
+)
+
+// isDeleted returns true if the item has been tombstoned by SoftDelete.
+//
+// A zero time is not a tombstone since attributevalue encodes it even with `omitempty`.
+func isDeleted(m *internal.Model, item map[string]types.AttributeValue) bool {
+	if m.DeletedTime == nil {
+		return false
+	}
+
+	switch av := item[m.DeletedTime.Name].(type) {
+	case nil:
+		return false
+	case *types.AttributeValueMemberNULL:
+		return !av.Value
+	case *types.AttributeValueMemberN:
+		return av.Value != zeroUnixTime
+	case *types.AttributeValueMemberS:
+		return av.Value != zeroTime
+	default:
+		return true
+	}
+}
+
+// SoftDelete is a wrapper around [DefaultFns.SoftDelete]; see [Fns.SoftDelete] for more information.
+func SoftDelete(v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.UpdateItemInput, error) {
+	return DefaultFns.SoftDelete(v, optFns...)
+}
+
+// DoSoftDelete is a wrapper around [DefaultFns.DoSoftDelete]; see [Fns.DoSoftDelete] for more information.
+func DoSoftDelete(ctx context.Context, client *dynamodb.Client, v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.UpdateItemOutput, error) {
+	return DefaultFns.DoSoftDelete(ctx, client, v, optFns...)
+}
+
+// Restore is a wrapper around [DefaultFns.Restore]; see [Fns.Restore] for more information.
+func Restore(v interface{}, optFns ...func(*UpdateOpts)) (*dynamodb.UpdateItemInput, error) {
+	return DefaultFns.Restore(v, optFns...)
+}
+
+// DoRestore is a wrapper around [DefaultFns.DoRestore]; see [Fns.DoRestore] for more information.
+func DoRestore(ctx context.Context, client *dynamodb.Client, v interface{}, optFns ...func(*UpdateOpts)) (*dynamodb.UpdateItemOutput, error) {
+	return DefaultFns.DoRestore(ctx, client, v, optFns...)
+}
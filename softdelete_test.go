@@ -0,0 +1,152 @@
+package ddbfns
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type softDeleteTest struct {
+	Id          string    `dynamodbav:"id,hashkey" tableName:""`
+	Version     int64     `dynamodbav:"version,version"`
+	DeletedTime time.Time `dynamodbav:"deletedTime,deletedTime,unixtime"`
+	ExpiresAt   time.Time `dynamodbav:"expiresAt,ttl"`
+}
+
+func TestFns_SoftDelete(t *testing.T) {
+	input := softDeleteTest{Id: "hello", Version: 3}
+
+	// this is to make sure the input item is not mutated.
+	before := MustToJSON(input)
+
+	got, err := SoftDelete(input, func(opts *DeleteOpts) {
+		opts.TTL = time.Hour
+	})
+	if err != nil {
+		t.Errorf("SoftDelete() error = %v", err)
+		return
+	}
+
+	assert.JSONEq(t, before, MustToJSON(input))
+	assert.Equal(t, "(attribute_not_exists (#0)) AND (#1 = :0)", *got.ConditionExpression)
+	assert.Equal(t, "ADD #1 :1\nSET #0 = :2, #2 = :3\n", *got.UpdateExpression)
+	assert.Equal(t, map[string]string{"#0": "deletedTime", "#1": "version", "#2": "expiresAt"}, got.ExpressionAttributeNames)
+
+	deletedTime, _ := strconv.ParseInt(got.ExpressionAttributeValues[":2"].(*types.AttributeValueMemberN).Value, 10, 64)
+	expiresAt, _ := strconv.ParseInt(got.ExpressionAttributeValues[":3"].(*types.AttributeValueMemberN).Value, 10, 64)
+	assert.Equal(t, int64(time.Hour.Seconds()), expiresAt-deletedTime)
+
+	_, err = DefaultFns.Delete(input, func(opts *DeleteOpts) {
+		opts.Soft = true
+	})
+	assert.Error(t, err)
+}
+
+func TestFns_Restore(t *testing.T) {
+	got, err := Restore(softDeleteTest{Id: "hello", Version: 4})
+	if err != nil {
+		t.Errorf("Restore() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, "(attribute_exists (#0)) AND (#1 = :0)", *got.ConditionExpression)
+	assert.Equal(t, "ADD #1 :1\nREMOVE #0, #2\n", *got.UpdateExpression)
+	assert.Equal(t, map[string]string{"#0": "deletedTime", "#1": "version", "#2": "expiresAt"}, got.ExpressionAttributeNames)
+}
+
+func TestFns_DoQueryExcludesDeleted(t *testing.T) {
+	f := &Fns{}
+	assert.NoError(t, RegisterType[softDeleteTest](f, "Test"))
+
+	item := func(id string, deleted bool) map[string]types.AttributeValue {
+		item := map[string]types.AttributeValue{
+			"id":    &types.AttributeValueMemberS{Value: id},
+			"_type": &types.AttributeValueMemberS{Value: "Test"},
+		}
+		if deleted {
+			item["deletedTime"] = &types.AttributeValueMemberN{Value: "1136214245"}
+		}
+		return item
+	}
+
+	newClient := func() *fakeQueryClient {
+		return &fakeQueryClient{pages: []*dynamodb.QueryOutput{{Items: []map[string]types.AttributeValue{item("a", false), item("b", true)}}}}
+	}
+
+	var ids []string
+	visitor := func(v interface{}) error {
+		ids = append(ids, v.(*softDeleteTest).Id)
+		return nil
+	}
+
+	assert.NoError(t, f.DoQuery(context.Background(), newClient(), &dynamodb.QueryInput{}, visitor))
+	assert.Equal(t, []string{"a"}, ids)
+
+	ids = nil
+	assert.NoError(t, f.DoQuery(context.Background(), newClient(), &dynamodb.QueryInput{}, visitor, func(opts *QueryOpts) {
+		opts.IncludeDeleted = true
+	}))
+	assert.Equal(t, []string{"a", "b"}, ids)
+}
+
+func TestFns_SoftDelete_zeroDeletedTime(t *testing.T) {
+	type Test struct {
+		Id          string    `dynamodbav:"id,hashkey" tableName:"my-table"`
+		Version     int64     `dynamodbav:"version,version"`
+		DeletedTime time.Time `dynamodbav:"deletedTime,deletedTime,unixtime"`
+		ExpiresAt   time.Time `dynamodbav:"expiresAt,ttl"`
+	}
+
+	// the fake client stores the put item, and fails the soft delete if deletedTime exists like DynamoDB would.
+	var stored json.RawMessage
+	client := newFakeClient(func(operation string, body []byte) (int, string) {
+		switch operation {
+		case "PutItem":
+			var input struct{ Item json.RawMessage }
+			_ = json.Unmarshal(body, &input)
+			stored = input.Item
+			return 200, `{}`
+		case "GetItem":
+			return 200, `{"Item":` + string(stored) + `}`
+		case "UpdateItem":
+			if strings.Contains(string(stored), `"deletedTime"`) {
+				return 400, conditionalCheckFailedBody
+			}
+			return 200, `{}`
+		default:
+			return 400, `{}`
+		}
+	})
+
+	ctx := context.Background()
+	f := &Fns{}
+
+	_, err := f.DoPut(ctx, client, Test{Id: "hello"})
+	assert.NoError(t, err)
+	assert.NotContains(t, string(stored), "deletedTime")
+	assert.NotContains(t, string(stored), "expiresAt")
+
+	var got Test
+	_, err = f.DoGet(ctx, client, Test{Id: "hello"}, func(opts *GetOpts) {
+		opts.Decode(&got)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, Test{Id: "hello", Version: 1}, got)
+
+	_, err = f.DoSoftDelete(ctx, client, got)
+	assert.NoError(t, err)
+
+	// items written before zero times were dropped are not tombstones either.
+	m, err := f.loadOrParse(reflect.TypeOf(Test{}))
+	assert.NoError(t, err)
+	assert.False(t, isDeleted(m, map[string]types.AttributeValue{"deletedTime": &types.AttributeValueMemberN{Value: "-62135596800"}}))
+	assert.True(t, isDeleted(m, map[string]types.AttributeValue{"deletedTime": &types.AttributeValueMemberN{Value: "1136214245"}}))
+}
@@ -0,0 +1,290 @@
+package ddbfns
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// DefaultCreateTableMaxWait is the default value of CreateTableOpts.MaxWait.
+const DefaultCreateTableMaxWait = 5 * time.Minute
+
+// CreateTableOpts customises [CreateTableInput] and [DoCreateTable].
+type CreateTableOpts struct {
+	// TableName overrides the table name from the `tableName` struct tag.
+	TableName *string
+	// BillingMode defaults to [types.BillingModePayPerRequest] unless ProvisionedThroughput is given.
+	BillingMode types.BillingMode
+	// ProvisionedThroughput is used for the table and all global secondary indexes.
+	ProvisionedThroughput *types.ProvisionedThroughput
+	// StreamSpecification enables DynamoDB Streams.
+	StreamSpecification *types.StreamSpecification
+	// Projections maps index names to their projection; the default is [types.ProjectionTypeAll].
+	Projections map[string]*types.Projection
+	// Tags are added to the table.
+	Tags []types.Tag
+	// MaxWait is the maximum duration that DoCreateTable waits for the table to become ACTIVE.
+	//
+	// Defaults to DefaultCreateTableMaxWait.
+	MaxWait time.Duration
+	// ClientOptions are passed to every DynamoDB call made by DoCreateTable.
+	ClientOptions []func(*dynamodb.Options)
+}
+
+// WithTableName overrides [CreateTableOpts.TableName].
+func (o *CreateTableOpts) WithTableName(tableName string) *CreateTableOpts {
+	o.TableName = &tableName
+	return o
+}
+
+// WithProvisionedThroughput switches the billing mode to [types.BillingModeProvisioned] with the given capacity units.
+func (o *CreateTableOpts) WithProvisionedThroughput(readCapacityUnits, writeCapacityUnits int64) *CreateTableOpts {
+	o.BillingMode = types.BillingModeProvisioned
+	o.ProvisionedThroughput = &types.ProvisionedThroughput{
+		ReadCapacityUnits:  aws.Int64(readCapacityUnits),
+		WriteCapacityUnits: aws.Int64(writeCapacityUnits),
+	}
+	return o
+}
+
+// WithStream enables DynamoDB Streams with the given view type.
+func (o *CreateTableOpts) WithStream(viewType types.StreamViewType) *CreateTableOpts {
+	o.StreamSpecification = &types.StreamSpecification{StreamEnabled: aws.Bool(true), StreamViewType: viewType}
+	return o
+}
+
+// WithProjection sets the projection of the named index.
+//
+// nonKeyAttributes are only used with [types.ProjectionTypeInclude].
+func (o *CreateTableOpts) WithProjection(indexName string, projectionType types.ProjectionType, nonKeyAttributes ...string) *CreateTableOpts {
+	if o.Projections == nil {
+		o.Projections = make(map[string]*types.Projection)
+	}
+
+	o.Projections[indexName] = &types.Projection{ProjectionType: projectionType, NonKeyAttributes: nonKeyAttributes}
+	return o
+}
+
+// CreateTableInput creates the CreateTable request for struct type T.
+//
+// The KeySchema comes from the hashkey and sortkey fields, while the AttributeDefinitions are derived from the Go types
+// of the key fields: strings (and keys with `keyFormat` or the `string` option) are S, numbers are N, and byte slices
+// are B. Secondary indexes are declared with the `gsi` and `lsi` struct tags:
+//
+//	type Order struct {
+//		PK        string    `dynamodbav:"pk,hashkey" tableName:"orders"`
+//		SK        string    `dynamodbav:"sk,sortkey"`
+//		Customer  string    `dynamodbav:"customer" gsi:"ByCustomer,hashkey"`
+//		Status    string    `dynamodbav:"status" gsi:"ByCustomer,sortkey ByStatus,hashkey"`
+//		Total     int64     `dynamodbav:"total" lsi:"ByTotal"`
+//		ExpiresAt time.Time `dynamodbav:"expiresAt,ttl,unixtime"`
+//	}
+//
+// The TimeToLiveSpecification is also returned if T has a ttl field since CreateTable cannot enable TTL by itself; it
+// is nil otherwise. See [DoCreateTable] which also enables TTL.
+func CreateTableInput[T any](f *Fns, optFns ...func(*CreateTableOpts)) (*dynamodb.CreateTableInput, *types.TimeToLiveSpecification, error) {
+	return f.createTableInput(reflect.TypeFor[T](), applyOpts(optFns))
+}
+
+func (f *Fns) createTableInput(t reflect.Type, opts *CreateTableOpts) (*dynamodb.CreateTableInput, *types.TimeToLiveSpecification, error) {
+	f.init.Do(f.initFn)
+
+	m, err := f.loadOrParse(t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tableName := m.TableName
+	if opts.TableName != nil {
+		tableName = opts.TableName
+	}
+	if tableName == nil {
+		return nil, nil, fmt.Errorf(`no table name for type "%s"`, m.StructType.Name())
+	}
+
+	input := &dynamodb.CreateTableInput{
+		TableName:           tableName,
+		BillingMode:         opts.BillingMode,
+		StreamSpecification: opts.StreamSpecification,
+		Tags:                opts.Tags,
+	}
+	if input.BillingMode == "" {
+		input.BillingMode = types.BillingModePayPerRequest
+	}
+	if input.BillingMode == types.BillingModeProvisioned {
+		if opts.ProvisionedThroughput == nil {
+			return nil, nil, fmt.Errorf("provisioned billing mode requires ProvisionedThroughput")
+		}
+		input.ProvisionedThroughput = opts.ProvisionedThroughput
+	}
+
+	defs := &attributeDefinitions{}
+	if input.KeySchema, err = defs.keySchema(m.HashKey, m.SortKey); err != nil {
+		return nil, nil, err
+	}
+
+	for _, index := range m.GlobalSecondaryIndexes {
+		keySchema, err := defs.keySchema(index.HashKey, index.SortKey)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		gsi := types.GlobalSecondaryIndex{
+			IndexName:  aws.String(index.Name),
+			KeySchema:  keySchema,
+			Projection: opts.projection(index.Name),
+		}
+		if input.BillingMode == types.BillingModeProvisioned {
+			gsi.ProvisionedThroughput = opts.ProvisionedThroughput
+		}
+		input.GlobalSecondaryIndexes = append(input.GlobalSecondaryIndexes, gsi)
+	}
+
+	for _, index := range m.LocalSecondaryIndexes {
+		keySchema, err := defs.keySchema(index.HashKey, index.SortKey)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		input.LocalSecondaryIndexes = append(input.LocalSecondaryIndexes, types.LocalSecondaryIndex{
+			IndexName:  aws.String(index.Name),
+			KeySchema:  keySchema,
+			Projection: opts.projection(index.Name),
+		})
+	}
+
+	input.AttributeDefinitions = defs.values
+
+	var ttl *types.TimeToLiveSpecification
+	if m.TTL != nil {
+		ttl = &types.TimeToLiveSpecification{AttributeName: aws.String(m.TTL.Name), Enabled: aws.Bool(true)}
+	}
+
+	return input, ttl, nil
+}
+
+// CreateTableAPIClient is the subset of the DynamoDB client used by [DoCreateTable].
+type CreateTableAPIClient interface {
+	dynamodb.DescribeTableAPIClient
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+}
+
+// DoCreateTable creates the table of struct type T and waits for it to become ACTIVE.
+//
+// If T has a ttl field, TTL is enabled once the table is ACTIVE. See [CreateTableInput] for how the table is defined.
+func DoCreateTable[T any](ctx context.Context, f *Fns, client CreateTableAPIClient, optFns ...func(*CreateTableOpts)) (*dynamodb.CreateTableOutput, error) {
+	opts := applyOpts(optFns)
+
+	input, ttl, err := f.createTableInput(reflect.TypeFor[T](), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := client.CreateTable(ctx, input, opts.ClientOptions...)
+	if err != nil {
+		return nil, fmt.Errorf("create table error: %w", err)
+	}
+
+	maxWait := opts.MaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultCreateTableMaxWait
+	}
+
+	if err = dynamodb.NewTableExistsWaiter(client).Wait(ctx, &dynamodb.DescribeTableInput{TableName: input.TableName}, maxWait, func(o *dynamodb.TableExistsWaiterOptions) {
+		o.ClientOptions = append(o.ClientOptions, opts.ClientOptions...)
+	}); err != nil {
+		return output, fmt.Errorf("wait for table to become active error: %w", err)
+	}
+
+	if ttl != nil {
+		if _, err = client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+			TableName:               input.TableName,
+			TimeToLiveSpecification: ttl,
+		}, opts.ClientOptions...); err != nil {
+			return output, fmt.Errorf("update time to live error: %w", err)
+		}
+	}
+
+	return output, nil
+}
+
+func (o *CreateTableOpts) projection(indexName string) *types.Projection {
+	if p, ok := o.Projections[indexName]; ok {
+		return p
+	}
+
+	return &types.Projection{ProjectionType: types.ProjectionTypeAll}
+}
+
+// attributeDefinitions collects the unique attribute definitions of the table and index keys.
+type attributeDefinitions struct {
+	values []types.AttributeDefinition
+}
+
+// keySchema returns the key schema of the given hash and optional sort key, adding their attribute definitions.
+func (d *attributeDefinitions) keySchema(hashKey, sortKey *internal.Attribute) ([]types.KeySchemaElement, error) {
+	if err := d.add(hashKey); err != nil {
+		return nil, err
+	}
+
+	keySchema := []types.KeySchemaElement{{AttributeName: aws.String(hashKey.Name), KeyType: types.KeyTypeHash}}
+	if sortKey != nil {
+		if err := d.add(sortKey); err != nil {
+			return nil, err
+		}
+
+		keySchema = append(keySchema, types.KeySchemaElement{AttributeName: aws.String(sortKey.Name), KeyType: types.KeyTypeRange})
+	}
+
+	return keySchema, nil
+}
+
+func (d *attributeDefinitions) add(attr *internal.Attribute) error {
+	attributeType, err := scalarAttributeType(attr)
+	if err != nil {
+		return err
+	}
+
+	for _, def := range d.values {
+		if *def.AttributeName != attr.Name {
+			continue
+		}
+
+		if def.AttributeType != attributeType {
+			return fmt.Errorf(`attribute "%s" has conflicting types %s and %s`, attr.Name, def.AttributeType, attributeType)
+		}
+		return nil
+	}
+
+	d.values = append(d.values, types.AttributeDefinition{AttributeName: aws.String(attr.Name), AttributeType: attributeType})
+	return nil
+}
+
+// scalarAttributeType returns the type of the key attribute the same way f.Encoder would encode the field.
+func scalarAttributeType(attr *internal.Attribute) (types.ScalarAttributeType, error) {
+	if attr.AsString || attr.KeyFormat != nil {
+		return types.ScalarAttributeTypeS, nil
+	}
+
+	switch ft := attr.Field.Type; ft.Kind() {
+	case reflect.String:
+		return types.ScalarAttributeTypeS, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return types.ScalarAttributeTypeN, nil
+	case reflect.Array, reflect.Slice:
+		if ft.Elem().Kind() == reflect.Uint8 {
+			return types.ScalarAttributeTypeB, nil
+		}
+	}
+
+	return "", fmt.Errorf(`unsupported key attribute type "%s" for attribute "%s"`, attr.Field.Type, attr.Name)
+}
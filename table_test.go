@@ -0,0 +1,181 @@
+package ddbfns
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type tableTest struct {
+	PK        string    `dynamodbav:"pk,hashkey" tableName:"orders"`
+	SK        string    `dynamodbav:"sk,sortkey"`
+	Customer  string    `dynamodbav:"customer" gsi:"ByCustomer,hashkey"`
+	Status    string    `dynamodbav:"status" gsi:"ByCustomer,sortkey ByStatus,hashkey"`
+	Total     int64     `dynamodbav:"total" lsi:"ByTotal"`
+	Hash      []byte    `dynamodbav:"hash" gsi:"ByHash,hashkey"`
+	ExpiresAt time.Time `dynamodbav:"expiresAt,ttl,unixtime"`
+}
+
+func TestFns_CreateTableInput(t *testing.T) {
+	f := &Fns{}
+	input, ttl, err := CreateTableInput[tableTest](f, func(opts *CreateTableOpts) {
+		opts.WithProjection("ByStatus", types.ProjectionTypeKeysOnly).WithStream(types.StreamViewTypeNewAndOldImages)
+	})
+	if err != nil {
+		t.Errorf("CreateTableInput() error = %v", err)
+		return
+	}
+
+	all := &types.Projection{ProjectionType: types.ProjectionTypeAll}
+	assert.Equal(t, &dynamodb.CreateTableInput{
+		TableName:   aws.String("orders"),
+		BillingMode: types.BillingModePayPerRequest,
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String("pk"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("sk"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("customer"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("status"), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String("hash"), AttributeType: types.ScalarAttributeTypeB},
+			{AttributeName: aws.String("total"), AttributeType: types.ScalarAttributeTypeN},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String("sk"), KeyType: types.KeyTypeRange},
+		},
+		GlobalSecondaryIndexes: []types.GlobalSecondaryIndex{
+			{
+				IndexName: aws.String("ByCustomer"),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("customer"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("status"), KeyType: types.KeyTypeRange},
+				},
+				Projection: all,
+			},
+			{
+				IndexName:  aws.String("ByStatus"),
+				KeySchema:  []types.KeySchemaElement{{AttributeName: aws.String("status"), KeyType: types.KeyTypeHash}},
+				Projection: &types.Projection{ProjectionType: types.ProjectionTypeKeysOnly},
+			},
+			{
+				IndexName:  aws.String("ByHash"),
+				KeySchema:  []types.KeySchemaElement{{AttributeName: aws.String("hash"), KeyType: types.KeyTypeHash}},
+				Projection: all,
+			},
+		},
+		LocalSecondaryIndexes: []types.LocalSecondaryIndex{
+			{
+				IndexName: aws.String("ByTotal"),
+				KeySchema: []types.KeySchemaElement{
+					{AttributeName: aws.String("pk"), KeyType: types.KeyTypeHash},
+					{AttributeName: aws.String("total"), KeyType: types.KeyTypeRange},
+				},
+				Projection: all,
+			},
+		},
+		StreamSpecification: &types.StreamSpecification{StreamEnabled: aws.Bool(true), StreamViewType: types.StreamViewTypeNewAndOldImages},
+	}, input)
+	assert.Equal(t, &types.TimeToLiveSpecification{AttributeName: aws.String("expiresAt"), Enabled: aws.Bool(true)}, ttl)
+}
+
+func TestFns_CreateTableInputProvisioned(t *testing.T) {
+	f := &Fns{}
+	input, ttl, err := CreateTableInput[tableTest](f, func(opts *CreateTableOpts) {
+		opts.WithTableName("test").WithProvisionedThroughput(5, 10)
+	})
+	if err != nil {
+		t.Errorf("CreateTableInput() error = %v", err)
+		return
+	}
+
+	throughput := &types.ProvisionedThroughput{ReadCapacityUnits: aws.Int64(5), WriteCapacityUnits: aws.Int64(10)}
+	assert.Equal(t, "test", *input.TableName)
+	assert.Equal(t, types.BillingModeProvisioned, input.BillingMode)
+	assert.Equal(t, throughput, input.ProvisionedThroughput)
+	for _, gsi := range input.GlobalSecondaryIndexes {
+		assert.Equal(t, throughput, gsi.ProvisionedThroughput)
+	}
+	assert.NotNil(t, ttl)
+}
+
+func TestFns_CreateTableInputErrors(t *testing.T) {
+	type NoTableName struct {
+		Id string `dynamodbav:"id,hashkey" tableName:""`
+	}
+	_, _, err := CreateTableInput[NoTableName](&Fns{})
+	assert.ErrorContains(t, err, "no table name")
+
+	type NoIndexHashKey struct {
+		Id   string `dynamodbav:"id,hashkey" tableName:"test"`
+		Date string `dynamodbav:"date" gsi:"ByDate,sortkey"`
+	}
+	_, _, err = CreateTableInput[NoIndexHashKey](&Fns{})
+	assert.ErrorContains(t, err, `no hashkey field for gsi "ByDate"`)
+
+	type NoSortKey struct {
+		Id   string `dynamodbav:"id,hashkey" tableName:"test"`
+		Date string `dynamodbav:"date" lsi:"ByDate"`
+	}
+	_, _, err = CreateTableInput[NoSortKey](&Fns{})
+	assert.ErrorContains(t, err, `lsi "ByDate" requires a sortkey field`)
+
+	type BadRole struct {
+		Id   string `dynamodbav:"id,hashkey" tableName:"test"`
+		Date string `dynamodbav:"date" gsi:"ByDate"`
+	}
+	_, _, err = CreateTableInput[BadRole](&Fns{})
+	assert.ErrorContains(t, err, `invalid gsi tag "ByDate"`)
+
+	type MissingThroughput struct {
+		Id string `dynamodbav:"id,hashkey" tableName:"test"`
+	}
+	_, _, err = CreateTableInput[MissingThroughput](&Fns{}, func(opts *CreateTableOpts) {
+		opts.BillingMode = types.BillingModeProvisioned
+	})
+	assert.ErrorContains(t, err, "requires ProvisionedThroughput")
+}
+
+// fakeCreateTableClient returns CREATING from DescribeTable until it has been called describeCalls times.
+type fakeCreateTableClient struct {
+	describeCalls int
+	calls         []string
+	ttl           *dynamodb.UpdateTimeToLiveInput
+}
+
+func (c *fakeCreateTableClient) CreateTable(_ context.Context, input *dynamodb.CreateTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	c.calls = append(c.calls, "CreateTable")
+	return &dynamodb.CreateTableOutput{TableDescription: &types.TableDescription{TableName: input.TableName, TableStatus: types.TableStatusCreating}}, nil
+}
+
+func (c *fakeCreateTableClient) DescribeTable(_ context.Context, input *dynamodb.DescribeTableInput, _ ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	c.calls = append(c.calls, "DescribeTable")
+	status := types.TableStatusActive
+	if c.describeCalls--; c.describeCalls > 0 {
+		status = types.TableStatusCreating
+	}
+	return &dynamodb.DescribeTableOutput{Table: &types.TableDescription{TableName: input.TableName, TableStatus: status}}, nil
+}
+
+func (c *fakeCreateTableClient) UpdateTimeToLive(_ context.Context, input *dynamodb.UpdateTimeToLiveInput, _ ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	c.calls = append(c.calls, "UpdateTimeToLive")
+	c.ttl = input
+	return &dynamodb.UpdateTimeToLiveOutput{}, nil
+}
+
+func TestFns_DoCreateTable(t *testing.T) {
+	client := &fakeCreateTableClient{describeCalls: 1}
+	output, err := DoCreateTable[tableTest](context.Background(), &Fns{}, client)
+	if err != nil {
+		t.Errorf("DoCreateTable() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, "orders", *output.TableDescription.TableName)
+	assert.Equal(t, []string{"CreateTable", "DescribeTable", "UpdateTimeToLive"}, client.calls)
+	assert.Equal(t, "orders", *client.ttl.TableName)
+	assert.Equal(t, "expiresAt", *client.ttl.TimeToLiveSpecification.AttributeName)
+}
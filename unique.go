@@ -0,0 +1,622 @@
+package ddbfns
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// UniqueOwnerAttribute is the name of the attribute of a uniqueness sentinel item that stores the key of the item
+// owning the unique value.
+const UniqueOwnerAttribute = "_owner"
+
+// TransactWriteItemsAPIClient is the subset of the DynamoDB client used by the DoTransactXyz methods.
+type TransactWriteItemsAPIClient interface {
+	TransactWriteItems(ctx context.Context, params *dynamodb.TransactWriteItemsInput, optFns ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error)
+}
+
+// UniqueConstraintError is returned by the DoTransactXyz methods if the transaction was cancelled because another item
+// already owns the unique value.
+type UniqueConstraintError struct {
+	// Constraint is the name of the constraint from the `unique` struct tag.
+	Constraint string
+	// Attribute is the name of the DynamoDB attribute.
+	Attribute string
+	// Value is the conflicting value.
+	Value string
+	// Err is the original [types.TransactionCanceledException].
+	Err error
+}
+
+// Error implements the error interface.
+func (e *UniqueConstraintError) Error() string {
+	return fmt.Sprintf(`unique constraint "%s" violated: attribute "%s" value "%s" already exists`, e.Constraint, e.Attribute, e.Value)
+}
+
+// Unwrap returns the original error.
+func (e *UniqueConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// uniqueRef identifies the unique value of a sentinel Put in the TransactItems.
+type uniqueRef struct {
+	attr  *internal.Attribute
+	value string
+}
+
+// TransactPut creates the TransactWriteItems request that puts the given item along with its uniqueness sentinel items.
+//
+// DynamoDB can only enforce uniqueness on the primary key. For every attribute tagged with `unique:"name"`, a sentinel
+// item whose hash key (and sort key, if any) is `UNIQUE#name#value` is written in the same table with a condition that
+// it must not already exist (or already be owned by the same item). For example:
+//
+//	Email string `dynamodbav:"email" unique:"email"`
+//
+// If the item's version is not at its zero value, sentinels are not written; instead the unique attributes are
+// required to keep their stored values. Use [Fns.TransactUpdate] to change a unique value so that the sentinel is moved
+// atomically. If the struct has no version attribute, the item must not already exist for the same reason.
+//
+// The hash key and sort key of the struct must be string types. ReturnValues and Decode are not supported by
+// TransactWriteItems.
+func (f *Fns) TransactPut(v interface{}, optFns ...func(*PutOpts)) (*dynamodb.TransactWriteItemsInput, error) {
+	input, _, err := f.transactPut(context.Background(), v, applyOpts(optFns))
+	return input, err
+}
+
+func (f *Fns) transactPut(ctx context.Context, v interface{}, opts *PutOpts) (*dynamodb.TransactWriteItemsInput, []*uniqueRef, error) {
+	f.init.Do(f.initFn)
+
+	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = checkSentinelKeys(attrs); err != nil {
+		return nil, nil, err
+	}
+
+	existing := false
+	if versionAttr := attrs.Version; versionAttr != nil {
+		version, err := versionAttr.Get(reflect.Indirect(reflect.ValueOf(v)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("get version value error: %w", err)
+		}
+		existing = !version.IsZero()
+	} else if len(attrs.Uniques) != 0 {
+		// without a version, the stored unique values are unknown so their sentinels could not be moved.
+		opts.And(expression.Name(attrs.HashKey.Name).AttributeNotExists())
+	}
+
+	var values map[*internal.Attribute]string
+	opts.beforeBuild = func(item map[string]types.AttributeValue) error {
+		values = make(map[*internal.Attribute]string)
+		for _, attr := range attrs.Uniques {
+			av := item[attr.Name]
+			values[attr] = uniqueValue(av)
+
+			if existing {
+				if av == nil {
+					opts.And(expression.Name(attr.Name).AttributeNotExists())
+				} else {
+					opts.And(expression.Name(attr.Name).Equal(expression.Value(av)))
+				}
+			}
+		}
+
+		return nil
+	}
+
+	putItemInput, err := f.put(ctx, v, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	owner := ownerOf(attrs, putItemInput.Item)
+	items := []types.TransactWriteItem{{Put: &types.Put{
+		Item:                                putItemInput.Item,
+		TableName:                           putItemInput.TableName,
+		ConditionExpression:                 putItemInput.ConditionExpression,
+		ExpressionAttributeNames:            putItemInput.ExpressionAttributeNames,
+		ExpressionAttributeValues:           putItemInput.ExpressionAttributeValues,
+		ReturnValuesOnConditionCheckFailure: putItemInput.ReturnValuesOnConditionCheckFailure,
+	}}}
+	refs := []*uniqueRef{nil}
+
+	if !existing {
+		for _, attr := range attrs.Uniques {
+			if value := values[attr]; value != "" {
+				item, err := f.putSentinel(attrs, attr, value, owner, putItemInput.TableName)
+				if err != nil {
+					return nil, nil, err
+				}
+
+				items = append(items, item)
+				refs = append(refs, &uniqueRef{attr: attr, value: value})
+			}
+		}
+	}
+
+	if f.HistoryOpts != nil {
+		version, err := newVersion(attrs, v)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		item, err := f.historyItem(ctx, attrs, putItemInput.Item, putItemInput.TableName, opts.now(), version, HistoryOperationPut, putItemInput.Item, nil)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		items = append(items, item)
+		refs = append(refs, nil)
+	}
+
+	return &dynamodb.TransactWriteItemsInput{
+		TransactItems:               items,
+		ReturnConsumedCapacity:      putItemInput.ReturnConsumedCapacity,
+		ReturnItemCollectionMetrics: putItemInput.ReturnItemCollectionMetrics,
+	}, refs, nil
+}
+
+// DoTransactPut performs a [Fns.TransactPut] and then executes the request with the specified DynamoDB client.
+//
+// If the transaction is cancelled because a sentinel item already exists, a [UniqueConstraintError] naming the
+// conflicting attribute is returned.
+func (f *Fns) DoTransactPut(ctx context.Context, client TransactWriteItemsAPIClient, v interface{}, optFns ...func(*PutOpts)) (*dynamodb.TransactWriteItemsOutput, error) {
+	opts := applyOpts(optFns)
+
+	input, refs, err := f.transactPut(ctx, v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.doTransactWriteItems(ctx, client, input, refs, opts.ClientOptions)
+}
+
+// TransactUpdate creates the TransactWriteItems request that updates the given item and moves its uniqueness sentinel
+// items accordingly.
+//
+// For every attribute tagged with `unique` that is the target of a SET (e.g. [UpdateOpts.Set]) or REMOVE action, the
+// value in the given struct is treated as the current stored value: the update is conditioned on the stored value being
+// the same, the sentinel of the current value is deleted, and the sentinel of the new value is put with the condition
+// that it must not already exist. See [Fns.TransactPut] for more information about sentinel items.
+//
+// The hash key and sort key of the struct must be string types. ReturnValues and Decode are not supported by
+// TransactWriteItems.
+func (f *Fns) TransactUpdate(v interface{}, requiredUpdateFn func(*UpdateOpts), optFns ...func(*UpdateOpts)) (*dynamodb.TransactWriteItemsInput, error) {
+	input, _, err := f.transactUpdate(context.Background(), v, newUpdateOpts(requiredUpdateFn, optFns))
+	return input, err
+}
+
+func (f *Fns) transactUpdate(ctx context.Context, v interface{}, opts *UpdateOpts) (*dynamodb.TransactWriteItemsInput, []*uniqueRef, error) {
+	f.init.Do(f.initFn)
+
+	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err = checkSentinelKeys(attrs); err != nil {
+		return nil, nil, err
+	}
+
+	type change struct {
+		attr     *internal.Attribute
+		old, new string
+	}
+	var changes []change
+	opts.beforeBuild = func(v interface{}) error {
+		iv := reflect.Indirect(reflect.ValueOf(v))
+		for _, attr := range attrs.Uniques {
+			value, set := opts.values[attr.Name]
+			if !set && !opts.removes(attr.Name) {
+				continue
+			}
+
+			old, oldAV, err := f.uniqueFieldValue(attr, iv)
+			if err != nil {
+				return err
+			}
+
+			if oldAV == nil {
+				opts.And(expression.Name(attr.Name).AttributeNotExists())
+			} else {
+				opts.And(expression.Name(attr.Name).Equal(expression.Value(oldAV)))
+			}
+
+			var newValue string
+			if set {
+				av, ok := value.(types.AttributeValue)
+				if !ok {
+					if av, err = f.Encoder.Encode(value); err != nil {
+						return fmt.Errorf("encode %s error: %w", attr.Name, err)
+					}
+				}
+				newValue = uniqueValue(av)
+			}
+
+			if old != newValue {
+				changes = append(changes, change{attr: attr, old: old, new: newValue})
+			}
+		}
+
+		return nil
+	}
+
+	updateItemInput, err := f.update(ctx, v, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	owner := ownerOf(attrs, updateItemInput.Key)
+	items := []types.TransactWriteItem{{Update: &types.Update{
+		Key:                                 updateItemInput.Key,
+		TableName:                           updateItemInput.TableName,
+		UpdateExpression:                    updateItemInput.UpdateExpression,
+		ConditionExpression:                 updateItemInput.ConditionExpression,
+		ExpressionAttributeNames:            updateItemInput.ExpressionAttributeNames,
+		ExpressionAttributeValues:           updateItemInput.ExpressionAttributeValues,
+		ReturnValuesOnConditionCheckFailure: updateItemInput.ReturnValuesOnConditionCheckFailure,
+	}}}
+	refs := []*uniqueRef{nil}
+
+	for _, c := range changes {
+		if c.old != "" {
+			item, err := f.deleteSentinel(attrs, c.attr, c.old, owner, updateItemInput.TableName)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			items = append(items, item)
+			refs = append(refs, nil)
+		}
+
+		if c.new != "" {
+			item, err := f.putSentinel(attrs, c.attr, c.new, owner, updateItemInput.TableName)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			items = append(items, item)
+			refs = append(refs, &uniqueRef{attr: c.attr, value: c.new})
+		}
+	}
+
+	if f.HistoryOpts != nil {
+		item, err := f.historyUpdateItem(ctx, attrs, v, updateItemInput, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		items = append(items, item)
+		refs = append(refs, nil)
+	}
+
+	return &dynamodb.TransactWriteItemsInput{
+		TransactItems:               items,
+		ReturnConsumedCapacity:      updateItemInput.ReturnConsumedCapacity,
+		ReturnItemCollectionMetrics: updateItemInput.ReturnItemCollectionMetrics,
+	}, refs, nil
+}
+
+// DoTransactUpdate performs a [Fns.TransactUpdate] and then executes the request with the specified DynamoDB client.
+//
+// If the transaction is cancelled because a sentinel item already exists, a [UniqueConstraintError] naming the
+// conflicting attribute is returned.
+func (f *Fns) DoTransactUpdate(ctx context.Context, client TransactWriteItemsAPIClient, v interface{}, requiredUpdateFn func(*UpdateOpts), optFns ...func(*UpdateOpts)) (*dynamodb.TransactWriteItemsOutput, error) {
+	opts := newUpdateOpts(requiredUpdateFn, optFns)
+
+	input, refs, err := f.transactUpdate(ctx, v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return f.doTransactWriteItems(ctx, client, input, refs, opts.ClientOptions)
+}
+
+// TransactDelete creates the TransactWriteItems request that deletes the given item along with its uniqueness
+// sentinel items.
+//
+// The unique values in the given struct are treated as the current stored values: the delete is conditioned on the
+// stored values being the same so that the right sentinel items are deleted. See [Fns.TransactPut] for more
+// information about sentinel items.
+func (f *Fns) TransactDelete(v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.TransactWriteItemsInput, error) {
+	return f.transactDelete(context.Background(), v, applyOpts(optFns))
+}
+
+func (f *Fns) transactDelete(ctx context.Context, v interface{}, opts *DeleteOpts) (*dynamodb.TransactWriteItemsInput, error) {
+	f.init.Do(f.initFn)
+
+	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+	if err = checkSentinelKeys(attrs); err != nil {
+		return nil, err
+	}
+
+	values := make(map[*internal.Attribute]string)
+	opts.beforeBuild = func(v interface{}) error {
+		iv := reflect.Indirect(reflect.ValueOf(v))
+		for _, attr := range attrs.Uniques {
+			value, av, err := f.uniqueFieldValue(attr, iv)
+			if err != nil {
+				return err
+			}
+
+			if av == nil {
+				opts.And(expression.Name(attr.Name).AttributeNotExists())
+			} else {
+				opts.And(expression.Name(attr.Name).Equal(expression.Value(av)))
+			}
+			values[attr] = value
+		}
+
+		return nil
+	}
+
+	deleteItemInput, err := f.delete(ctx, v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := ownerOf(attrs, deleteItemInput.Key)
+	items := []types.TransactWriteItem{{Delete: &types.Delete{
+		Key:                                 deleteItemInput.Key,
+		TableName:                           deleteItemInput.TableName,
+		ConditionExpression:                 deleteItemInput.ConditionExpression,
+		ExpressionAttributeNames:            deleteItemInput.ExpressionAttributeNames,
+		ExpressionAttributeValues:           deleteItemInput.ExpressionAttributeValues,
+		ReturnValuesOnConditionCheckFailure: deleteItemInput.ReturnValuesOnConditionCheckFailure,
+	}}}
+
+	for _, attr := range attrs.Uniques {
+		if value := values[attr]; value != "" {
+			item, err := f.deleteSentinel(attrs, attr, value, owner, deleteItemInput.TableName)
+			if err != nil {
+				return nil, err
+			}
+
+			items = append(items, item)
+		}
+	}
+
+	if f.HistoryOpts != nil {
+		version, err := newVersion(attrs, v)
+		if err != nil {
+			return nil, err
+		}
+
+		item, err := f.historyItem(ctx, attrs, deleteItemInput.Key, deleteItemInput.TableName, opts.now(), version, HistoryOperationDelete, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		items = append(items, item)
+	}
+
+	return &dynamodb.TransactWriteItemsInput{
+		TransactItems:               items,
+		ReturnConsumedCapacity:      deleteItemInput.ReturnConsumedCapacity,
+		ReturnItemCollectionMetrics: deleteItemInput.ReturnItemCollectionMetrics,
+	}, nil
+}
+
+// DoTransactDelete performs a [Fns.TransactDelete] and then executes the request with the specified DynamoDB client.
+func (f *Fns) DoTransactDelete(ctx context.Context, client TransactWriteItemsAPIClient, v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return f.doTransactDelete(ctx, client, v, applyOpts(optFns))
+}
+
+func (f *Fns) doTransactDelete(ctx context.Context, client TransactWriteItemsAPIClient, v interface{}, opts *DeleteOpts) (*dynamodb.TransactWriteItemsOutput, error) {
+	input, err := f.transactDelete(ctx, v, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return invoke(ctx, f, newRequest(input), client.TransactWriteItems, opts.ClientOptions)
+}
+
+// doTransactWriteItems executes the request and converts cancellations caused by sentinel items to
+// UniqueConstraintError.
+func (f *Fns) doTransactWriteItems(ctx context.Context, client TransactWriteItemsAPIClient, input *dynamodb.TransactWriteItemsInput, refs []*uniqueRef, optFns []func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	output, err := invoke(ctx, f, newRequest(input), client.TransactWriteItems, optFns)
+
+	var tce *types.TransactionCanceledException
+	if errors.As(err, &tce) {
+		for i, reason := range tce.CancellationReasons {
+			if i < len(refs) && refs[i] != nil && reason.Code != nil && *reason.Code == "ConditionalCheckFailed" {
+				return output, &UniqueConstraintError{
+					Constraint: refs[i].attr.Unique,
+					Attribute:  refs[i].attr.Name,
+					Value:      refs[i].value,
+					Err:        err,
+				}
+			}
+		}
+	}
+
+	return output, err
+}
+
+// hasUniques returns true if the struct type has any attribute tagged with `unique`.
+func (f *Fns) hasUniques(v interface{}) bool {
+	f.init.Do(f.initFn)
+
+	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+	return err == nil && len(attrs.Uniques) != 0
+}
+
+// updatesUniques returns true if the update has a SET or REMOVE action on any attribute tagged with `unique`.
+func updatesUniques(m *internal.Model, opts *UpdateOpts) bool {
+	for _, attr := range m.Uniques {
+		if _, ok := opts.values[attr.Name]; ok || opts.removes(attr.Name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// errUniques returns the error for requests that cannot maintain the sentinel items of the given model.
+func errUniques(m *internal.Model, alternative string) error {
+	return fmt.Errorf(`type "%s" has unique attributes so %s must be used instead`, m.StructType.Name(), alternative)
+}
+
+// checkTransactReturnValues returns an error if the request options ask for the item, which TransactWriteItems cannot
+// return.
+func checkTransactReturnValues(opts *RequestOptions) error {
+	if (opts.ReturnValues != "" && opts.ReturnValues != types.ReturnValueNone) || opts.out != nil {
+		return fmt.Errorf("ReturnValues and Decode are not supported by TransactWriteItems")
+	}
+
+	return nil
+}
+
+// checkSentinelKeys returns an error if sentinel items cannot be created for the given model.
+func checkSentinelKeys(m *internal.Model) error {
+	if len(m.Uniques) == 0 {
+		return nil
+	}
+
+	for _, attr := range []*internal.Attribute{m.HashKey, m.SortKey} {
+		if attr != nil && attr.Field.Type.Kind() != reflect.String {
+			return fmt.Errorf(`unique constraints require string key attributes but "%s" is "%s"`, attr.Name, attr.Field.Type)
+		}
+	}
+
+	return nil
+}
+
+// uniqueFieldValue returns the unique value from the struct field as both string and encoded attribute value.
+//
+// Returns empty string and nil attribute value if the field would not be written by Put, such as a nil pointer or an
+// empty `omitempty` field.
+func (f *Fns) uniqueFieldValue(attr *internal.Attribute, iv reflect.Value) (string, types.AttributeValue, error) {
+	fv, err := attr.Get(iv)
+	if err != nil {
+		return "", nil, fmt.Errorf("get %s value error: %w", attr.Name, err)
+	}
+
+	// only values that Put would not write are absent; a numeric 0 or false is still a unique value.
+	switch {
+	case (fv.Kind() == reflect.Pointer || fv.Kind() == reflect.Interface) && fv.IsNil():
+		return "", nil, nil
+	case attr.OmitEmpty && fv.IsZero():
+		return "", nil, nil
+	}
+
+	av, err := f.Encoder.Encode(fv.Interface())
+	if err != nil {
+		return "", nil, fmt.Errorf("encode %s error: %w", attr.Name, err)
+	}
+	if _, ok := av.(*types.AttributeValueMemberNULL); ok {
+		return "", nil, nil
+	}
+
+	return uniqueValue(av), av, nil
+}
+
+// uniqueValue returns the string representation of the scalar attribute value.
+func uniqueValue(av types.AttributeValue) string {
+	switch av := av.(type) {
+	case *types.AttributeValueMemberS:
+		return av.Value
+	case *types.AttributeValueMemberN:
+		return av.Value
+	case *types.AttributeValueMemberB:
+		return base64.StdEncoding.EncodeToString(av.Value)
+	default:
+		return ""
+	}
+}
+
+// ownerOf returns the string representation of the key of the item owning the sentinel items.
+func ownerOf(m *internal.Model, item map[string]types.AttributeValue) string {
+	owner := uniqueValue(item[m.HashKey.Name])
+	if m.SortKey != nil {
+		owner += "|" + uniqueValue(item[m.SortKey.Name])
+	}
+
+	return owner
+}
+
+// isSentinelItem returns true if the item is a uniqueness sentinel item.
+//
+// Sentinel items are written to the same table as their owners so they are returned by scans.
+func isSentinelItem(item map[string]types.AttributeValue) bool {
+	_, ok := item[UniqueOwnerAttribute]
+	return ok
+}
+
+func sentinelKey(m *internal.Model, attr *internal.Attribute, value string) map[string]types.AttributeValue {
+	av := &types.AttributeValueMemberS{Value: "UNIQUE#" + attr.Unique + "#" + value}
+
+	key := map[string]types.AttributeValue{m.HashKey.Name: av}
+	if m.SortKey != nil {
+		key[m.SortKey.Name] = av
+	}
+
+	return key
+}
+
+// sentinelCondition returns the condition that the sentinel item must either not exist or be owned by the given item.
+func sentinelCondition(m *internal.Model, owner string) (expression.Expression, error) {
+	return expression.NewBuilder().
+		WithCondition(expression.Or(
+			expression.Name(m.HashKey.Name).AttributeNotExists(),
+			expression.Name(UniqueOwnerAttribute).Equal(expression.Value(owner)))).
+		Build()
+}
+
+func (f *Fns) putSentinel(m *internal.Model, attr *internal.Attribute, value, owner string, tableName *string) (types.TransactWriteItem, error) {
+	expr, err := sentinelCondition(m, owner)
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("build expressions error: %w", err)
+	}
+
+	item := sentinelKey(m, attr, value)
+	item[UniqueOwnerAttribute] = &types.AttributeValueMemberS{Value: owner}
+
+	return types.TransactWriteItem{Put: f.rewriteTransactPut(&types.Put{
+		Item:                      item,
+		TableName:                 tableName,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})}, nil
+}
+
+func (f *Fns) deleteSentinel(m *internal.Model, attr *internal.Attribute, value, owner string, tableName *string) (types.TransactWriteItem, error) {
+	expr, err := sentinelCondition(m, owner)
+	if err != nil {
+		return types.TransactWriteItem{}, fmt.Errorf("build expressions error: %w", err)
+	}
+
+	return types.TransactWriteItem{Delete: f.rewriteTransactDelete(&types.Delete{
+		Key:                       sentinelKey(m, attr, value),
+		TableName:                 tableName,
+		ConditionExpression:       expr.Condition(),
+		ExpressionAttributeNames:  expr.Names(),
+		ExpressionAttributeValues: expr.Values(),
+	})}, nil
+}
+
+// TransactPut is a wrapper around [DefaultFns.TransactPut]; see [Fns.TransactPut] for more information.
+func TransactPut(v interface{}, optFns ...func(*PutOpts)) (*dynamodb.TransactWriteItemsInput, error) {
+	return DefaultFns.TransactPut(v, optFns...)
+}
+
+// TransactUpdate is a wrapper around [DefaultFns.TransactUpdate]; see [Fns.TransactUpdate] for more information.
+func TransactUpdate(v interface{}, requiredUpdateFn func(*UpdateOpts), optFns ...func(*UpdateOpts)) (*dynamodb.TransactWriteItemsInput, error) {
+	return DefaultFns.TransactUpdate(v, requiredUpdateFn, optFns...)
+}
+
+// TransactDelete is a wrapper around [DefaultFns.TransactDelete]; see [Fns.TransactDelete] for more information.
+func TransactDelete(v interface{}, optFns ...func(*DeleteOpts)) (*dynamodb.TransactWriteItemsInput, error) {
+	return DefaultFns.TransactDelete(v, optFns...)
+}
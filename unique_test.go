@@ -0,0 +1,213 @@
+package ddbfns
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type uniqueTest struct {
+	Id      string `dynamodbav:"id,hashkey" tableName:"users"`
+	Email   string `dynamodbav:"email,omitempty" unique:"email"`
+	Version int64  `dynamodbav:"version,version"`
+}
+
+type fakeTransactClient struct {
+	err error
+}
+
+func (c *fakeTransactClient) TransactWriteItems(_ context.Context, _ *dynamodb.TransactWriteItemsInput, _ ...func(*dynamodb.Options)) (*dynamodb.TransactWriteItemsOutput, error) {
+	return &dynamodb.TransactWriteItemsOutput{}, c.err
+}
+
+func TestFns_TransactPutUnique(t *testing.T) {
+	got, err := TransactPut(uniqueTest{Id: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Errorf("TransactPut() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, 2, len(got.TransactItems))
+	assert.Equal(t, "attribute_not_exists (#0)", *got.TransactItems[0].Put.ConditionExpression)
+
+	sentinel := got.TransactItems[1].Put
+	assert.Equal(t, "users", *sentinel.TableName)
+	assert.Equal(t, map[string]types.AttributeValue{
+		"id":     &types.AttributeValueMemberS{Value: "UNIQUE#email#alice@example.com"},
+		"_owner": &types.AttributeValueMemberS{Value: "alice"},
+	}, sentinel.Item)
+	assert.Equal(t, "(attribute_not_exists (#0)) OR (#1 = :0)", *sentinel.ConditionExpression)
+	assert.Equal(t, map[string]string{"#0": "id", "#1": "_owner"}, sentinel.ExpressionAttributeNames)
+
+	// existing item does not write sentinels, but requires the unique value to stay the same.
+	got, err = TransactPut(uniqueTest{Id: "alice", Email: "alice@example.com", Version: 1})
+	if err != nil {
+		t.Errorf("TransactPut() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, 1, len(got.TransactItems))
+	assert.Equal(t, "(#0 = :0) AND (#1 = :1)", *got.TransactItems[0].Put.ConditionExpression)
+	assert.Equal(t, map[string]string{"#0": "version", "#1": "email"}, got.TransactItems[0].Put.ExpressionAttributeNames)
+}
+
+func TestFns_TransactUpdateUnique(t *testing.T) {
+	got, err := TransactUpdate(uniqueTest{Id: "alice", Email: "alice@example.com", Version: 1}, func(opts *UpdateOpts) {
+		opts.Set("email", "alice@example.org")
+	})
+	if err != nil {
+		t.Errorf("TransactUpdate() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, 3, len(got.TransactItems))
+	assert.Equal(t, "(#0 = :0) AND (#1 = :1)", *got.TransactItems[0].Update.ConditionExpression)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "alice@example.com"}, got.TransactItems[0].Update.ExpressionAttributeValues[":1"])
+	assert.Equal(t, map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "UNIQUE#email#alice@example.com"}}, got.TransactItems[1].Delete.Key)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "UNIQUE#email#alice@example.org"}, got.TransactItems[2].Put.Item["id"])
+
+	// updates that don't touch the unique attribute don't move sentinels.
+	got, err = TransactUpdate(uniqueTest{Id: "alice", Version: 1}, func(opts *UpdateOpts) {
+		opts.Set("notes", "hello")
+	})
+	if err != nil {
+		t.Errorf("TransactUpdate() error = %v", err)
+		return
+	}
+	assert.Equal(t, 1, len(got.TransactItems))
+}
+
+func TestFns_TransactDeleteUnique(t *testing.T) {
+	got, err := TransactDelete(uniqueTest{Id: "alice", Email: "alice@example.com", Version: 1})
+	if err != nil {
+		t.Errorf("TransactDelete() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, 2, len(got.TransactItems))
+	assert.Equal(t, "(#0 = :0) AND (#1 = :1)", *got.TransactItems[0].Delete.ConditionExpression)
+	assert.Equal(t, map[string]types.AttributeValue{"id": &types.AttributeValueMemberS{Value: "UNIQUE#email#alice@example.com"}}, got.TransactItems[1].Delete.Key)
+
+	// an empty omitempty value was never written so it has no sentinel.
+	got, err = TransactDelete(uniqueTest{Id: "alice", Version: 1})
+	if err != nil {
+		t.Errorf("TransactDelete() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, 1, len(got.TransactItems))
+	assert.Equal(t, "(#0 = :0) AND (attribute_not_exists (#1))", *got.TransactItems[0].Delete.ConditionExpression)
+}
+
+func TestFns_DoTransactPutUniqueError(t *testing.T) {
+	none, failed := "None", "ConditionalCheckFailed"
+	client := &fakeTransactClient{err: &types.TransactionCanceledException{
+		CancellationReasons: []types.CancellationReason{{Code: &none}, {Code: &failed}},
+	}}
+
+	_, err := DefaultFns.DoTransactPut(context.Background(), client, uniqueTest{Id: "bob", Email: "alice@example.com"})
+
+	var uerr *UniqueConstraintError
+	if assert.ErrorAs(t, err, &uerr) {
+		assert.Equal(t, "email", uerr.Attribute)
+		assert.Equal(t, "alice@example.com", uerr.Value)
+	}
+
+	var tce *types.TransactionCanceledException
+	assert.ErrorAs(t, err, &tce)
+}
+
+func TestFns_NonTransactUnique(t *testing.T) {
+	_, err := Put(uniqueTest{Id: "alice", Email: "alice@example.com"})
+	assert.ErrorContains(t, err, "TransactPut")
+
+	_, err = Delete(uniqueTest{Id: "alice", Email: "alice@example.com", Version: 1})
+	assert.ErrorContains(t, err, "TransactDelete")
+
+	_, err = Update(uniqueTest{Id: "alice", Version: 1}, func(opts *UpdateOpts) {
+		opts.Set("email", "alice@example.org")
+	})
+	assert.ErrorContains(t, err, "TransactUpdate")
+
+	// updates that don't touch unique attributes don't need a transaction.
+	_, err = Update(uniqueTest{Id: "alice", Version: 1}, func(opts *UpdateOpts) {
+		opts.Set("name", "Alice")
+	})
+	assert.NoError(t, err)
+}
+
+func TestFns_DoDeleteUnique(t *testing.T) {
+	var operations []string
+	var body string
+	client := newFakeClient(func(operation string, b []byte) (int, string) {
+		operations = append(operations, operation)
+		body = string(b)
+		return 200, `{}`
+	})
+
+	ctx := context.Background()
+	_, err := DoDelete(ctx, client, uniqueTest{Id: "alice", Email: "alice@example.com", Version: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"TransactWriteItems"}, operations)
+	assert.Contains(t, body, "UNIQUE#email#alice@example.com")
+
+	_, err = DoDelete(ctx, client, uniqueTest{Id: "alice", Email: "alice@example.com", Version: 1}, func(opts *DeleteOpts) {
+		opts.ReturnValues = types.ReturnValueAllOld
+	})
+	assert.ErrorContains(t, err, "ReturnValues")
+}
+
+func TestFns_TransactUniqueNoVersion(t *testing.T) {
+	type Test struct {
+		Id    string `dynamodbav:"id,hashkey" tableName:"users"`
+		Code  int    `dynamodbav:"code" unique:"code"`
+		Email string `dynamodbav:"email" unique:"email"`
+	}
+
+	// without a version, the put must not replace an existing item whose sentinels it cannot know.
+	got, err := TransactPut(Test{Id: "alice", Code: 0, Email: "alice@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, "attribute_not_exists (#0)", *got.TransactItems[0].Put.ConditionExpression)
+	assert.Equal(t, 3, len(got.TransactItems))
+
+	// a numeric 0 is a unique value whose sentinel must be deleted too.
+	got, err = TransactDelete(Test{Id: "alice", Code: 0, Email: "alice@example.com"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, len(got.TransactItems))
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "UNIQUE#code#0"}, got.TransactItems[1].Delete.Key["id"])
+}
+
+type fakeScanClient struct {
+	items []map[string]types.AttributeValue
+}
+
+func (c *fakeScanClient) Scan(_ context.Context, _ *dynamodb.ScanInput, _ ...func(*dynamodb.Options)) (*dynamodb.ScanOutput, error) {
+	return &dynamodb.ScanOutput{Items: c.items}, nil
+}
+
+func TestFns_DoScanSkipsSentinels(t *testing.T) {
+	f := &Fns{}
+	assert.NoError(t, RegisterType[uniqueTest](f, "User"))
+
+	put, err := f.TransactPut(uniqueTest{Id: "alice", Email: "alice@example.com"})
+	if err != nil {
+		t.Errorf("TransactPut() error = %v", err)
+		return
+	}
+
+	// the sentinel item has no type attribute so it would fail to decode.
+	client := &fakeScanClient{items: []map[string]types.AttributeValue{
+		put.TransactItems[1].Put.Item,
+		put.TransactItems[0].Put.Item,
+	}}
+
+	var got []interface{}
+	assert.NoError(t, f.DoScan(context.Background(), client, &dynamodb.ScanInput{}, func(v interface{}) error {
+		got = append(got, v)
+		return nil
+	}))
+	assert.Equal(t, []interface{}{&uniqueTest{Id: "alice", Email: "alice@example.com", Version: 1}}, got)
+}
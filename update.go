@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"reflect"
-	"strconv"
-	"time"
 
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
@@ -23,67 +21,82 @@ import (
 // optimistic locking. An `ADD #version 1` update expression will be used to update the version.
 //
 // Modified time will always be set to [time.Now] unless disabled by UpdateOpts.
+//
+// Before the request is built, the update actions are validated against the struct tags: attributes tagged with
+// `immutable` cannot be the target of any update action, attributes tagged with `required` cannot be removed, and values
+// from SET actions must satisfy the `min`, `max`, `maxLength`, `pattern`, and `oneof` rules. All violations are
+// aggregated into a [ValidationError].
+//
+// If the struct implements [BeforeUpdateHook], the hook is called on a copy of the struct before validation.
+//
+// If the update has a SET or REMOVE action on an attribute tagged with `unique`, an error is returned since a single
+// UpdateItem request cannot move its sentinel item; use [Fns.TransactUpdate] instead.
 func (f *Fns) Update(v interface{}, requiredUpdateFn func(*UpdateOpts), optFns ...func(*UpdateOpts)) (*dynamodb.UpdateItemInput, error) {
-	f.init.Do(f.initFn)
+	opts := newUpdateOpts(requiredUpdateFn, optFns)
 
-	opts := &UpdateOpts{}
-	requiredUpdateFn(opts)
-	for _, fn := range optFns {
-		fn(opts)
+	f.init.Do(f.initFn)
+	if attrs, err := f.loadOrParse(reflect.TypeOf(v)); err == nil && updatesUniques(attrs, opts) {
+		return nil, errUniques(attrs, "TransactUpdate")
 	}
-	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+
+	return f.update(context.Background(), v, opts)
+}
+
+// newUpdateOpts creates the UpdateOpts from the required update fn and the optional fns.
+func newUpdateOpts(requiredUpdateFn func(*UpdateOpts), optFns []func(*UpdateOpts)) *UpdateOpts {
+	return applyOpts(append([]func(*UpdateOpts){requiredUpdateFn}, optFns...))
+}
+
+func (f *Fns) update(ctx context.Context, v interface{}, opts *UpdateOpts) (*dynamodb.UpdateItemInput, error) {
+	f.init.Do(f.initFn)
+
+	attrs, p, err := f.loadPlan(reflect.TypeOf(v))
 	if err != nil {
 		return nil, err
 	}
 
+	if cp, hook, ok := shallowCopy[BeforeUpdateHook](v); ok {
+		if err = hook.BeforeUpdate(ctx, opts); err != nil {
+			return nil, err
+		}
+		v = cp
+	}
+
 	if opts.TableName == nil {
 		opts.TableName = attrs.TableName
 	}
 
+	if err = validateUpdate(attrs, opts); err != nil {
+		return nil, err
+	}
+
 	// UpdateItem only needs the key.
-	var key map[string]types.AttributeValue
-	if av, err := f.Encoder.Encode(v); err != nil {
+	key, err := f.encodeKey(attrs, v)
+	if err != nil {
 		return nil, err
-	} else if asMap, ok := av.(*types.AttributeValueMemberM); !ok {
-		return nil, fmt.Errorf("item did not encode to M type")
-	} else {
-		item := asMap.Value
-		key = map[string]types.AttributeValue{attrs.HashKey.Name: item[attrs.HashKey.Name]}
-		if attrs.SortKey != nil {
-			key[attrs.SortKey.Name] = item[attrs.SortKey.Name]
-		}
 	}
 
 	iv := reflect.Indirect(reflect.ValueOf(v))
 
 	if versionAttr := attrs.Version; !opts.DisableOptimisticLocking && versionAttr != nil {
-		version, err := versionAttr.Get(iv)
+		current, _, zero, err := p.version.get(iv)
 		if err != nil {
-			return nil, fmt.Errorf("get version value error: %w", err)
+			return nil, err
 		}
 
-		switch {
-		case version.IsZero():
+		if zero {
 			opts.And(expression.Name(attrs.HashKey.Name).AttributeNotExists())
 			opts.Set(versionAttr.Name, &types.AttributeValueMemberN{Value: "1"})
-		case version.CanInt():
-			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(&types.AttributeValueMemberN{Value: strconv.FormatInt(version.Int(), 10)})))
-			opts.Add(versionAttr.Name, 1)
-		case version.CanUint():
-			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(&types.AttributeValueMemberN{Value: strconv.FormatUint(version.Uint(), 10)})))
-			opts.Add(versionAttr.Name, 1)
-		case version.CanFloat():
-			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(&types.AttributeValueMemberN{Value: strconv.FormatFloat(version.Float(), 'f', -1, 64)})))
+		} else {
+			opts.And(expression.Name(versionAttr.Name).Equal(expression.Value(&types.AttributeValueMemberN{Value: current})))
 			opts.Add(versionAttr.Name, 1)
-		default:
-			panic(fmt.Errorf("version attribute's type (%s) is unknown numeric type", version.Type()))
 		}
 	}
 
-	now := time.Now()
+	now := opts.now()
 
 	if modifiedTimeAttr := attrs.ModifiedTime; !opts.DisableAutoGeneratedTimestamps && modifiedTimeAttr != nil {
-		modifiedTime, err := modifiedTimeAttr.Get(iv)
+		modifiedTime, err := p.modifiedTime.get(iv)
 		if err != nil {
 			return nil, fmt.Errorf("get modifiedTime value error: %w", err)
 		}
@@ -104,6 +117,12 @@ func (f *Fns) Update(v interface{}, requiredUpdateFn func(*UpdateOpts), optFns .
 		opts.Set(modifiedTimeAttr.Name, av)
 	}
 
+	if opts.beforeBuild != nil {
+		if err = opts.beforeBuild(v); err != nil {
+			return nil, err
+		}
+	}
+
 	var expr expression.Expression
 	if opts.condition.IsSet() {
 		expr, err = expression.NewBuilder().WithUpdate(opts.update).WithCondition(opts.condition).Build()
@@ -114,7 +133,7 @@ func (f *Fns) Update(v interface{}, requiredUpdateFn func(*UpdateOpts), optFns .
 		return nil, fmt.Errorf("build expressions error: %w", err)
 	}
 
-	return &dynamodb.UpdateItemInput{
+	return f.rewriteUpdate(&dynamodb.UpdateItemInput{
 		Key:                                 key,
 		TableName:                           opts.TableName,
 		ConditionExpression:                 expr.Condition(),
@@ -125,28 +144,56 @@ func (f *Fns) Update(v interface{}, requiredUpdateFn func(*UpdateOpts), optFns .
 		ReturnValues:                        opts.ReturnValues,
 		ReturnValuesOnConditionCheckFailure: opts.ReturnValuesOnConditionCheckFailure,
 		UpdateExpression:                    expr.Update(),
-	}, nil
+	}), nil
 }
 
 // DoUpdate performs a [Fns.Update] and then executes the request with the specified DynamoDB client.
+//
+// If [Fns.HistoryOpts] is given, or if the update has a SET or REMOVE action on an attribute tagged with `unique`,
+// [Fns.DoTransactUpdate] is performed instead to also write the history item or move the uniqueness sentinel items.
+// ReturnValues and Decode are not supported by TransactWriteItems so an error is returned if either is given.
 func (f *Fns) DoUpdate(ctx context.Context, client *dynamodb.Client, v interface{}, requiredUpdateFn func(*UpdateOpts), optFns ...func(*UpdateOpts)) (*dynamodb.UpdateItemOutput, error) {
-	var opts *UpdateOpts
-	optFns = append(optFns, func(o *UpdateOpts) {
-		opts = o
-	})
+	opts := newUpdateOpts(requiredUpdateFn, optFns)
+
+	f.init.Do(f.initFn)
+	attrs, err := f.loadOrParse(reflect.TypeOf(v))
+	if err != nil {
+		return nil, err
+	}
+
+	if f.HistoryOpts != nil || updatesUniques(attrs, opts) {
+		if err = checkTransactReturnValues(&opts.RequestOptions); err != nil {
+			return nil, err
+		}
+
+		input, refs, err := f.transactUpdate(ctx, v, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		output, err := f.doTransactWriteItems(ctx, client, input, refs, opts.ClientOptions)
+		if output == nil {
+			return nil, err
+		}
+
+		return &dynamodb.UpdateItemOutput{
+			ConsumedCapacity: firstConsumedCapacity(output.ConsumedCapacity),
+			ResultMetadata:   output.ResultMetadata,
+		}, err
+	}
 
-	input, err := f.Update(v, requiredUpdateFn, optFns...)
+	input, err := f.update(ctx, v, opts)
 	if err != nil {
 		return nil, err
 	}
 
-	updateItemOutput, err := client.UpdateItem(ctx, input)
+	updateItemOutput, err := invoke(ctx, f, newRequest(input), client.UpdateItem, opts.ClientOptions)
 	if err != nil || opts.out == nil {
 		return updateItemOutput, err
 	}
 
 	if item := updateItemOutput.Attributes; len(item) != 0 {
-		err = f.Decoder.Decode(&types.AttributeValueMemberM{Value: item}, opts.out)
+		err = f.decode(item, opts.out)
 	}
 
 	return updateItemOutput, err
@@ -1,31 +1,34 @@
 package ddbfns
 
 import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
 	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/expression"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
 )
 
 // UpdateOpts customises [Fns.Update] operations per each invocation.
 type UpdateOpts struct {
+	RequestOptions
+
 	// DisableOptimisticLocking, if true, will skip all logic concerning version attribute.
 	DisableOptimisticLocking bool
 	// DisableAutoGeneratedTimestamps, if true, will skip all logic concerning timestamp attributes.
 	DisableAutoGeneratedTimestamps bool
 
-	// TableName modifies the [dynamodb.UpdateItemInput.TableName]
-	TableName *string
-	// ReturnConsumedCapacity modifies the [dynamodb.UpdateItemInput.ReturnConsumedCapacity]
-	ReturnConsumedCapacity types.ReturnConsumedCapacity
-	// ReturnItemCollectionMetrics modifies the [dynamodb.UpdateItemInput.ReturnItemCollectionMetrics]
-	ReturnItemCollectionMetrics types.ReturnItemCollectionMetrics
-	// ReturnValues modifies the [dynamodb.UpdateItemInput.ReturnValues]
-	ReturnValues types.ReturnValue
-	// ReturnValuesOnConditionCheckFailure modifies the [dynamodb.UpdateItemInput.ReturnValuesOnConditionCheckFailure].
-	ReturnValuesOnConditionCheckFailure types.ReturnValuesOnConditionCheckFailure
+	update  expression.UpdateBuilder
+	names   []string
+	values  map[string]interface{}
+	removed []string
 
-	update    expression.UpdateBuilder
-	condition expression.ConditionBuilder
-	out       interface{}
+	// beforeBuild, if set, is called with the (possibly hook-modified) struct right before the expressions are built.
+	beforeBuild func(v interface{}) error
 }
 
 // WithTableName overrides [UpdateOpts.TableName].
@@ -59,37 +62,13 @@ func (o *UpdateOpts) Decode(out interface{}) *UpdateOpts {
 
 // And adds an expression.And to the condition expression.
 func (o *UpdateOpts) And(right expression.ConditionBuilder, other ...expression.ConditionBuilder) *UpdateOpts {
-	if o.condition.IsSet() {
-		o.condition = o.condition.And(right, other...)
-		return o
-	}
-
-	switch len(other) {
-	case 0:
-		o.condition = right
-	case 1:
-		o.condition = right.And(other[0])
-	default:
-		o.condition = right.And(other[0], other[1:]...)
-	}
+	o.and(right, other...)
 	return o
 }
 
-// Or adds an expression.And to the condition expression.
+// Or adds an expression.Or to the condition expression.
 func (o *UpdateOpts) Or(right expression.ConditionBuilder, other ...expression.ConditionBuilder) *UpdateOpts {
-	if o.condition.IsSet() {
-		o.condition = o.condition.Or(right, other...)
-		return o
-	}
-
-	switch len(other) {
-	case 0:
-		o.condition = right
-	case 1:
-		o.condition = right.Or(other[0])
-	default:
-		o.condition = right.Or(other[0], other[1:]...)
-	}
+	o.or(right, other...)
 	return o
 }
 
@@ -98,7 +77,7 @@ func (o *UpdateOpts) Or(right expression.ConditionBuilder, other ...expression.C
 // Like all other UpdateOpts methods to modify the update expression, the name and value will be wrapped with an
 // `expression.Name` and `expression.Value`.
 func (o *UpdateOpts) Add(name string, value interface{}) *UpdateOpts {
-	o.update = o.update.Add(expression.Name(name), expression.Value(value))
+	o.add(name, expression.Value(value))
 
 	return o
 }
@@ -108,7 +87,7 @@ func (o *UpdateOpts) Add(name string, value interface{}) *UpdateOpts {
 // Like all other UpdateOpts methods to modify the update expression, the name and value will be wrapped with an
 // `expression.Name` and `expression.Value`.
 func (o *UpdateOpts) Delete(name string, value interface{}) *UpdateOpts {
-	o.update = o.update.Delete(expression.Name(name), expression.Value(value))
+	o.delete(name, expression.Value(value))
 
 	return o
 }
@@ -118,7 +97,7 @@ func (o *UpdateOpts) Delete(name string, value interface{}) *UpdateOpts {
 // Like all other UpdateOpts methods to modify the update expression, the name and value will be wrapped with an
 // `expression.Name` and `expression.Value`.
 func (o *UpdateOpts) Set(name string, value interface{}) *UpdateOpts {
-	o.update = o.update.Set(expression.Name(name), expression.Value(value))
+	o.setValue(name, value)
 
 	return o
 }
@@ -155,12 +134,12 @@ func (o *UpdateOpts) Set(name string, value interface{}) *UpdateOpts {
 // `expression.Name` and `expression.Value`.
 func (o *UpdateOpts) SetOrRemove(set, remove bool, name string, value interface{}) *UpdateOpts {
 	if set {
-		o.update = o.update.Set(expression.Name(name), expression.Value(value))
+		o.setValue(name, value)
 		return o
 	}
 
 	if remove {
-		o.update = o.update.Remove(expression.Name(name))
+		o.remove(name)
 	}
 
 	return o
@@ -179,20 +158,375 @@ func (o *UpdateOpts) SetOrRemoveStringPointer(name string, ptr *string) *UpdateO
 	}
 
 	if v := *ptr; v != "" {
-		o.update = o.update.Set(expression.Name(name), expression.Value(v))
+		o.setValue(name, v)
 		return o
 	}
 
-	o.update = o.update.Remove(expression.Name(name))
+	o.remove(name)
 	return o
 }
 
+// SetOrRemovePtr is a generic version of [UpdateOpts.SetOrRemoveStringPointer] for any pointer type.
+//
+// If ptr is a nil pointer, no action is taken. If isEmpty reports the dereferenced value as empty, a REMOVE action is
+// used. Otherwise, a SET action with the dereferenced value is used. If isEmpty is nil, only empty strings, slices, and
+// maps are considered empty; numbers and booleans are always SET even if they are 0 or false.
+//
+// This is useful for PATCH handlers whose request fields are pointers so that "not given" (nil) can be distinguished
+// from "clear this attribute" (pointer to empty value):
+//
+//	ddbfns.SetOrRemovePtr(opts, "count", body.Count, nil)
+//	ddbfns.SetOrRemovePtr(opts, "tags", body.Tags, func(v []string) bool { return len(v) == 0 })
+//
+// Like all other UpdateOpts methods, the name will be wrapped with an `expression.Name` and dereferenced value
+// `expression.Value`.
+func SetOrRemovePtr[V any](o *UpdateOpts, name string, ptr *V, isEmpty func(V) bool) *UpdateOpts {
+	if ptr == nil {
+		return o
+	}
+
+	v := *ptr
+	if isEmpty == nil {
+		isEmpty = func(v V) bool {
+			return isEmptyValue(reflect.ValueOf(&v).Elem())
+		}
+	}
+
+	if isEmpty(v) {
+		o.remove(name)
+		return o
+	}
+
+	o.setValue(name, v)
+	return o
+}
+
+// SetOrRemovePointers applies [SetOrRemovePtr] to every pointer field of the given PATCH request struct.
+//
+// The attribute name of each field is given by its `dynamodbav` struct tag, defaulting to the field name if the tag is
+// absent. Fields tagged with `dynamodbav:"-"`, unexported fields, non-pointer fields, and nil pointers are skipped.
+// Like SetOrRemovePtr, a pointer to an empty string, slice, or map is a REMOVE action while a pointer to any other
+// value such as 0 or false is a SET action. If the tag includes `omitempty`, a pointer to a value that
+// [attributevalue.Encoder] would omit, such as 0 or false, is a REMOVE action too. If the tag includes `unixtime`, a
+// *time.Time value will be written as epoch second like [attributevalue.UnixTime].
+//
+// The other `dynamodbav` options are not used; like all other UpdateOpts methods, the values are marshalled by the
+// expression builder with the default options of [attributevalue.Marshal] rather than [Fns.Encoder]. In particular, a
+// pointer to a zero time.Time is a SET action even with `omitempty` since the encoder never omits structs; use a nil
+// pointer to leave the attribute unchanged.
+//
+//	type PatchRequest struct {
+//		Notes     *string    `dynamodbav:"notes"`
+//		Count     *int       `dynamodbav:"count"`
+//		ExpiresAt *time.Time `dynamodbav:"expiresAt,unixtime"`
+//	}
+//
+//	if err := ddbfns.SetOrRemovePointers(opts, body); err != nil {
+//		return err
+//	}
+//
+// Returns an error if patch is not a struct or pointer to struct.
+func SetOrRemovePointers(o *UpdateOpts, patch interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(patch))
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("patch must be a struct or pointer to struct, got %T", patch)
+	}
+
+	t := v.Type()
+	for i, n := 0, t.NumField(); i < n; i++ {
+		structField := t.Field(i)
+		if !structField.IsExported() || structField.Type.Kind() != reflect.Pointer {
+			continue
+		}
+
+		name, omitEmpty, unixTime := structField.Name, false, false
+		if tag, ok := structField.Tag.Lookup("dynamodbav"); ok {
+			tags := strings.Split(tag, ",")
+			if tags[0] == "-" {
+				continue
+			}
+			if tags[0] != "" {
+				name = tags[0]
+			}
+			for _, tag = range tags[1:] {
+				switch tag {
+				case "omitempty":
+					omitEmpty = true
+				case "unixtime":
+					unixTime = true
+				}
+			}
+		}
+
+		ptr := v.Field(i)
+		if ptr.IsNil() {
+			continue
+		}
+
+		elem := ptr.Elem()
+		if isEmptyValue(elem) || (omitEmpty && isZeroScalar(elem)) {
+			o.remove(name)
+			continue
+		}
+
+		value := elem.Interface()
+		if t, ok := value.(time.Time); ok && unixTime {
+			value = attributevalue.UnixTime(t)
+		}
+
+		o.setValue(name, value)
+	}
+
+	return nil
+}
+
+// isEmptyValue returns true if the value is an empty string, slice, or map, or a nil pointer or interface.
+//
+// Zero numbers and false booleans are not empty since they are meaningful values to SET.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Pointer, reflect.Interface:
+		return v.IsNil()
+	case reflect.Invalid:
+		return true
+	default:
+		return false
+	}
+}
+
+// isZeroScalar returns true if the value is a false boolean or a zero number, which attributevalue.Encoder omits if the
+// field is tagged with `omitempty`.
+func isZeroScalar(v reflect.Value) bool {
+	return (v.Kind() == reflect.Bool || v.CanInt() || v.CanUint() || v.CanFloat()) && v.IsZero()
+}
+
 // Remove adds an [expression.UpdateBuilder.Set] expression.
 //
 // Like all other UpdateOpts methods to modify the update expression, the name and value will be wrapped with an
 // `expression.Name` and `expression.Value`.
 func (o *UpdateOpts) Remove(name string) *UpdateOpts {
-	o.update = o.update.Remove(expression.Name(name))
+	o.remove(name)
+
+	return o
+}
+
+// SetIfNotExists adds a SET action that only writes the value if the attribute does not exist yet.
+//
+// The resulting update expression is `SET #name = if_not_exists(#name, :value)`, which is useful for attributes that
+// should only be initialised once such as a first-seen timestamp.
+//
+// Like all other UpdateOpts methods to modify the update expression, the name and value will be wrapped with an
+// `expression.Name` and `expression.Value`.
+func (o *UpdateOpts) SetIfNotExists(name string, value interface{}) *UpdateOpts {
+	o.set(name, expression.Name(name).IfNotExists(expression.Value(value)))
+	o.recordValue(name, value)
+
+	return o
+}
+
+// SetFromAttribute adds a SET action that copies the value of attribute source into attribute name.
+//
+// The resulting update expression is `SET #name = #source`. Both name and source will be wrapped with an
+// `expression.Name`.
+func (o *UpdateOpts) SetFromAttribute(name, source string) *UpdateOpts {
+	o.set(name, expression.Name(source))
+
+	return o
+}
+
+// AppendToList adds a SET action that appends the given list to the end of the list attribute.
+//
+// The value must marshal to a DynamoDB list (L type) such as a slice. If the attribute does not exist yet, it is
+// treated as an empty list so the resulting attribute will be exactly the given list.
+//
+// Like all other UpdateOpts methods to modify the update expression, the name and value will be wrapped with an
+// `expression.Name` and `expression.Value`.
+func (o *UpdateOpts) AppendToList(name string, value interface{}) *UpdateOpts {
+	o.set(name, expression.ListAppend(emptyListIfNotExists(name), expression.Value(value)))
+
+	return o
+}
+
+// PrependToList adds a SET action that prepends the given list to the start of the list attribute.
+//
+// The value must marshal to a DynamoDB list (L type) such as a slice. If the attribute does not exist yet, it is
+// treated as an empty list so the resulting attribute will be exactly the given list.
+//
+// Like all other UpdateOpts methods to modify the update expression, the name and value will be wrapped with an
+// `expression.Name` and `expression.Value`.
+func (o *UpdateOpts) PrependToList(name string, value interface{}) *UpdateOpts {
+	o.set(name, expression.ListAppend(expression.Value(value), emptyListIfNotExists(name)))
+
+	return o
+}
+
+// Increment adds a SET action that increments the numeric attribute by the given delta.
+//
+// The resulting update expression is `SET #name = if_not_exists(#name, 0) + :delta` so the attribute does not have to
+// exist beforehand. Unlike [UpdateOpts.Add], SET arithmetic can be combined with other SET actions on nested paths.
+//
+// Like all other UpdateOpts methods to modify the update expression, the name and value will be wrapped with an
+// `expression.Name` and `expression.Value`.
+func (o *UpdateOpts) Increment(name string, delta interface{}) *UpdateOpts {
+	o.set(name, expression.Plus(zeroIfNotExists(name), expression.Value(delta)))
+
+	return o
+}
+
+// Decrement adds a SET action that decrements the numeric attribute by the given delta.
+//
+// The resulting update expression is `SET #name = if_not_exists(#name, 0) - :delta` so the attribute does not have to
+// exist beforehand.
+//
+// Like all other UpdateOpts methods to modify the update expression, the name and value will be wrapped with an
+// `expression.Name` and `expression.Value`.
+func (o *UpdateOpts) Decrement(name string, delta interface{}) *UpdateOpts {
+	o.set(name, expression.Minus(zeroIfNotExists(name), expression.Value(delta)))
+
+	return o
+}
+
+// AddToStringSet adds an ADD action that adds the given strings to the string set (SS type) attribute.
+//
+// If no values are given, no action is taken since DynamoDB does not allow empty sets.
+func (o *UpdateOpts) AddToStringSet(name string, values ...string) *UpdateOpts {
+	if len(values) == 0 {
+		return o
+	}
+
+	o.add(name, expression.Value(&types.AttributeValueMemberSS{Value: values}))
+
+	return o
+}
+
+// AddToNumberSet adds an ADD action that adds the given numbers to the number set (NS type) attribute.
+//
+// If no values are given, no action is taken since DynamoDB does not allow empty sets.
+func (o *UpdateOpts) AddToNumberSet(name string, values ...float64) *UpdateOpts {
+	if len(values) == 0 {
+		return o
+	}
+
+	o.add(name, expression.Value(numberSet(values)))
+
+	return o
+}
+
+// AddToBinarySet adds an ADD action that adds the given byte slices to the binary set (BS type) attribute.
+//
+// If no values are given, no action is taken since DynamoDB does not allow empty sets.
+func (o *UpdateOpts) AddToBinarySet(name string, values ...[]byte) *UpdateOpts {
+	if len(values) == 0 {
+		return o
+	}
+
+	o.add(name, expression.Value(&types.AttributeValueMemberBS{Value: values}))
+
+	return o
+}
+
+// RemoveFromStringSet adds a DELETE action that removes the given strings from the string set (SS type) attribute.
+//
+// If no values are given, no action is taken since DynamoDB does not allow empty sets.
+func (o *UpdateOpts) RemoveFromStringSet(name string, values ...string) *UpdateOpts {
+	if len(values) == 0 {
+		return o
+	}
+
+	o.delete(name, expression.Value(&types.AttributeValueMemberSS{Value: values}))
 
 	return o
 }
+
+// RemoveFromNumberSet adds a DELETE action that removes the given numbers from the number set (NS type) attribute.
+//
+// If no values are given, no action is taken since DynamoDB does not allow empty sets.
+func (o *UpdateOpts) RemoveFromNumberSet(name string, values ...float64) *UpdateOpts {
+	if len(values) == 0 {
+		return o
+	}
+
+	o.delete(name, expression.Value(numberSet(values)))
+
+	return o
+}
+
+// RemoveFromBinarySet adds a DELETE action that removes the given byte slices from the binary set (BS type) attribute.
+//
+// If no values are given, no action is taken since DynamoDB does not allow empty sets.
+func (o *UpdateOpts) RemoveFromBinarySet(name string, values ...[]byte) *UpdateOpts {
+	if len(values) == 0 {
+		return o
+	}
+
+	o.delete(name, expression.Value(&types.AttributeValueMemberBS{Value: values}))
+
+	return o
+}
+
+func (o *UpdateOpts) set(name string, operand expression.OperandBuilder) {
+	o.update = o.update.Set(expression.Name(name), operand)
+	o.names = append(o.names, name)
+}
+
+// setValue is the same as set, but it also keeps track of the value so that it can be validated.
+func (o *UpdateOpts) setValue(name string, value interface{}) {
+	o.set(name, expression.Value(value))
+	o.recordValue(name, value)
+}
+
+func (o *UpdateOpts) recordValue(name string, value interface{}) {
+	if o.values == nil {
+		o.values = make(map[string]interface{})
+	}
+	o.values[name] = value
+}
+
+func (o *UpdateOpts) add(name string, value expression.ValueBuilder) {
+	o.update = o.update.Add(expression.Name(name), value)
+	o.names = append(o.names, name)
+}
+
+func (o *UpdateOpts) delete(name string, value expression.ValueBuilder) {
+	o.update = o.update.Delete(expression.Name(name), value)
+	o.names = append(o.names, name)
+}
+
+func (o *UpdateOpts) remove(name string) {
+	o.update = o.update.Remove(expression.Name(name))
+	o.names = append(o.names, name)
+	o.removed = append(o.removed, name)
+}
+
+// topLevelName returns the top-level attribute name of a document path such as `a.b[0]`.
+func topLevelName(name string) string {
+	if i := strings.IndexAny(name, ".["); i != -1 {
+		return name[:i]
+	}
+
+	return name
+}
+
+func emptyListIfNotExists(name string) expression.SetValueBuilder {
+	return expression.Name(name).IfNotExists(expression.Value(&types.AttributeValueMemberL{Value: []types.AttributeValue{}}))
+}
+
+func zeroIfNotExists(name string) expression.SetValueBuilder {
+	return expression.Name(name).IfNotExists(expression.Value(&types.AttributeValueMemberN{Value: "0"}))
+}
+
+func numberSet(values []float64) *types.AttributeValueMemberNS {
+	ns := make([]string, len(values))
+	for i, v := range values {
+		ns[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+
+	return &types.AttributeValueMemberNS{Value: ns}
+}
+
+// removes returns true if there is a REMOVE action for the given attribute name.
+func (o *UpdateOpts) removes(name string) bool {
+	return slices.Contains(o.removed, name)
+}
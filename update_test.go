@@ -114,3 +114,150 @@ func TestFns_UpdateIncrementVersion(t *testing.T) {
 	assert.Equal(t, map[string]string{"#0": "version", "#1": "notes"}, got.ExpressionAttributeNames)
 	assert.Equal(t, map[string]types.AttributeValue{":0": &types.AttributeValueMemberN{Value: "3"}, ":1": &types.AttributeValueMemberN{Value: "1"}, ":2": &types.AttributeValueMemberS{Value: "world!"}}, got.ExpressionAttributeValues)
 }
+
+func TestUpdateOpts_Actions(t *testing.T) {
+	type Test struct {
+		Id string `dynamodbav:"id,hashkey" tableName:""`
+	}
+
+	got, err := Update(Test{Id: "hello"}, func(opts *UpdateOpts) {
+		opts.
+			SetIfNotExists("firstSeen", "today").
+			SetFromAttribute("backup", "notes").
+			AppendToList("history", []string{"a"}).
+			Increment("count", 2).
+			AddToStringSet("tags", "x", "y").
+			RemoveFromNumberSet("scores", 1, 2.5).
+			AddToBinarySet("blobs")
+	})
+	if err != nil {
+		t.Errorf("Update() error = %v", err)
+		return
+	}
+
+	assert.Nil(t, got.ConditionExpression)
+	assert.Equal(t, "ADD #0 :0\nDELETE #1 :1\nSET #2 = if_not_exists(#2, :2), #3 = #4, #5 = list_append(if_not_exists(#5, :3), :4), #6 = if_not_exists(#6, :5) + :6\n", *got.UpdateExpression)
+	assert.Equal(t, map[string]string{"#0": "tags", "#1": "scores", "#2": "firstSeen", "#3": "backup", "#4": "notes", "#5": "history", "#6": "count"}, got.ExpressionAttributeNames)
+	assert.Equal(t, &types.AttributeValueMemberL{Value: []types.AttributeValue{}}, got.ExpressionAttributeValues[":3"])
+	assert.Equal(t, &types.AttributeValueMemberN{Value: "0"}, got.ExpressionAttributeValues[":5"])
+	assert.Equal(t, &types.AttributeValueMemberSS{Value: []string{"x", "y"}}, got.ExpressionAttributeValues[":0"])
+	assert.Equal(t, &types.AttributeValueMemberNS{Value: []string{"1", "2.5"}}, got.ExpressionAttributeValues[":1"])
+}
+
+func TestSetOrRemovePointers(t *testing.T) {
+	type Test struct {
+		Id string `dynamodbav:"id,hashkey" tableName:""`
+	}
+	type Patch struct {
+		Notes     *string    `dynamodbav:"notes"`
+		Count     *int       `dynamodbav:"count"`
+		Tags      *[]string  `dynamodbav:"tags"`
+		Active    *bool      `dynamodbav:"active"`
+		ExpiresAt *time.Time `dynamodbav:"expiresAt,unixtime"`
+		Skipped   *string    `dynamodbav:"-"`
+		Ignored   string     `dynamodbav:"ignored"`
+	}
+
+	notes, count, tags, active, skipped := "hello", 0, []string{}, false, "skipped"
+	patch := Patch{Notes: &notes, Count: &count, Tags: &tags, Active: &active, ExpiresAt: &testTime, Skipped: &skipped, Ignored: "ignored"}
+
+	got, err := Update(Test{Id: "hello"}, func(opts *UpdateOpts) {
+		if err := SetOrRemovePointers(opts, &patch); err != nil {
+			t.Errorf("SetOrRemovePointers() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Errorf("Update() error = %v", err)
+		return
+	}
+
+	// only the empty slice is removed; 0 and false are values to set.
+	assert.Equal(t, "REMOVE #0\nSET #1 = :0, #2 = :1, #3 = :2, #4 = :3\n", *got.UpdateExpression)
+	assert.Equal(t, map[string]string{"#0": "tags", "#1": "notes", "#2": "count", "#3": "active", "#4": "expiresAt"}, got.ExpressionAttributeNames)
+	assert.Equal(t, map[string]types.AttributeValue{
+		":0": &types.AttributeValueMemberS{Value: "hello"},
+		":1": &types.AttributeValueMemberN{Value: "0"},
+		":2": &types.AttributeValueMemberBOOL{Value: false},
+		":3": &types.AttributeValueMemberN{Value: "1136214245"},
+	}, got.ExpressionAttributeValues)
+
+	got, err = Update(Test{Id: "hello"}, func(opts *UpdateOpts) {
+		SetOrRemovePtr(opts, "count", &count, nil)
+		SetOrRemovePtr(opts, "tags", &tags, func(v []string) bool { return false })
+		SetOrRemovePtr[string](opts, "notes", nil, nil)
+	})
+	if err != nil {
+		t.Errorf("Update() error = %v", err)
+		return
+	}
+
+	assert.Equal(t, "SET #0 = :0, #1 = :1\n", *got.UpdateExpression)
+	assert.Equal(t, map[string]string{"#0": "count", "#1": "tags"}, got.ExpressionAttributeNames)
+
+	assert.Error(t, SetOrRemovePointers(&UpdateOpts{}, "not a struct"))
+}
+
+func TestSetOrRemovePointersOmitEmpty(t *testing.T) {
+	type Test struct {
+		Id string `dynamodbav:"id,hashkey" tableName:""`
+	}
+	type Patch struct {
+		Count     *int       `dynamodbav:"count,omitempty"`
+		Active    *bool      `dynamodbav:"active,omitempty"`
+		ExpiresAt *time.Time `dynamodbav:"expiresAt,omitempty"`
+	}
+
+	count, active, expiresAt := 0, false, time.Time{}
+	patch := Patch{Count: &count, Active: &active, ExpiresAt: &expiresAt}
+
+	got, err := Update(Test{Id: "hello"}, func(opts *UpdateOpts) {
+		if err := SetOrRemovePointers(opts, &patch); err != nil {
+			t.Errorf("SetOrRemovePointers() error = %v", err)
+		}
+	})
+	if err != nil {
+		t.Errorf("Update() error = %v", err)
+		return
+	}
+
+	// 0 and false are omitted by the encoder so they are removed, but the zero time.Time is not.
+	assert.Equal(t, "REMOVE #0, #1\nSET #2 = :0\n", *got.UpdateExpression)
+	assert.Equal(t, map[string]string{"#0": "count", "#1": "active", "#2": "expiresAt"}, got.ExpressionAttributeNames)
+	assert.Equal(t, &types.AttributeValueMemberS{Value: "0001-01-01T00:00:00Z"}, got.ExpressionAttributeValues[":0"])
+}
+
+func TestFns_UpdateImmutable(t *testing.T) {
+	type Test struct {
+		Id      string `dynamodbav:"id,hashkey" tableName:""`
+		OwnerId string `dynamodbav:"ownerId,immutable"`
+	}
+
+	_, err := Update(Test{Id: "hello"}, func(opts *UpdateOpts) {
+		opts.Set("notes", "world!").Remove("ownerId.name")
+	})
+
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []*FieldError{{Field: "OwnerId", Attribute: "ownerId", Reason: "immutable attribute cannot be updated"}}, verr.Errors)
+	}
+}
+
+func TestFns_UpdateValidation(t *testing.T) {
+	_, err := Update(validatedTest{Id: "hello"}, func(opts *UpdateOpts) {
+		opts.Remove("email").Set("color", "blue").Set("count", &types.AttributeValueMemberN{Value: "100"})
+	})
+
+	var verr *ValidationError
+	if assert.ErrorAs(t, err, &verr) {
+		assert.Equal(t, []*FieldError{
+			{Field: "Email", Attribute: "email", Reason: "required attribute cannot be removed"},
+			{Field: "Color", Attribute: "color", Reason: `value "blue" is not one of [red green]`},
+		}, verr.Errors)
+	}
+
+	// model's Validate is not called by Update.
+	_, err = Update(validatedTest{Id: "hello", Color: "green", Count: 2}, func(opts *UpdateOpts) {
+		opts.Set("email", "a@b.c")
+	})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,171 @@
+package ddbfns
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/nguyengg/go-ddb-fns/internal"
+)
+
+// Validator can be implemented by the struct to add custom validation to [Fns.Put].
+//
+// Validate is called after the struct tag rules have been checked. If it returns a [ValidationError] or [FieldError],
+// their field errors are aggregated with those from the struct tag rules.
+//
+// [Fns.Update] does not call Validate since the struct given to it usually only has the key attributes set; rules that
+// span several attributes cannot be checked against the update actions alone.
+type Validator interface {
+	Validate() error
+}
+
+// FieldError describes why a single attribute failed validation.
+type FieldError struct {
+	// Field is the name of the struct field.
+	Field string
+	// Attribute is the name of the DynamoDB attribute.
+	Attribute string
+	// Reason explains why validation failed.
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *FieldError) Error() string {
+	if e.Attribute == "" {
+		return e.Reason
+	}
+
+	return fmt.Sprintf(`attribute "%s": %s`, e.Attribute, e.Reason)
+}
+
+// ValidationError is returned by [Fns.Put] and [Fns.Update] if the request would violate the rules declared by the
+// struct tags.
+//
+// Use [errors.As] to retrieve all the individual FieldError instances.
+type ValidationError struct {
+	// Type is the name of the struct type being validated.
+	Type string
+	// Errors contains at least one FieldError.
+	Errors []*FieldError
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		messages[i] = fe.Error()
+	}
+
+	return fmt.Sprintf(`validate type "%s" error: %s`, e.Type, strings.Join(messages, "; "))
+}
+
+// Unwrap returns the individual FieldError instances.
+func (e *ValidationError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, fe := range e.Errors {
+		errs[i] = fe
+	}
+
+	return errs
+}
+
+// validateItem checks every attribute of the given struct value against the validation rules from the struct tags,
+// then calls Validator.Validate if the struct implements Validator.
+func validateItem(m *internal.Model, v interface{}) error {
+	verr := &ValidationError{Type: m.StructType.Name()}
+	iv := reflect.Indirect(reflect.ValueOf(v))
+
+	for _, attr := range m.Validated {
+		fv, err := attr.Get(iv)
+		if err != nil {
+			return fmt.Errorf("get %s value error: %w", attr.Name, err)
+		}
+
+		for _, reason := range attr.Validate(fv) {
+			verr.Errors = append(verr.Errors, &FieldError{Field: attr.Field.Name, Attribute: attr.Name, Reason: reason})
+		}
+	}
+
+	validator, ok := v.(Validator)
+	if !ok && iv.Kind() == reflect.Struct {
+		// Validate may be implemented with pointer receiver.
+		ptr := reflect.New(iv.Type())
+		ptr.Elem().Set(iv)
+		validator, ok = ptr.Interface().(Validator)
+	}
+
+	if ok {
+		if err := validator.Validate(); err != nil {
+			var (
+				other *ValidationError
+				fe    *FieldError
+			)
+			switch {
+			case errors.As(err, &other):
+				verr.Errors = append(verr.Errors, other.Errors...)
+			case errors.As(err, &fe):
+				verr.Errors = append(verr.Errors, fe)
+			default:
+				verr.Errors = append(verr.Errors, &FieldError{Reason: err.Error()})
+			}
+		}
+	}
+
+	if len(verr.Errors) != 0 {
+		return verr
+	}
+
+	return nil
+}
+
+// validateUpdate checks the update actions in opts against the immutable and validation rules from the struct tags.
+//
+// Immutable attributes cannot be the target of any update action. Required attributes cannot be removed. Values from
+// SET actions such as [UpdateOpts.Set] are checked against the validation rules; values from other actions such as
+// arithmetic or list operations are not.
+func validateUpdate(m *internal.Model, opts *UpdateOpts) error {
+	verr := &ValidationError{Type: m.StructType.Name()}
+
+	for _, attr := range m.Immutables {
+		for _, name := range opts.names {
+			if topLevelName(name) == attr.Name {
+				verr.Errors = append(verr.Errors, &FieldError{Field: attr.Field.Name, Attribute: attr.Name, Reason: "immutable attribute cannot be updated"})
+				break
+			}
+		}
+	}
+
+	for _, attr := range m.Validated {
+		for _, name := range opts.names {
+			if name != attr.Name {
+				continue
+			}
+
+			value, ok := opts.values[name]
+			if !ok {
+				if attr.Required && opts.removes(name) {
+					verr.Errors = append(verr.Errors, &FieldError{Field: attr.Field.Name, Attribute: attr.Name, Reason: "required attribute cannot be removed"})
+				}
+				break
+			}
+
+			// values that have already been marshalled cannot be validated.
+			if _, ok = value.(types.AttributeValue); ok {
+				break
+			}
+
+			for _, reason := range attr.Validate(reflect.ValueOf(value)) {
+				verr.Errors = append(verr.Errors, &FieldError{Field: attr.Field.Name, Attribute: attr.Name, Reason: reason})
+			}
+			break
+		}
+	}
+
+	if len(verr.Errors) != 0 {
+		return verr
+	}
+
+	return nil
+}